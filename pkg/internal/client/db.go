@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/gogo/protobuf/proto"
+	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
@@ -605,10 +606,11 @@ func getOneRow(runErr error, b *Batch) (KeyValue, error) {
 func IncrementValRetryable(ctx context.Context, db *DB, key roachpb.Key, inc int64) (int64, error) {
 	var err error
 	var res KeyValue
-	for r := retry.Start(base.DefaultRetryOptions()); r.Next(); {
+	for r, attempt := retry.Start(base.DefaultRetryOptions()), 1; r.Next(); attempt++ {
 		res, err = db.Inc(ctx, key, inc)
 		switch err.(type) {
 		case *roachpb.UnhandledRetryableError, *roachpb.AmbiguousResultError:
+			tracing.AnnotateRetry(opentracing.SpanFromContext(ctx), attempt, 0 /* backoff */, err)
 			continue
 		}
 		break
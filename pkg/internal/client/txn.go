@@ -658,7 +658,7 @@ func (txn *Txn) Exec(
 		log.Fatal(ctx, "asked to retry or commit a txn that is already aborted")
 	}
 
-	for {
+	for attempt := 1; ; attempt++ {
 		if txn != nil {
 			txn.mu.Lock()
 			// If we're looking at a brand new transaction, then communicate
@@ -721,6 +721,7 @@ func (txn *Txn) Exec(
 
 		log.VEventf(ctx, 2, "automatically retrying transaction: %s because of error: %s",
 			txn.DebugName(), err)
+		tracing.AnnotateRetry(opentracing.SpanFromContext(ctx), attempt, 0 /* backoff */, err)
 	}
 
 	return err
@@ -22,6 +22,7 @@ import (
 	"time"
 	"unsafe"
 
+	opentracing "github.com/opentracing/opentracing-go"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 
@@ -922,7 +923,7 @@ func (ds *DistSender) sendPartialBatch(
 	}
 
 	// Start a retry loop for sending the batch to the range.
-	for r := retry.StartWithCtx(ctx, ds.rpcRetryOptions); r.Next(); {
+	for r, attempt := retry.StartWithCtx(ctx, ds.rpcRetryOptions), 1; r.Next(); attempt++ {
 		// If we've cleared the descriptor on a send failure, re-lookup.
 		if desc == nil {
 			var descKey roachpb.RKey
@@ -934,6 +935,7 @@ func (ds *DistSender) sendPartialBatch(
 			desc, evictToken, err = ds.getDescriptor(ctx, descKey, nil, isReverse)
 			if err != nil {
 				log.ErrEventf(ctx, "range descriptor re-lookup failed: %s", err)
+				tracing.AnnotateRetry(opentracing.SpanFromContext(ctx), attempt, 0 /* backoff */, err)
 				continue
 			}
 		}
@@ -967,6 +969,7 @@ func (ds *DistSender) sendPartialBatch(
 			}
 			// Clear the descriptor to reload on the next attempt.
 			desc = nil
+			tracing.AnnotateRetry(opentracing.SpanFromContext(ctx), attempt, 0 /* backoff */, pErr.GoError())
 			continue
 		case *roachpb.RangeKeyMismatchError:
 			// Range descriptor might be out of date - evict it. This is
@@ -258,13 +258,21 @@ func (gt *grpcTransport) SendNext(ctx context.Context, done chan<- BatchCall) {
 			}
 
 			log.VEventf(ctx, 2, "sending request to %s", client.remoteAddr)
-			reply, err := client.client.Batch(ctx, &client.args)
+			rpcCtx := tracing.ContextWithPeerNodeID(ctx, int32(client.args.Replica.NodeID))
+			reply, err := client.client.Batch(rpcCtx, &client.args)
 			if reply != nil {
 				for i := range reply.Responses {
 					if err := reply.Responses[i].GetInner().Verify(client.args.Requests[i].GetInner()); err != nil {
 						log.Error(ctx, err)
 					}
 				}
+				// Annotate the remote spans with the clock offset of the remote
+				// node, so that the recording can be aligned.
+				if len(reply.CollectedSpans) > 0 {
+					if offset, ok := gt.rpcContext.RemoteClocks.Offset(client.remoteAddr); ok {
+						tracing.SetClockOffset(reply.CollectedSpans, offset)
+					}
+				}
 			}
 			return reply, err
 		}()
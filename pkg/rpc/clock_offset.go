@@ -111,6 +111,18 @@ func (r *RemoteClockMonitor) Latency(addr string) (time.Duration, bool) {
 	return 0, false
 }
 
+// Offset returns the offset of the clock of the given node address relative
+// to the local clock. Returns false if there is no current measurement.
+func (r *RemoteClockMonitor) Offset(addr string) (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	offset, ok := r.mu.offsets[addr]
+	if !ok || offset.isStale(r.offsetTTL, r.clock.PhysicalTime()) {
+		return 0, false
+	}
+	return time.Duration(offset.Offset), true
+}
+
 // AllLatencies returns a map of all currently valid latency measurements.
 func (r *RemoteClockMonitor) AllLatencies() map[string]time.Duration {
 	r.mu.Lock()
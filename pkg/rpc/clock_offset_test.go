@@ -232,3 +232,32 @@ func TestLatencies(t *testing.T) {
 		}
 	}
 }
+
+func TestOffset(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	clock := hlc.NewClock(hlc.NewManualClock(123).UnixNano, time.Nanosecond)
+	monitor := newRemoteClockMonitor(clock, time.Hour, 0)
+
+	if _, ok := monitor.Offset("addr"); ok {
+		t.Fatal("expected no offset")
+	}
+	monitor.UpdateOffset(context.TODO(), "addr", RemoteOffset{
+		Offset:      int64(5 * time.Millisecond),
+		Uncertainty: 20,
+		MeasuredAt:  clock.PhysicalTime().UnixNano(),
+	}, 0)
+	if o, ok := monitor.Offset("addr"); !ok || o != 5*time.Millisecond {
+		t.Errorf("expected offset of 5ms, got %s (%t)", o, ok)
+	}
+
+	// Stale measurements are ignored.
+	monitor.UpdateOffset(context.TODO(), "stale", RemoteOffset{
+		Offset:      int64(5 * time.Millisecond),
+		Uncertainty: 20,
+		MeasuredAt:  clock.PhysicalTime().Add(-(monitor.offsetTTL + 1)).UnixNano(),
+	}, 0)
+	if _, ok := monitor.Offset("stale"); ok {
+		t.Error("expected no offset for stale measurement")
+	}
+}
@@ -26,7 +26,6 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/grpc-ecosystem/grpc-opentracing/go/otgrpc"
 	"github.com/rubyist/circuitbreaker"
 	"golang.org/x/net/context"
 	"golang.org/x/sync/syncmap"
@@ -42,6 +41,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
 func init() {
@@ -120,10 +120,11 @@ func NewServer(ctx *Context) *grpc.Server {
 		}
 		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
 	}
-	if tracer := ctx.AmbientCtx.Tracer; tracer != nil {
-		opts = append(opts, grpc.UnaryInterceptor(
-			otgrpc.OpenTracingServerInterceptor(tracer),
-		))
+	if tracer, ok := ctx.AmbientCtx.Tracer.(*tracing.Tracer); ok {
+		opts = append(opts,
+			grpc.UnaryInterceptor(tracing.ServerInterceptor(tracer)),
+			grpc.StreamInterceptor(tracing.StreamServerInterceptor(tracer)),
+		)
 	}
 	s := grpc.NewServer(opts...)
 	RegisterHeartbeatServer(s, &HeartbeatService{
@@ -311,10 +312,11 @@ func (ctx *Context) GRPCDial(target string, opts ...grpc.DialOption) (*grpc.Clie
 			))
 		}
 
-		if tracer := ctx.AmbientCtx.Tracer; tracer != nil {
-			dialOpts = append(dialOpts, grpc.WithUnaryInterceptor(
-				otgrpc.OpenTracingClientInterceptor(tracer),
-			))
+		if tracer, ok := ctx.AmbientCtx.Tracer.(*tracing.Tracer); ok {
+			dialOpts = append(dialOpts,
+				grpc.WithUnaryInterceptor(tracing.ClientInterceptor(tracer)),
+				grpc.WithStatsHandler(tracing.NewRPCNetworkStatsHandler()),
+			)
 		}
 
 		if log.V(1) {
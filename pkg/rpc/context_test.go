@@ -25,6 +25,7 @@ import (
 	"testing"
 	"time"
 
+	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
@@ -41,6 +42,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/netutil"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
 func newTestServer(t testing.TB, ctx *Context, compression bool) (*grpc.Server, net.Listener) {
@@ -672,6 +674,56 @@ func TestGRPCKeepaliveFailureFailsInflightRPCs(t *testing.T) {
 	// sufficiently tested in TestHeartbeatHealthTransport.
 }
 
+// TestRPCTracing verifies that the context of a traced RPC is propagated to
+// the server and that the client span gets the server's timing.
+func TestRPCTracing(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+
+	tr := tracing.NewTracer()
+	clock := hlc.NewClock(time.Unix(0, 20).UnixNano, time.Nanosecond)
+	serverCtx := NewContext(
+		log.AmbientContext{Tracer: tr}, testutils.NewNodeTestBaseContext(), clock, stopper)
+	ln, err := netutil.ListenAndServeGRPC(stopper, NewServer(serverCtx), util.TestAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCtx := NewContext(
+		log.AmbientContext{Tracer: tr}, testutils.NewNodeTestBaseContext(), clock, stopper)
+	conn, err := clientCtx.GRPCDial(ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sp := tr.StartSpan("test", tracing.Recordable)
+	tracing.StartRecording(sp, tracing.SingleNodeRecording)
+	ctx := opentracing.ContextWithSpan(context.Background(), sp)
+	if _, err := NewHeartbeatClient(conn).Ping(ctx, &PingRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	sp.Finish()
+
+	var found bool
+	for _, rec := range tracing.GetRecording(sp) {
+		if rec.Operation != "/cockroach.rpc.Heartbeat/Ping" {
+			continue
+		}
+		found = true
+		if _, ok := rec.Tags[tracing.TagRPCServerDuration]; !ok {
+			t.Errorf("expected the server timing on the client span, got tags %v", rec.Tags)
+		}
+	}
+	if !found {
+		t.Fatalf("expected a client span in %v", tracing.GetRecording(sp))
+	}
+	if st := tr.(*tracing.Tracer).RPCSpanStats(); st.Spans == 0 {
+		t.Errorf("expected the RPC to be counted, got %+v", st)
+	}
+}
+
 func BenchmarkGRPCDial(b *testing.B) {
 	stopper := stop.NewStopper()
 	defer stopper.Stop(context.TODO())
@@ -49,6 +49,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -60,6 +61,10 @@ const (
 	// defaultAPIEventLimit is the default maximum number of events returned by any
 	// endpoints returning events.
 	defaultAPIEventLimit = 1000
+
+	// drainTraceFlushTimeout bounds the time spent sending out buffered traces
+	// when the node is shutting down.
+	drainTraceFlushTimeout = 5 * time.Second
 )
 
 // apiServerMessage is the standard body for all HTTP 500 responses.
@@ -1077,6 +1082,18 @@ func (s *adminServer) Drain(req *serverpb.DrainRequest, stream serverpb.Admin_Dr
 		return nil
 	}
 
+	// Send out the traces from the last moments before the shutdown. This
+	// needs to happen before gRPC is stopped, which cancels the stream's
+	// context.
+	if tr, ok := s.server.cfg.AmbientCtx.Tracer.(*tracing.Tracer); ok {
+		flushCtx, cancel := context.WithTimeout(
+			s.server.AnnotateCtx(context.Background()), drainTraceFlushTimeout)
+		if err := tr.Flush(flushCtx); err != nil {
+			log.Warningf(flushCtx, "failed to flush traces: %s", err)
+		}
+		cancel()
+	}
+
 	s.server.grpc.Stop()
 
 	ctx := stream.Context()
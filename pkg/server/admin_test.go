@@ -37,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
@@ -1109,3 +1110,48 @@ func TestAdminAPIRangeLog(t *testing.T) {
 		})
 	}
 }
+
+func TestDrainFlushesTraces(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	shutdown := false
+	defer func() {
+		if !shutdown {
+			s.Stopper().Stop(context.TODO())
+		}
+	}()
+	ts := s.(*TestServer)
+
+	backend := tracing.NewSimulatedBackend(tracing.SimulationOptions{})
+	tr := ts.Cfg.AmbientCtx.Tracer.(*tracing.Tracer)
+	tr.SetSimulatedBackend(backend)
+	tr.StartSpan("before drain").Finish()
+
+	rpcContext := rpc.NewContext(
+		log.AmbientContext{Tracer: tracing.NewTracer()}, ts.RPCContext().Config, ts.Clock(), ts.Stopper(),
+	)
+	conn, err := rpcContext.GRPCDial(ts.ServingAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := serverpb.NewAdminClient(conn).Drain(
+		context.Background(), &serverpb.DrainRequest{Shutdown: true},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdown = true
+	for {
+		if _, err := stream.Recv(); err != nil {
+			break
+		}
+	}
+	<-ts.Stopper().IsStopped()
+
+	if stats := backend.Stats(); stats.Flushes == 0 {
+		t.Errorf("expected the traces to be flushed, got %+v", stats)
+	}
+	if sps := backend.SpansByOperation("before drain"); len(sps) != 1 {
+		t.Errorf("expected the span to be exported, got %v", sps)
+	}
+}
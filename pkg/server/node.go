@@ -330,6 +330,9 @@ func (n *Node) initNodeID(id roachpb.NodeID) {
 	}
 	// Gossip the node descriptor to make this node addressable by node ID.
 	n.Descriptor.NodeID = id
+	if tr, ok := n.storeCfg.AmbientCtx.Tracer.(*tracing.Tracer); ok {
+		tr.SetNodeID(int32(id))
+	}
 	if err = n.storeCfg.Gossip.SetNodeDescriptor(&n.Descriptor); err != nil {
 		log.Fatalf(ctx, "couldn't gossip descriptor for node %d: %s", n.Descriptor.NodeID, err)
 	}
@@ -920,7 +923,7 @@ func (n *Node) setupSpanForIncomingRPC(
 			// spans in the BatchResponse at the end of the request.
 			// We don't want to do this if the operation is on the same host, in which
 			// case everything is already part of the same recording.
-			if rec := tracing.GetRecording(sp); rec != nil {
+			if rec := tracing.GetRecording(sp, tracing.ForRequester()); rec != nil {
 				br.CollectedSpans = append(br.CollectedSpans, rec...)
 			}
 		}
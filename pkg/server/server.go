@@ -41,6 +41,8 @@ import (
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
 
 	"github.com/cockroachdb/cmux"
 	"github.com/cockroachdb/cockroach/pkg/base"
@@ -63,6 +65,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ui"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -146,6 +149,21 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	if tr, ok := cfg.AmbientCtx.Tracer.(stop.Closer); ok {
 		stopper.AddCloser(tr)
 	}
+	if tr, ok := cfg.AmbientCtx.Tracer.(*tracing.Tracer); ok {
+		tr.SetAbandonedSpanHandler(func(ctx context.Context, sp tracing.AbandonedSpan) {
+			log.Warningf(ctx, "abandoned %s", sp)
+		})
+		tr.SetSpanIDReuseHandler(func(r tracing.SpanIDReuse) {
+			log.Errorf(cfg.AmbientCtx.AnnotateCtx(context.Background()), "%s", r)
+		})
+		tr.SetBaggageAuthorizer(makeTraceBaggageAuthorizer(cfg.Insecure))
+		tr.RegisterRecordingSink(traceRecordingLogSink, tracing.RecordingSinkFunc(
+			func(rec tracing.Recording) {
+				log.Infof(cfg.AmbientCtx.AnnotateCtx(context.Background()), "trace:\n%s", rec)
+			}))
+		tr.StartMaintenance(stopper)
+		s.registry.AddMetricStruct(makeTracingMetrics(tr))
+	}
 
 	// Attempt to load TLS configs right away, failures are permanent.
 	if certMgr, err := cfg.InitializeNodeTLSConfigs(stopper); err != nil {
@@ -472,6 +490,7 @@ type ListenError struct {
 // should represent the general startup operation.
 func (s *Server) Start(ctx context.Context) error {
 	ctx = s.AnnotateCtx(ctx)
+	grpcutil.SetTracingLogger(ctx)
 
 	startTime := timeutil.Now()
 
@@ -1040,6 +1059,18 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		defer gzw.Close()
 		w = gzw
 	}
+
+	if tr, ok := s.cfg.AmbientCtx.Tracer.(*tracing.Tracer); ok {
+		// Continue the client's trace, if the request carries its context. If
+		// not, the context is a noop context and so is the span.
+		if wireContext, err := tr.ExtractExternal(
+			opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header),
+		); err == nil {
+			sp := tr.StartSpan(r.URL.Path, opentracing.ChildOf(wireContext))
+			defer sp.Finish()
+			r = r.WithContext(opentracing.ContextWithSpan(r.Context(), sp))
+		}
+	}
 	s.mux.ServeHTTP(w, r)
 }
 
@@ -1108,3 +1139,35 @@ func officialAddr(
 
 	return util.NewUnresolvedAddr(lnAddr.Network(), net.JoinHostPort(host, lnPort)), nil
 }
+
+// traceRecordingLogSink is the name of the recording sink which writes the
+// recordings routed to it by trace.recording.routes to the log.
+const traceRecordingLogSink = "log"
+
+// makeTraceBaggageAuthorizer returns the authorizer of privileged baggage
+// items, which are only honored for other nodes and root (or any client in
+// insecure mode).
+func makeTraceBaggageAuthorizer(insecure bool) tracing.BaggageAuthorizer {
+	return func(carrier interface{}, key, value string) bool {
+		if user, ok := tracing.CarrierUser(carrier); ok {
+			return insecure || user == security.RootUser
+		}
+		ctx, ok := tracing.IncomingRPCContext(carrier)
+		if !ok {
+			return false
+		}
+		if insecure {
+			return true
+		}
+		p, ok := peer.FromContext(ctx)
+		if !ok {
+			return false
+		}
+		tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+		if !ok {
+			return false
+		}
+		user, err := security.GetCertificateUser(&tlsInfo.State)
+		return err == nil && (user == security.NodeUser || user == security.RootUser)
+	}
+}
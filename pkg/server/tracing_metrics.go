@@ -0,0 +1,150 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// tracingMetrics expose the overhead of the tracer, as measured while the
+// trace.self_measurement.enabled setting is set (see tracing.Tracer.Overhead),
+// and the stats of the tracer's sampling decisions and of its RPC and component
+// spans.
+type tracingMetrics struct {
+	StartSpanCount *metric.Gauge
+	StartSpanNanos *metric.Gauge
+	LogCount       *metric.Gauge
+	LogNanos       *metric.Gauge
+	FinishCount    *metric.Gauge
+	FinishNanos    *metric.Gauge
+	InjectCount    *metric.Gauge
+	InjectNanos    *metric.Gauge
+
+	SampledKept    *metric.Gauge
+	SampledDropped *metric.Gauge
+
+	RPCSpans    *metric.Gauge
+	RPCPromoted *metric.Gauge
+
+	SQLComponent     tracingComponentMetrics
+	KVComponent      tracingComponentMetrics
+	StorageComponent tracingComponentMetrics
+	RPCComponent     tracingComponentMetrics
+}
+
+// MetricStruct implements the metrics.Struct interface.
+func (tracingMetrics) MetricStruct() {}
+
+var _ metric.Struct = tracingMetrics{}
+
+func makeTracingMetrics(tr *tracing.Tracer) tracingMetrics {
+	gauges := func(
+		path string, get func(tracing.OverheadStats) tracing.PathOverhead,
+	) (count, nanos *metric.Gauge) {
+		prefix := "tracing.overhead." + path
+		count = metric.NewFunctionalGauge(
+			metric.Metadata{
+				Name: prefix + ".count",
+				Help: "Number of measured calls to the tracer's " + path + " code path"},
+			func() int64 { return get(tr.Overhead()).Calls },
+		)
+		nanos = metric.NewFunctionalGauge(
+			metric.Metadata{
+				Name: prefix + ".ns",
+				Help: "Total time spent in the tracer's " + path + " code path, in nanoseconds"},
+			func() int64 { return int64(get(tr.Overhead()).Time) },
+		)
+		return count, nanos
+	}
+	var m tracingMetrics
+	m.StartSpanCount, m.StartSpanNanos = gauges("start_span",
+		func(s tracing.OverheadStats) tracing.PathOverhead { return s.StartSpan })
+	m.LogCount, m.LogNanos = gauges("log",
+		func(s tracing.OverheadStats) tracing.PathOverhead { return s.Log })
+	m.FinishCount, m.FinishNanos = gauges("finish",
+		func(s tracing.OverheadStats) tracing.PathOverhead { return s.Finish })
+	m.InjectCount, m.InjectNanos = gauges("inject",
+		func(s tracing.OverheadStats) tracing.PathOverhead { return s.Inject })
+	m.SampledKept = metric.NewFunctionalGauge(
+		metric.Metadata{
+			Name: "tracing.sampling.kept",
+			Help: "Number of new traces sent to the shadow tracer"},
+		func() int64 { return tr.SamplingStats().Kept },
+	)
+	m.SampledDropped = metric.NewFunctionalGauge(
+		metric.Metadata{
+			Name: "tracing.sampling.dropped",
+			Help: "Number of new traces not sent to the shadow tracer because of trace.sample_rate"},
+		func() int64 { return tr.SamplingStats().Dropped },
+	)
+	m.RPCSpans = metric.NewFunctionalGauge(
+		metric.Metadata{
+			Name: "tracing.rpc.spans",
+			Help: "Number of RPCs traced by the tracer's gRPC client interceptor"},
+		func() int64 { return tr.RPCSpanStats().Spans },
+	)
+	m.RPCPromoted = metric.NewFunctionalGauge(
+		metric.Metadata{
+			Name: "tracing.rpc.promoted",
+			Help: "Number of RPCs traced with a recording because of trace.rpc.record_one_in"},
+		func() int64 { return tr.RPCSpanStats().Promoted },
+	)
+	m.SQLComponent = makeTracingComponentMetrics(tr, tracing.ComponentSQL)
+	m.KVComponent = makeTracingComponentMetrics(tr, tracing.ComponentKV)
+	m.StorageComponent = makeTracingComponentMetrics(tr, tracing.ComponentStorage)
+	m.RPCComponent = makeTracingComponentMetrics(tr, tracing.ComponentRPC)
+	return m
+}
+
+// tracingComponentMetrics expose the stats of the spans of a component; see
+// tracing.Tracer.ComponentStats.
+type tracingComponentMetrics struct {
+	Spans         *metric.Gauge
+	DurationNanos *metric.Gauge
+	RecordedBytes *metric.Gauge
+}
+
+// MetricStruct implements the metrics.Struct interface.
+func (tracingComponentMetrics) MetricStruct() {}
+
+var _ metric.Struct = tracingComponentMetrics{}
+
+func makeTracingComponentMetrics(tr *tracing.Tracer, component string) tracingComponentMetrics {
+	prefix := "tracing.component." + component
+	stats := func() tracing.ComponentStats {
+		return tr.ComponentStats()[component]
+	}
+	return tracingComponentMetrics{
+		Spans: metric.NewFunctionalGauge(
+			metric.Metadata{
+				Name: prefix + ".spans",
+				Help: "Number of spans created by the " + component + " component"},
+			func() int64 { return stats().Spans },
+		),
+		DurationNanos: metric.NewFunctionalGauge(
+			metric.Metadata{
+				Name: prefix + ".duration_ns",
+				Help: "Total duration of the finished spans of the " + component + " component, in nanoseconds"},
+			func() int64 { return int64(stats().Duration) },
+		),
+		RecordedBytes: metric.NewFunctionalGauge(
+			metric.Metadata{
+				Name: prefix + ".recorded_bytes",
+				Help: "Estimated size of the messages recorded in the spans of the " + component + " component"},
+			func() int64 { return stats().RecordedBytes },
+		),
+	}
+}
@@ -179,7 +179,7 @@ func DrainAndForwardMetadata(ctx context.Context, src RowSource, dst RowReceiver
 
 func sendTraceData(ctx context.Context, dst RowReceiver) {
 	if sp := opentracing.SpanFromContext(ctx); sp != nil {
-		if rec := tracing.GetRecording(sp); rec != nil {
+		if rec := tracing.GetRecording(sp, tracing.ForRequester()); rec != nil {
 			dst.Push(nil /* row */, ProducerMetadata{TraceData: rec})
 		}
 	}
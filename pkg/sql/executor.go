@@ -571,6 +571,7 @@ func (e *Executor) ExecutePreparedStatement(
 		session.phaseTimes[sessionStartParse] = now
 		session.phaseTimes[sessionEndParse] = now
 	}
+	session.forceTrace = strings.HasPrefix(strings.TrimSpace(stmt.Str), traceForceHint)
 
 	return e.execPrepared(session, stmt, pinfo)
 }
@@ -616,6 +617,11 @@ func (session *Session) CopyEnd(ctx context.Context) {
 	session.copyFrom = nil
 }
 
+// traceForceHint is the comment which, at the start of a batch of statements,
+// forces the transactions started by the batch to be fully traced regardless
+// of the sampling configuration; see tracing.ForceTrace.
+const traceForceHint = "/*+ trace-force */"
+
 // execRequest executes the request in the provided Session.
 // It parses the sql into statements, iterates through the statements, creates
 // KV transactions and automatically retries them when possible, and executes
@@ -640,6 +646,7 @@ func (e *Executor) execRequest(
 		log.Infof(session.Ctx(), "execRequest: %s", sql)
 	}
 
+	session.forceTrace = strings.HasPrefix(strings.TrimSpace(sql), traceForceHint)
 	session.phaseTimes[sessionStartParse] = timeutil.Now()
 	if session.copyFrom != nil {
 		stmts, err = session.ProcessCopyData(session.Ctx(), sql, copymsg)
@@ -727,8 +734,8 @@ func (e *Executor) execParsed(
 			}
 			txnState.resetForNewSQLTxn(
 				e, session,
-				execOpt.AutoCommit, /* implicitTxn */
-				false,              /* retryIntent */
+				execOpt.AutoCommit,         /* implicitTxn */
+				false,                      /* retryIntent */
 				e.cfg.Clock.PhysicalTime(), /* sqlTimestamp */
 				session.DefaultIsolationLevel,
 				roachpb.NormalUserPriority,
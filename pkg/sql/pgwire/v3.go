@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/lib/pq/oid"
+	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
@@ -236,6 +237,13 @@ func parseOptions(ctx context.Context, data []byte) (sql.SessionArgs, error) {
 		case "application_name":
 			args.ApplicationName = value
 		default:
+			if args.TraceCarrier == nil {
+				args.TraceCarrier = opentracing.TextMapCarrier{}
+			}
+			// The other parameters can carry the context of the client's
+			// trace. The fields of the carrier that are not part of a span
+			// context are ignored.
+			args.TraceCarrier[key] = value
 			if log.V(1) {
 				log.Warningf(ctx, "unrecognized configuration parameter %q", key)
 			}
@@ -176,3 +176,21 @@ func TestReadTimeoutConnExits(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+// TestParseOptionsTraceCarrier verifies that the unrecognized startup
+// parameters are kept as the carrier of the client's trace context.
+func TestParseOptionsTraceCarrier(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	data := []byte("user\x00root\x00traceparent\x00tp\x00ot-tracer-traceid\x00a\x00\x00")
+	args, err := parseOptions(context.Background(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.User != "root" {
+		t.Errorf("expected user root, got %q", args.User)
+	}
+	if len(args.TraceCarrier) != 2 || args.TraceCarrier["traceparent"] != "tp" ||
+		args.TraceCarrier["ot-tracer-traceid"] != "a" {
+		t.Errorf("unexpected trace carrier %v", args.TraceCarrier)
+	}
+}
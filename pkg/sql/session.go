@@ -287,6 +287,14 @@ type Session struct {
 
 	Tracing SessionTracing
 
+	// forceTrace is set when the current batch of statements starts with
+	// traceForceHint.
+	forceTrace bool
+
+	// clientTraceContext is the context of the client's trace, if the client
+	// passed one when connecting. The session's transactions continue it.
+	clientTraceContext opentracing.SpanContext
+
 	tables TableCollection
 
 	// If set, contains the in progress COPY FROM columns.
@@ -357,6 +365,9 @@ type SessionArgs struct {
 	Database        string
 	User            string
 	ApplicationName string
+	// TraceCarrier holds the parameters passed by the client which can carry
+	// the context of its trace; see tracing.Tracer.ExtractExternal.
+	TraceCarrier opentracing.TextMapCarrier
 }
 
 // SessionRegistry stores a set of all sessions on this node.
@@ -429,6 +440,15 @@ func NewSession(
 			databaseCache: e.getDatabaseCache(),
 		},
 	}
+	if len(args.TraceCarrier) > 0 {
+		if tr, ok := e.cfg.AmbientCtx.Tracer.(*tracing.Tracer); ok {
+			// A malformed context is ignored; the session is then not part of
+			// the client's trace. The user is passed on to the tracer's
+			// BaggageAuthorizer.
+			s.clientTraceContext, _ = tr.ExtractExternal(
+				opentracing.TextMap, tracing.WithCarrierUser(args.TraceCarrier, args.User))
+		}
+	}
 	s.phaseTimes[sessionInit] = timeutil.Now()
 	s.resetApplicationName(args.ApplicationName)
 	s.PreparedStatements = makePreparedStatements(s)
@@ -902,13 +922,22 @@ func (ts *txnState) resetForNewSQLTxn(
 		opName = sqlTxnName
 	}
 
+	opts := []opentracing.StartSpanOption{
+		tracing.Recordable, tracing.Component(tracing.ComponentSQL),
+	}
+	if s.forceTrace {
+		opts = append(opts, tracing.ForceTrace)
+	}
 	if parentSp := opentracing.SpanFromContext(ctx); parentSp != nil {
 		// Create a child span for this SQL txn.
 		sp = parentSp.Tracer().StartSpan(
-			opName, opentracing.ChildOf(parentSp.Context()), tracing.Recordable)
+			opName, append(opts, opentracing.ChildOf(parentSp.Context()))...)
+	} else if s.clientTraceContext != nil {
+		// Create a child span of the client's span for this SQL txn.
+		sp = tracer.StartSpan(opName, append(opts, opentracing.ChildOf(s.clientTraceContext))...)
 	} else {
 		// Create a root span for this SQL txn.
-		sp = tracer.StartSpan(opName, tracing.Recordable)
+		sp = tracer.StartSpan(opName, opts...)
 	}
 
 	// Start recording for the traceTxnThreshold and debugTrace7881Enabled
@@ -938,6 +967,12 @@ func (ts *txnState) resetForNewSQLTxn(
 	ts.Ctx = ctx
 	ts.SetState(FirstBatch)
 	s.Tracing.onNewSQLTxn(ts.sp)
+	// The traces started on behalf of the txn (e.g. for background work) are
+	// grouped by the correlation ID. There is nothing to group if the txn is
+	// not traced.
+	if !tracing.IsBlackHoleSpan(sp) {
+		tracing.SetCorrelationID(sp, uuid.MakeV4().String())
+	}
 
 	ts.mon.Start(ctx, &s.mon, mon.BoundAccount{})
 
@@ -1235,8 +1270,7 @@ func (st *SessionTracing) StopTracing() error {
 	if sp == nil {
 		return errors.Errorf("no span for SessionTracing")
 	}
-	spans := tracing.GetRecording(sp)
-	tracing.StopRecording(sp)
+	spans := tracing.StopRecordingAndGet(sp)
 	if spans == nil {
 		return errors.Errorf("nil recording")
 	}
@@ -1354,6 +1388,10 @@ func (st *SessionTracing) generateSessionTraceVTable() ([]traceRow, error) {
 	// Get all the log messages, in the right order.
 	var allLogs []logRecordRow
 	for txnIdx, spans := range st.txnRecordings {
+		// Remote spans might have been recorded by nodes with skewed clocks;
+		// align them with their parents so that messages are interleaved
+		// correctly.
+		tracing.AlignRecording(spans)
 		seenSpans := make(map[uint64]struct{})
 
 		// The spans are recorded in the order in which they are started, so the
@@ -26,9 +26,12 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
 func TestTrace(t *testing.T) {
@@ -236,3 +239,34 @@ func TestTrace(t *testing.T) {
 		})
 	}
 }
+
+func TestTraceForceHint(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	tr := s.(*server.TestServer).Cfg.AmbientCtx.Tracer.(*tracing.Tracer)
+	c := tracing.NewTestCollector()
+	tr.SetTestCollector(c)
+
+	if _, err := sqlDB.Exec("SET CLUSTER SETTING trace.sample_rate = 0"); err != nil {
+		t.Fatal(err)
+	}
+	// Wait for the setting to take effect.
+	testutils.SucceedsSoon(t, func() error {
+		c.Reset()
+		if _, err := sqlDB.Exec("SELECT 1"); err != nil {
+			t.Fatal(err)
+		}
+		if sps := c.SpansByOperation("sql txn implicit"); len(sps) != 0 {
+			return fmt.Errorf("expected the txn not to be traced, got %v", sps)
+		}
+		return nil
+	})
+	if _, err := sqlDB.Exec("/*+ trace-force */ SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+	if sps := c.SpansByOperation("sql txn implicit"); len(sps) != 1 {
+		t.Fatalf("expected the txn to be traced, got %v", sps)
+	}
+}
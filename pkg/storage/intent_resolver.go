@@ -31,8 +31,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	opentracing "github.com/opentracing/opentracing-go"
 	"golang.org/x/net/context"
 )
 
@@ -208,9 +210,25 @@ func (ir *intentResolver) maybePushTransactions(
 	b := &client.Batch{}
 	b.AddRawRequest(pushReqs...)
 	var pErr *roachpb.Error
+	pushStart := timeutil.Now()
 	if err := ir.store.db.Run(ctx, b); err != nil {
 		pErr = b.MustPErr()
 	}
+	if sp := opentracing.SpanFromContext(ctx); sp != nil && !tracing.IsBlackHoleSpan(sp) {
+		wait := timeutil.Since(pushStart)
+		var waitingTxn fmt.Stringer
+		if partialPusherTxn.ID != nil {
+			waitingTxn = *partialPusherTxn.ID
+		}
+		for _, intent := range pushIntents {
+			tracing.RecordContention(sp, tracing.ContentionEvent{
+				Key:        intent.Key,
+				WaitingTxn: waitingTxn,
+				HolderTxn:  *intent.Txn.ID,
+				Duration:   wait,
+			})
+		}
+	}
 	ir.mu.Lock()
 	for _, intent := range pushIntents {
 		ir.mu.inFlight[*intent.Txn.ID]--
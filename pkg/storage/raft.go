@@ -44,6 +44,9 @@ func init() {
 // Raft is fairly verbose at the "info" level, so we map "info" messages to
 // clog.V(1) and "debug" messages to clog.V(2).
 //
+// Messages that are not logged because of their level are still recorded in
+// the span of the logger's context, if any.
+//
 // This file is named raft.go instead of something like logger.go because this
 // file's name is used to determine the vmodule parameter: --vmodule=raft=1
 type raftLogger struct {
@@ -53,24 +56,32 @@ type raftLogger struct {
 func (r *raftLogger) Debug(v ...interface{}) {
 	if log.V(3) {
 		log.InfofDepth(r.ctx, 1, "", v...)
+	} else if log.HasSpanOrEvent(r.ctx) {
+		log.Event(r.ctx, fmt.Sprint(v...))
 	}
 }
 
 func (r *raftLogger) Debugf(format string, v ...interface{}) {
 	if log.V(3) {
 		log.InfofDepth(r.ctx, 1, format, v...)
+	} else {
+		log.Eventf(r.ctx, format, v...)
 	}
 }
 
 func (r *raftLogger) Info(v ...interface{}) {
 	if log.V(2) {
 		log.InfofDepth(r.ctx, 1, "", v...)
+	} else if log.HasSpanOrEvent(r.ctx) {
+		log.Event(r.ctx, fmt.Sprint(v...))
 	}
 }
 
 func (r *raftLogger) Infof(format string, v ...interface{}) {
 	if log.V(2) {
 		log.InfofDepth(r.ctx, 1, format, v...)
+	} else {
+		log.Eventf(r.ctx, format, v...)
 	}
 }
 
@@ -115,6 +115,11 @@ var maxCommandSize = settings.RegisterByteSizeSetting(
 	"maximum size of a raft command",
 	64<<20)
 
+var slowBatchTraceThreshold = settings.RegisterNonNegativeDurationSetting(
+	"kv.trace.slow_batch_threshold",
+	"if nonzero, batches whose evaluation takes longer than this get their own trace span",
+	0)
+
 // raftInitialLog{Index,Term} are the starting points for the raft log. We
 // bootstrap the raft membership by synthesizing a snapshot as if there were
 // some discarded prefix to the log, so we must begin the log at an arbitrary
@@ -4687,6 +4692,9 @@ func optimizePuts(
 // evaluateBatch evaluates a batch request by splitting it up into its
 // individual commands, passing them to evaluateCommand, and combining
 // the results.
+//
+// If kv.trace.slow_batch_threshold is set, slow evaluations get a
+// retroactive trace span (see tracing.RunWithRetroactiveSpan).
 func evaluateBatch(
 	ctx context.Context,
 	idKey storagebase.CmdIDKey,
@@ -4694,6 +4702,29 @@ func evaluateBatch(
 	rec ReplicaEvalContext,
 	ms *enginepb.MVCCStats,
 	ba roachpb.BatchRequest,
+) (*roachpb.BatchResponse, EvalResult, *roachpb.Error) {
+	threshold := slowBatchTraceThreshold.Get()
+	if threshold == 0 {
+		return evaluateBatchInner(ctx, idKey, batch, rec, ms, ba)
+	}
+	var br *roachpb.BatchResponse
+	var result EvalResult
+	var pErr *roachpb.Error
+	_ = tracing.RunWithRetroactiveSpan(ctx, "evaluate batch", threshold,
+		func(ctx context.Context) error {
+			br, result, pErr = evaluateBatchInner(ctx, idKey, batch, rec, ms, ba)
+			return pErr.GoError()
+		})
+	return br, result, pErr
+}
+
+func evaluateBatchInner(
+	ctx context.Context,
+	idKey storagebase.CmdIDKey,
+	batch engine.ReadWriter,
+	rec ReplicaEvalContext,
+	ms *enginepb.MVCCStats,
+	ba roachpb.BatchRequest,
 ) (*roachpb.BatchResponse, EvalResult, *roachpb.Error) {
 	br := ba.CreateReply()
 
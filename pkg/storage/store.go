@@ -3138,6 +3138,8 @@ func (s *Store) processRaftRequest(
 				// crashing potential for any choice of dummy value below.
 				appliedIndex,
 				r.store.cfg,
+				// Raft's output while applying the snapshot goes to the trace of
+				// the snapshot request, if any; see raftLogger.
 				&raftLogger{ctx: ctx},
 			), nil)
 		if err != nil {
@@ -40,8 +40,7 @@ func MakeRecordCtx() (context.Context, func() string) {
 
 	return ctx, func() string {
 		once.Do(func() {
-			dump = tracing.FormatRecordedSpans(tracing.GetRecording(sp))
-			tracing.StopRecording(sp)
+			dump = tracing.FormatRecordedSpans(tracing.StopRecordingAndGet(sp))
 			sp.Finish()
 			cancel()
 		})
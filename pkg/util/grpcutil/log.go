@@ -16,10 +16,12 @@
 package grpcutil
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 	"time"
 
+	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/petermattis/goid"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/grpclog"
@@ -27,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
 func init() {
@@ -84,6 +87,76 @@ func (*logger) Println(args ...interface{}) {
 	log.InfofDepth(context.TODO(), 2, "", args...)
 }
 
+// SetTracingLogger replaces the logger installed by this package with a
+// tracing.LibraryLogger which logs gRPC's messages with the log tags of the
+// given context. The logger is process-wide and outlives the operation that
+// installs it, so the context's span is not used.
+func SetTracingLogger(ctx context.Context) {
+	ctx = opentracing.ContextWithSpan(ctx, nil)
+	grpclog.SetLogger(tracing.NewLibraryLogger(ctx, &leveledLogger{ctx: ctx}))
+}
+
+// leveledLogger is the tracing.LeveledLogger that writes gRPC's messages to the
+// log. We pass a depth of 3 for the frames of the tracing.LibraryLogger and of
+// the grpclog adapter.
+type leveledLogger struct {
+	ctx context.Context
+}
+
+var _ tracing.LeveledLogger = (*leveledLogger)(nil)
+
+func (l *leveledLogger) Debug(args ...interface{}) {
+	log.InfofDepth(l.ctx, 3, "", args...)
+}
+
+func (l *leveledLogger) Debugf(format string, args ...interface{}) {
+	log.InfofDepth(l.ctx, 3, format, args...)
+}
+
+func (l *leveledLogger) Info(args ...interface{}) {
+	log.InfofDepth(l.ctx, 3, "", args...)
+}
+
+func (l *leveledLogger) Infof(format string, args ...interface{}) {
+	if shouldPrint(transportFailedRe, connectionRefusedRe, time.Minute, format, args...) {
+		log.InfofDepth(l.ctx, 3, format, args...)
+	}
+}
+
+func (l *leveledLogger) Warning(args ...interface{}) {
+	log.WarningfDepth(l.ctx, 3, "", args...)
+}
+
+func (l *leveledLogger) Warningf(format string, args ...interface{}) {
+	log.WarningfDepth(l.ctx, 3, format, args...)
+}
+
+func (l *leveledLogger) Error(args ...interface{}) {
+	log.ErrorfDepth(l.ctx, 3, "", args...)
+}
+
+func (l *leveledLogger) Errorf(format string, args ...interface{}) {
+	log.ErrorfDepth(l.ctx, 3, format, args...)
+}
+
+func (l *leveledLogger) Fatal(args ...interface{}) {
+	log.FatalfDepth(l.ctx, 3, "", args...)
+}
+
+func (l *leveledLogger) Fatalf(format string, args ...interface{}) {
+	log.FatalfDepth(l.ctx, 3, format, args...)
+}
+
+func (l *leveledLogger) Panic(args ...interface{}) {
+	log.ErrorfDepth(l.ctx, 3, "", args...)
+	panic(fmt.Sprint(args...))
+}
+
+func (l *leveledLogger) Panicf(format string, args ...interface{}) {
+	log.ErrorfDepth(l.ctx, 3, format, args...)
+	panic(fmt.Sprintf(format, args...))
+}
+
 var spamMu = struct {
 	syncutil.Mutex
 	gids map[int64]time.Time
@@ -39,6 +39,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/util/caller"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/petermattis/goid"
 )
 
@@ -768,12 +769,32 @@ func (l *loggingT) outputLogEntry(s Severity, file string, line int, msg string)
 	l.mu.Unlock()
 	// Flush and exit on fatal logging.
 	if s == Severity_FATAL {
+		l.dumpTraces()
 		// If we got here via Exit rather than Fatal, print no stacks.
 		timeoutFlush(10 * time.Second)
 		exitFunc(255) // C++ uses -1, which is silly because it's anded with 255 anyway.
 	}
 }
 
+// dumpTraces writes the in-flight traces (see tracing.EmergencyDump) to the
+// log file or, if logging to files is disabled, to stderr. Called on fatal
+// errors.
+func (l *loggingT) dumpTraces() {
+	var buf bytes.Buffer
+	tracing.EmergencyDump(&buf)
+	if buf.Len() == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if logDir.isSet() && l.file != nil {
+		_, _ = l.file.Write(buf.Bytes())
+		_ = l.file.Flush()
+		return
+	}
+	_, _ = OrigStderr.Write(buf.Bytes())
+}
+
 func (l *loggingT) outputToStderr(entry Entry, stacks []byte) {
 	buf := l.processForStderr(entry, stacks)
 	if _, err := OrigStderr.Write(buf.Bytes()); err != nil {
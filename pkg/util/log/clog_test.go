@@ -35,8 +35,11 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/kr/pretty"
+	opentracing "github.com/opentracing/opentracing-go"
 
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/caller"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
 // Test that shortHostname works as advertised.
@@ -100,6 +103,28 @@ func setFlags() {
 	logging.stderrThreshold = Severity_ERROR
 }
 
+// Test that the IDs of the span in the context are logged when
+// trace.log_span_ids.enabled is set.
+func TestInfoSpanIDs(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+	sp := tracing.NewTracer().StartSpan("test", tracing.Recordable)
+	defer sp.Finish()
+	ctx := opentracing.ContextWithSpan(context.Background(), sp)
+	ids := tracing.SpanString(sp)
+	Info(ctx, "off")
+	if contains("["+ids+"] off", t) {
+		t.Errorf("unexpected span IDs in the log: %q", contents())
+	}
+	defer settings.TestingSetBool(&logSpanIDs, true)()
+	Info(ctx, "on")
+	if !contains("["+ids+"] on", t) {
+		t.Errorf("expected the span's IDs %q in the log: %q", ids, contents())
+	}
+}
+
 // Test that Info works as advertised.
 func TestInfo(t *testing.T) {
 	s := ScopeWithoutShowLogs(t)
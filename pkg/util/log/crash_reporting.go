@@ -100,6 +100,9 @@ func ReportPanic(ctx context.Context, r interface{}, depth int) {
 	}
 	sendCrashReport(ctx, reportable, depth+3)
 
+	// The in-flight traces can help figure out what led to the panic.
+	logging.dumpTraces()
+
 	// Ensure that the logs are flushed before letting a panic
 	// terminate the server.
 	Flush()
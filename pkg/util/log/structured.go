@@ -24,10 +24,19 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/caller"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	opentracing "github.com/opentracing/opentracing-go"
 	otlog "github.com/opentracing/opentracing-go/log"
 )
 
+var logSpanIDs = settings.RegisterBoolSetting(
+	"trace.log_span_ids.enabled",
+	"if set, log messages are prefixed with the IDs of the span of their context (if any)",
+	false,
+)
+
 // msgBuf extends bytes.Buffer and implements otlog.Encoder.
 type msgBuf struct {
 	bytes.Buffer
@@ -154,6 +163,12 @@ func addStructured(ctx context.Context, s Severity, depth int, format string, ar
 	}
 	// MakeMessage already added the tags when forming msg, we don't want
 	// eventInternal to prepend them again.
-	eventInternal(ctx, (s >= Severity_ERROR), false /*withTags*/, "%s:%d %s", file, line, msg)
+	eventInternal(ctx, (s >= Severity_ERROR), false /*withTags*/, file, line, msg)
+	// The IDs of the span allow finding the message's trace.
+	if logSpanIDs.Get() {
+		if ids := tracing.SpanString(opentracing.SpanFromContext(ctx)); ids != "" {
+			msg = "[" + ids + "] " + msg
+		}
+	}
 	logging.outputLogEntry(s, file, line, msg)
 }
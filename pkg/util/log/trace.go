@@ -18,6 +18,7 @@ package log
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
@@ -106,8 +107,12 @@ func getSpanOrEventLog(ctx context.Context) (opentracing.Span, *ctxEventLog, boo
 }
 
 // eventInternal is the common code for logging an event. If no args are given,
-// the format is treated as a pre-formatted string.
-func eventInternal(ctx context.Context, isErr, withTags bool, format string, args ...interface{}) {
+// the format is treated as a pre-formatted string. If file is set, the location
+// of the caller is recorded in a separate field of the span's log record (see
+// tracing.FieldLocation), or prepended to the message in event logs.
+func eventInternal(
+	ctx context.Context, isErr, withTags bool, file string, line int, format string, args ...interface{},
+) {
 	if sp, el, ok := getSpanOrEventLog(ctx); ok {
 		var buf msgBuf
 		if withTags {
@@ -130,7 +135,14 @@ func eventInternal(ctx context.Context, isErr, withTags bool, format string, arg
 		if sp != nil {
 			// TODO(radu): pass tags directly to sp.LogKV when LightStep supports
 			// that.
-			sp.LogFields(otlog.String("event", msg))
+			if file != "" {
+				sp.LogFields(
+					otlog.String("event", msg),
+					otlog.String(tracing.FieldLocation, file+":"+strconv.Itoa(line)),
+				)
+			} else {
+				sp.LogFields(otlog.String("event", msg))
+			}
 			// if isErr {
 			// 	// TODO(radu): figure out a way to signal that this is an error. We
 			// 	// could use a different "error" key (provided it shows up in
@@ -139,6 +151,9 @@ func eventInternal(ctx context.Context, isErr, withTags bool, format string, arg
 			// 	// Baggage on the span. See #8827 for more discussion.
 			// }
 		} else {
+			if file != "" {
+				msg = fmt.Sprintf("%s:%d %s", file, line, msg)
+			}
 			el.Lock()
 			if el.eventLog != nil {
 				if isErr {
@@ -156,21 +171,21 @@ func eventInternal(ctx context.Context, isErr, withTags bool, format string, arg
 // message to it. If no Trace is found, it looks for an EventLog in the context
 // and logs the message to it. If neither is found, does nothing.
 func Event(ctx context.Context, msg string) {
-	eventInternal(ctx, false /*isErr*/, true /*withTags*/, msg)
+	eventInternal(ctx, false /*isErr*/, true /*withTags*/, "", 0, msg)
 }
 
 // Eventf looks for an opentracing.Trace in the context and formats and logs
 // the given message to it. If no Trace is found, it looks for an EventLog in
 // the context and logs the message to it. If neither is found, does nothing.
 func Eventf(ctx context.Context, format string, args ...interface{}) {
-	eventInternal(ctx, false /*isErr*/, true /*withTags*/, format, args...)
+	eventInternal(ctx, false /*isErr*/, true /*withTags*/, "", 0, format, args...)
 }
 
 // ErrEvent looks for an opentracing.Trace in the context and logs the given
 // message to it. If no Trace is found, it looks for an EventLog in the context
 // and logs the message to it (as an error). If neither is found, does nothing.
 func ErrEvent(ctx context.Context, msg string) {
-	eventInternal(ctx, true /*isErr*/, true /*withTags*/, msg)
+	eventInternal(ctx, true /*isErr*/, true /*withTags*/, "", 0, msg)
 }
 
 // ErrEventf looks for an opentracing.Trace in the context and formats and logs
@@ -178,7 +193,7 @@ func ErrEvent(ctx context.Context, msg string) {
 // the context and formats and logs the message to it (as an error). If neither
 // is found, does nothing.
 func ErrEventf(ctx context.Context, format string, args ...interface{}) {
-	eventInternal(ctx, true /*isErr*/, true /*withTags*/, format, args...)
+	eventInternal(ctx, true /*isErr*/, true /*withTags*/, "", 0, format, args...)
 }
 
 // VEvent either logs a message to the log files (which also outputs to the
@@ -189,7 +204,7 @@ func VEvent(ctx context.Context, level level, msg string) {
 		// Log to INFO (which also logs an event).
 		logDepth(ctx, 1, Severity_INFO, "", []interface{}{msg})
 	} else {
-		eventInternal(ctx, false /*isErr*/, true /*withTags*/, msg)
+		eventInternal(ctx, false /*isErr*/, true /*withTags*/, "", 0, msg)
 	}
 }
 
@@ -201,7 +216,7 @@ func VEventf(ctx context.Context, level level, format string, args ...interface{
 		// Log to INFO (which also logs an event).
 		logDepth(ctx, 1, Severity_INFO, format, args)
 	} else {
-		eventInternal(ctx, false /*isErr*/, true /*withTags*/, format, args...)
+		eventInternal(ctx, false /*isErr*/, true /*withTags*/, "", 0, format, args...)
 	}
 }
 
@@ -212,7 +227,7 @@ func VEventfDepth(ctx context.Context, depth int, level level, format string, ar
 		// Log to INFO (which also logs an event).
 		logDepth(ctx, 1+depth, Severity_INFO, format, args)
 	} else {
-		eventInternal(ctx, false /*isErr*/, true /*withTags*/, format, args...)
+		eventInternal(ctx, false /*isErr*/, true /*withTags*/, "", 0, format, args...)
 	}
 }
 
@@ -19,6 +19,7 @@ package log
 import (
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
 	"golang.org/x/net/context"
@@ -94,6 +95,11 @@ func TestTrace(t *testing.T) {
 	`); err != nil {
 		t.Fatal(err)
 	}
+	// Events logged through log.Info keep their location in a separate field.
+	rec := tracing.GetRecording(sp)
+	if loc := rec[0].Logs[3].Location(); !strings.Contains(loc, "trace_test.go:") {
+		t.Errorf("expected the location of the log call, got %q", loc)
+	}
 }
 
 func TestTraceWithTags(t *testing.T) {
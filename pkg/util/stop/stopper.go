@@ -261,7 +261,7 @@ func (s *Stopper) RunAsyncTask(
 	go func() {
 		defer s.Recover(ctx)
 		defer s.runPostlude(taskName)
-		defer tracing.FinishSpan(span)
+		defer tracing.FinishTaskSpan(span)
 
 		f(ctx)
 	}()
@@ -322,7 +322,7 @@ func (s *Stopper) RunLimitedAsyncTask(
 		defer s.Recover(ctx)
 		defer s.runPostlude(taskName)
 		defer func() { <-sem }()
-		defer tracing.FinishSpan(span)
+		defer tracing.FinishTaskSpan(span)
 
 		f(ctx)
 	}()
@@ -0,0 +1,58 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeutil
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// ManualTime is a clock which only moves when told to, for tests and
+// simulators that need deterministic timings. It is safe for concurrent use.
+type ManualTime struct {
+	mu struct {
+		syncutil.Mutex
+		now time.Time
+	}
+}
+
+// NewManualTime creates a ManualTime reading the given time.
+func NewManualTime(initial time.Time) *ManualTime {
+	m := &ManualTime{}
+	m.mu.now = initial
+	return m
+}
+
+// Now returns the current reading of the clock.
+func (m *ManualTime) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mu.now
+}
+
+// Advance moves the clock forward by the given duration.
+func (m *ManualTime) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mu.now = m.mu.now.Add(d)
+}
+
+// Set sets the reading of the clock. The clock is allowed to go backwards.
+func (m *ManualTime) Set(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mu.now = t
+}
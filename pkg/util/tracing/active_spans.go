@@ -0,0 +1,170 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// The span GC detects spans that were never finished (e.g. because of a
+// missing Finish on an error path), which would otherwise leak along with
+// their recordings.
+var (
+	spanGCMaxAge = settings.RegisterDurationSetting(
+		"trace.span_gc.max_age",
+		"if nonzero, open spans are tracked and spans open for longer than this are reported as abandoned",
+		0,
+	)
+	spanGCFinishAbandoned = settings.RegisterBoolSetting(
+		"trace.span_gc.finish_abandoned.enabled",
+		"if set, spans reported as abandoned by the span GC are finished",
+		false,
+	)
+)
+
+// spanGCInterval is the interval at which the span GC scans the open spans.
+const spanGCInterval = 10 * time.Second
+
+// TagAbandoned is set on the spans finished by the span GC.
+const TagAbandoned = "abandoned"
+
+// AbandonedSpan describes a span reported by the span GC.
+type AbandonedSpan struct {
+	Operation       string
+	TraceID, SpanID uint64
+	// Age is the time since the span was started.
+	Age  time.Duration
+	Tags map[string]interface{}
+	// Finished is set if the span GC finished the span.
+	Finished bool
+}
+
+func (a AbandonedSpan) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "span %q (trace=%x span=%x) open for %s", a.Operation, a.TraceID, a.SpanID, a.Age)
+	if a.Finished {
+		buf.WriteString(" (finished)")
+	}
+	keys := make([]string, 0, len(a.Tags))
+	for k := range a.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		// One tag per line, since the creation stack (see
+		// TracerOptions.CreationStacks) is long.
+		fmt.Fprintf(&buf, "\n  %s: %v", k, a.Tags[k])
+	}
+	return buf.String()
+}
+
+// activeSpans is the registry of the open spans of a Tracer, maintained while
+// trace.span_gc.max_age is set.
+type activeSpans struct {
+	syncutil.Mutex
+	spans map[*span]struct{}
+	// handler is called for each abandoned span; see SetAbandonedSpanHandler.
+	handler func(context.Context, AbandonedSpan)
+}
+
+// SetAbandonedSpanHandler sets the function called for each span found by the
+// span GC to be open for longer than trace.span_gc.max_age (generally, to log
+// the span). The handler is called from the Tracer's maintenance worker (see
+// StartMaintenance); spans are only reported once.
+func (t *Tracer) SetAbandonedSpanHandler(fn func(context.Context, AbandonedSpan)) {
+	t.activeSpans.Lock()
+	defer t.activeSpans.Unlock()
+	t.activeSpans.handler = fn
+}
+
+// maybeRegisterSpan adds a new span to the registry if the span GC is enabled.
+func (t *Tracer) maybeRegisterSpan(s *span) {
+	if spanGCMaxAge.Get() == 0 {
+		return
+	}
+	s.registered = true
+	t.activeSpans.Lock()
+	if t.activeSpans.spans == nil {
+		t.activeSpans.spans = make(map[*span]struct{})
+	}
+	t.activeSpans.spans[s] = struct{}{}
+	t.activeSpans.Unlock()
+}
+
+// finishRegistered is called when a registered span is finished; it returns
+// false if the span was already finished (by the span GC).
+func (s *span) finishRegistered() bool {
+	if !atomic.CompareAndSwapInt32(&s.finished, 0, 1) {
+		return false
+	}
+	s.tracer.activeSpans.Lock()
+	delete(s.tracer.activeSpans.spans, s)
+	s.tracer.activeSpans.Unlock()
+	return true
+}
+
+// collectAbandonedSpans reports (and optionally finishes) the spans that are
+// open for longer than trace.span_gc.max_age.
+func (t *Tracer) collectAbandonedSpans(ctx context.Context) {
+	maxAge := spanGCMaxAge.Get()
+	if maxAge == 0 {
+		return
+	}
+	now := t.now()
+	var abandoned []*span
+	t.activeSpans.Lock()
+	handler := t.activeSpans.handler
+	for s := range t.activeSpans.spans {
+		if now.Sub(s.startTime) > maxAge {
+			abandoned = append(abandoned, s)
+			// Spans are only reported once.
+			delete(t.activeSpans.spans, s)
+		}
+	}
+	t.activeSpans.Unlock()
+
+	finish := spanGCFinishAbandoned.Get()
+	for _, s := range abandoned {
+		info := AbandonedSpan{
+			Operation: s.operation,
+			TraceID:   s.TraceID,
+			SpanID:    s.SpanID,
+			Age:       now.Sub(s.startTime),
+			Tags:      GetSpanTags(s),
+		}
+		// The span can no longer be finished by its owner once it is marked as
+		// finished here.
+		if finish && atomic.CompareAndSwapInt32(&s.finished, 0, 1) {
+			// Bypass the ownership checks, since the span is generally owned by
+			// another goroutine.
+			s.setTagInner(TagAbandoned, true, false /* locked */)
+			s.finish(opentracing.FinishOptions{})
+			info.Finished = true
+		}
+		if handler != nil {
+			handler(ctx, info)
+		}
+	}
+}
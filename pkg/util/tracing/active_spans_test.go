@@ -0,0 +1,73 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestSpanGC(t *testing.T) {
+	defer settings.TestingSetDuration(&spanGCMaxAge, time.Hour)()
+	tr := NewTracer().(*Tracer)
+	var reported []AbandonedSpan
+	tr.SetAbandonedSpanHandler(func(_ context.Context, sp AbandonedSpan) {
+		reported = append(reported, sp)
+	})
+
+	old := tr.StartSpan("old", Recordable, opentracing.StartTime(time.Now().Add(-2*time.Hour)))
+	old.SetTag("k", "v")
+	StartRecording(old, SingleNodeRecording)
+	recent := tr.StartSpan("recent", Recordable)
+	finished := tr.StartSpan("finished", Recordable, opentracing.StartTime(time.Now().Add(-2*time.Hour)))
+	finished.Finish()
+
+	tr.collectAbandonedSpans(context.Background())
+	if len(reported) != 1 || reported[0].Operation != "old" || reported[0].Tags["k"] != "v" ||
+		reported[0].Finished {
+		t.Fatalf("unexpected abandoned spans: %+v", reported)
+	}
+	// Spans are only reported once.
+	tr.collectAbandonedSpans(context.Background())
+	if len(reported) != 1 {
+		t.Fatalf("unexpected abandoned spans: %+v", reported)
+	}
+	old.Finish()
+
+	// Abandoned spans can be finished by the GC; finishing them afterwards is a
+	// no-op.
+	defer settings.TestingSetBool(&spanGCFinishAbandoned, true)()
+	reported = nil
+	leaked := tr.StartSpan("leaked", Recordable, opentracing.StartTime(time.Now().Add(-2*time.Hour)))
+	StartRecording(leaked, SingleNodeRecording)
+	tr.collectAbandonedSpans(context.Background())
+	if len(reported) != 1 || !reported[0].Finished {
+		t.Fatalf("unexpected abandoned spans: %+v", reported)
+	}
+	rec := GetRecording(leaked)
+	if rec[0].Duration == 0 || rec[0].Tags[TagAbandoned] != "true" {
+		t.Errorf("expected finished abandoned span, got %+v", rec[0])
+	}
+	leaked.Finish()
+	if d := GetRecording(leaked)[0].Duration; d != rec[0].Duration {
+		t.Errorf("span finished twice: %s vs %s", d, rec[0].Duration)
+	}
+	recent.Finish()
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import opentracing "github.com/opentracing/opentracing-go"
+
+// privilegedBaggage are the baggage items which have effects beyond the trace
+// that carries them (e.g. Snowball turns on recording on every node the
+// operation reaches). When extracted, they are subject to the
+// BaggageAuthorizer.
+var privilegedBaggage = map[string]bool{
+	Snowball:              true,
+	ForceTraceBaggage:     true,
+	VerboseOnErrorBaggage: true,
+}
+
+// honoredWithoutAuthorizer are the privileged baggage items which are honored
+// when no BaggageAuthorizer is set. Snowball is, so that session tracing keeps
+// working across nodes running without one.
+var honoredWithoutAuthorizer = map[string]bool{
+	Snowball: true,
+}
+
+// BaggageAuthorizer is consulted by Extract for each privileged baggage item
+// (e.g. Snowball) in a carrier. Items it rejects are dropped.
+type BaggageAuthorizer func(carrier interface{}, key, value string) bool
+
+// SetBaggageAuthorizer registers the function used to authorize privileged
+// baggage items on Extract. Without one, only Snowball is honored. A nil
+// authorizer removes the previous one.
+func (t *Tracer) SetBaggageAuthorizer(fn BaggageAuthorizer) {
+	t.baggageAuthorizer.Store(baggageAuthorizerHolder{fn: fn})
+}
+
+// baggageAuthorizerHolder allows storing a nil BaggageAuthorizer in an
+// atomic.Value.
+type baggageAuthorizerHolder struct {
+	fn BaggageAuthorizer
+}
+
+func (t *Tracer) getBaggageAuthorizer() BaggageAuthorizer {
+	h, _ := t.baggageAuthorizer.Load().(baggageAuthorizerHolder)
+	return h.fn
+}
+
+// authorizeBaggage drops the privileged items that are not authorized from the
+// extracted baggage.
+func (t *Tracer) authorizeBaggage(carrier interface{}, baggage map[string]string) {
+	authorize := t.getBaggageAuthorizer()
+	for k, v := range baggage {
+		if !privilegedBaggage[k] {
+			continue
+		}
+		if authorize == nil {
+			if !honoredWithoutAuthorizer[k] {
+				delete(baggage, k)
+			}
+		} else if !authorize(carrier, k, v) {
+			delete(baggage, k)
+		}
+	}
+}
+
+// userCarrier is the carrier of a request made by an authenticated user. It
+// gives BaggageAuthorizers access to the user.
+type userCarrier struct {
+	opentracing.TextMapReader
+	user string
+}
+
+// WithCarrierUser wraps the carrier of a request made by the given
+// authenticated user (e.g. the startup parameters of a SQL connection).
+func WithCarrierUser(carrier opentracing.TextMapReader, user string) opentracing.TextMapReader {
+	return userCarrier{TextMapReader: carrier, user: user}
+}
+
+// CarrierUser returns the user that made the request the carrier comes from,
+// if the carrier was wrapped by WithCarrierUser.
+func CarrierUser(carrier interface{}) (string, bool) {
+	c, ok := carrier.(userCarrier)
+	if !ok {
+		return "", false
+	}
+	return c.user, true
+}
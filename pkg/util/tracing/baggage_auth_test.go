@@ -0,0 +1,109 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestBaggageAuthorizer(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	newCarrier := func(internal bool) opentracing.TextMapCarrier {
+		c := opentracing.TextMapCarrier{
+			fieldNameTraceID:          "1",
+			fieldNameSpanID:           "2",
+			prefixBaggage + Snowball:  "1",
+			prefixBaggage + "request": "x",
+		}
+		if internal {
+			c["internal"] = "true"
+		}
+		return c
+	}
+	extract := func(carrier opentracing.TextMapCarrier) map[string]string {
+		wireContext, err := tr.Extract(opentracing.TextMap, carrier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wireContext.(*spanContext).Baggage
+	}
+
+	// Without an authorizer, only the snowball item is honored.
+	c := newCarrier(false)
+	c[prefixBaggage+ForceTraceBaggage] = "1"
+	if b := extract(c); b[Snowball] != "1" || b[ForceTraceBaggage] != "" {
+		t.Fatalf("expected only snowball baggage, got %v", b)
+	}
+
+	var calls int
+	tr.SetBaggageAuthorizer(func(carrier interface{}, key, value string) bool {
+		calls++
+		if key != Snowball {
+			t.Errorf("authorizer called for unprivileged item %s", key)
+		}
+		return carrier.(opentracing.TextMapCarrier)["internal"] == "true"
+	})
+	if b := extract(newCarrier(true)); b[Snowball] != "1" {
+		t.Fatalf("expected snowball baggage, got %v", b)
+	}
+	b := extract(newCarrier(false))
+	if _, ok := b[Snowball]; ok {
+		t.Fatalf("expected snowball baggage to be dropped, got %v", b)
+	}
+	if b["request"] != "x" {
+		t.Fatalf("expected unprivileged baggage to be kept, got %v", b)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+
+	tr.SetBaggageAuthorizer(nil)
+	if b := extract(newCarrier(false)); b[Snowball] != "1" {
+		t.Fatalf("expected snowball baggage, got %v", b)
+	}
+}
+
+func TestBaggageAuthorizerCarrierUser(t *testing.T) {
+	defer settings.TestingSetEnum(&externalBaggage, int64(externalBaggageAccept))()
+	tr := NewTracer().(*Tracer)
+	tr.SetBaggageAuthorizer(func(carrier interface{}, key, value string) bool {
+		user, ok := CarrierUser(carrier)
+		return ok && user == "root"
+	})
+	carrier := opentracing.TextMapCarrier{
+		fieldNameTraceID:         "1",
+		fieldNameSpanID:          "2",
+		prefixBaggage + Snowball: "1",
+	}
+	for _, tc := range []struct {
+		user     string
+		expected string
+	}{
+		{"root", "1"},
+		{"someone", ""},
+	} {
+		wireContext, err := tr.ExtractExternal(opentracing.TextMap, WithCarrierUser(carrier, tc.user))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := wireContext.(*spanContext).Baggage[Snowball]; v != tc.expected {
+			t.Errorf("%s: expected snowball baggage %q, got %q", tc.user, tc.expected, v)
+		}
+	}
+}
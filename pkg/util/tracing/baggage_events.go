@@ -0,0 +1,73 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	opentracing "github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+var baggageEvents = settings.RegisterBoolSetting(
+	"trace.debug.baggage_events.enabled",
+	"if set, changes to the baggage of verbose recording spans are recorded as events, "+
+		"which helps tracking down who turned on recording (e.g. by setting the sb item)",
+	false,
+)
+
+// Keys of the fields of the events recorded for baggage changes, besides
+// "event". The FieldBaggageOld field is omitted for new items, and the
+// FieldBaggageNew field for deleted ones.
+const (
+	FieldBaggageKey = "baggage.key"
+	FieldBaggageOld = "baggage.old"
+	FieldBaggageNew = "baggage.new"
+)
+
+// baggageEventName is the "event" field of the events recorded for baggage
+// changes.
+const baggageEventName = "baggage changed"
+
+// recordBaggageChangeLocked records a change to the baggage of the span, if
+// the trace.debug.baggage_events.enabled setting is set and the span is
+// verbose. oldVal is nil if the item is new, newVal is nil if it is deleted.
+// Setting an item to its current value is not a change.
+func (s *span) recordBaggageChangeLocked(key string, oldVal, newVal *string) {
+	if !baggageEvents.Get() || !s.isVerbose() || s.cost.cutOff() {
+		return
+	}
+	if oldVal != nil && newVal != nil && *oldVal == *newVal {
+		return
+	}
+	if len(s.mu.recordedLogs) >= maxLogsPerSpan || !s.mu.recordingGroup.budget().consumeLogBudget() {
+		return
+	}
+	fields := make([]otlog.Field, 0, 4)
+	fields = append(fields, otlog.String("event", baggageEventName), otlog.String(FieldBaggageKey, key))
+	if oldVal != nil {
+		fields = append(fields, otlog.String(FieldBaggageOld, *oldVal))
+	}
+	if newVal != nil {
+		fields = append(fields, otlog.String(FieldBaggageNew, *newVal))
+	}
+	s.mu.recordedLogs = append(s.mu.recordedLogs, opentracing.LogRecord{
+		Timestamp: s.tracer.now(),
+		Fields:    fields,
+	})
+	size := fieldsSize(fields)
+	s.cost.addRecorded(size)
+	s.component.addRecorded(size)
+}
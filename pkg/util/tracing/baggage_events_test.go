@@ -0,0 +1,61 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestBaggageEvents(t *testing.T) {
+	tr := NewTracer()
+	run := func() []string {
+		sp := tr.StartSpan("root", Recordable)
+		StartRecording(sp, SnowballRecording)
+		sp.SetBaggageItem("k", "a")
+		sp.SetBaggageItem("k", "a")
+		sp.SetBaggageItem("k", "b")
+		sp.(*span).DeleteBaggageItem("k")
+		sp.Finish()
+
+		var events []string
+		for _, l := range GetRecording(sp)[0].Logs {
+			var fields []string
+			for _, f := range l.Fields {
+				fields = append(fields, f.Key+"="+f.Value)
+			}
+			events = append(events, strings.Join(fields, " "))
+		}
+		return events
+	}
+
+	if events := run(); len(events) != 0 {
+		t.Errorf("expected no events, got %v", events)
+	}
+
+	defer settings.TestingSetBool(&baggageEvents, true)()
+	expected := []string{
+		"event=baggage changed baggage.key=sb baggage.new=1",
+		"event=baggage changed baggage.key=k baggage.new=a",
+		"event=baggage changed baggage.key=k baggage.old=a baggage.new=b",
+		"event=baggage changed baggage.key=k baggage.old=b",
+	}
+	if events := run(); !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected %v, got %v", expected, events)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// Tags set by BulkSpan.Finish.
+const (
+	// TagBulkCount is the number of sub-operations.
+	TagBulkCount = "bulk.count"
+	// TagBulkErrors is the number of sub-operations that failed.
+	TagBulkErrors = "bulk.errors"
+	// TagBulkTotal is the total duration of the sub-operations.
+	TagBulkTotal = "bulk.total"
+	// TagBulkMin and TagBulkMax are the shortest and longest durations of the
+	// sub-operations.
+	TagBulkMin = "bulk.min"
+	TagBulkMax = "bulk.max"
+	// TagBulkHistogram is a histogram of the durations of the sub-operations,
+	// in power-of-two buckets starting at 1µs; only non-empty buckets are
+	// listed, e.g. "<2µs:10 <4µs:3 <16µs:1".
+	TagBulkHistogram = "bulk.histogram"
+)
+
+// BulkSpan represents N homogeneous sub-operations (e.g. the items of a bulk
+// ingestion or of a scan) as a single span, which carries their count and a
+// histogram of their durations instead of having one child span per item,
+// which would be prohibitive. It is safe for concurrent use.
+type BulkSpan struct {
+	sp opentracing.Span
+	// active is false if the span is a noop span, in which case the
+	// measurements are not accumulated.
+	active bool
+
+	mu struct {
+		syncutil.Mutex
+		count, errors int64
+		total         time.Duration
+		min, max      time.Duration
+		buckets       [numExemplarBuckets]int64
+	}
+}
+
+// StartBulkSpan starts a BulkSpan as a child of the span in ctx (if any). The
+// returned context contains the span, so that the events of the
+// sub-operations end up in it. The BulkSpan must be finished with Finish.
+func StartBulkSpan(ctx context.Context, opName string) (context.Context, *BulkSpan) {
+	ctx, sp := ChildSpan(ctx, opName)
+	return ctx, &BulkSpan{sp: sp, active: sp != nil && !IsBlackHoleSpan(sp)}
+}
+
+// Span returns the underlying span, or nil if ctx had no span.
+func (b *BulkSpan) Span() opentracing.Span {
+	return b.sp
+}
+
+// Record accounts for a sub-operation with the given duration and error.
+func (b *BulkSpan) Record(d time.Duration, err error) {
+	if !b.active {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.mu.count == 0 || d < b.mu.min {
+		b.mu.min = d
+	}
+	if d > b.mu.max {
+		b.mu.max = d
+	}
+	b.mu.count++
+	b.mu.total += d
+	if err != nil {
+		b.mu.errors++
+	}
+	b.mu.buckets[exemplarBucketIdx(d)]++
+}
+
+// Finish sets the tags summarizing the sub-operations (see TagBulkCount etc.)
+// and finishes the span.
+func (b *BulkSpan) Finish() {
+	if b.sp == nil {
+		return
+	}
+	if b.active {
+		b.mu.Lock()
+		b.sp.SetTag(TagBulkCount, b.mu.count)
+		if b.mu.errors > 0 {
+			b.sp.SetTag(TagBulkErrors, b.mu.errors)
+		}
+		if b.mu.count > 0 {
+			b.sp.SetTag(TagBulkTotal, b.mu.total.String())
+			b.sp.SetTag(TagBulkMin, b.mu.min.String())
+			b.sp.SetTag(TagBulkMax, b.mu.max.String())
+			b.sp.SetTag(TagBulkHistogram, formatBulkHistogram(&b.mu.buckets))
+		}
+		b.mu.Unlock()
+	}
+	b.sp.Finish()
+}
+
+// formatBulkHistogram formats the non-empty buckets of a histogram; see
+// TagBulkHistogram.
+func formatBulkHistogram(buckets *[numExemplarBuckets]int64) string {
+	var buf bytes.Buffer
+	for i, n := range buckets {
+		if n == 0 {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		if i == numExemplarBuckets-1 {
+			fmt.Fprintf(&buf, ">=%s:%d", time.Duration(minTrackedLatency<<uint(i)), n)
+		} else {
+			fmt.Fprintf(&buf, "<%s:%d", time.Duration(minTrackedLatency<<uint(i+1)), n)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+func TestBulkSpan(t *testing.T) {
+	// Without a span, the measurements are ignored.
+	_, b := StartBulkSpan(context.Background(), "ingest")
+	b.Record(time.Second, nil)
+	b.Finish()
+
+	tr := NewTracer()
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SingleNodeRecording)
+	ctx := opentracing.ContextWithSpan(context.Background(), root)
+
+	_, b = StartBulkSpan(ctx, "ingest")
+	for _, d := range []time.Duration{time.Microsecond, time.Microsecond, 3 * time.Microsecond} {
+		b.Record(d, nil)
+	}
+	b.Record(20*time.Microsecond, errors.New("boom"))
+	b.Finish()
+	root.Finish()
+
+	if err := TestingCheckRecordedSpans(GetRecording(root), `
+		span root:
+		span ingest:
+			tags: bulk.count=4 bulk.errors=1 bulk.histogram=<2µs:2 <4µs:1 <32µs:1 bulk.max=20µs bulk.min=1µs bulk.total=25µs
+	`); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// RecordingBundle gathers the trace artifacts of an operation, along with the
+// context needed to interpret them, for inclusion in diagnostics bundles (e.g.
+// for statement diagnostics). It is built by BuildBundle.
+type RecordingBundle struct {
+	// Recording is the recording of the span.
+	Recording Recording
+	// Config maps the names of the tracing cluster settings (trace.*) to their
+	// values at the time the bundle was built.
+	Config map[string]string
+	// SamplingReason is the reason why the trace was sent to the shadow
+	// tracer (see TagSamplingReason), or empty if it wasn't.
+	SamplingReason string
+	// Forced is set if the trace was forced (see ForceTraceBaggage).
+	Forced bool
+	// SamplingStats are the sampling decisions made by the tracer so far.
+	SamplingStats SamplingStats
+	// Environment holds the tags describing the node on which the bundle was
+	// built: the tracer's global tags (see TracerOptions) and the node ID.
+	Environment map[string]string
+}
+
+// BuildBundle builds the RecordingBundle of a span, which must be recording.
+// It is the supported way for other packages to assemble trace artifacts;
+// the span is not modified.
+func BuildBundle(os opentracing.Span) (RecordingBundle, error) {
+	s, ok := os.(*span)
+	if !ok || !s.isRecording() {
+		return RecordingBundle{}, errors.Errorf("span is not recording")
+	}
+	b := RecordingBundle{
+		Recording:     GetRecording(s),
+		Config:        make(map[string]string),
+		SamplingStats: s.tracer.SamplingStats(),
+		Environment:   make(map[string]string),
+	}
+	for _, k := range settings.Keys() {
+		if !strings.HasPrefix(k, "trace.") {
+			continue
+		}
+		if v, ok := settings.Lookup(k); ok {
+			b.Config[k] = v.String()
+		}
+	}
+	s.mu.Lock()
+	if t, ok := s.mu.tags[TagSamplingReason]; ok {
+		b.SamplingReason = fmt.Sprint(t.value)
+	}
+	b.Forced = s.mu.Baggage[ForceTraceBaggage] != ""
+	s.mu.Unlock()
+	for k, v := range s.tracer.globalTags {
+		b.Environment[k] = fmt.Sprint(v)
+	}
+	if nodeID := atomic.LoadInt32(&s.tracer.nodeID); nodeID != 0 {
+		b.Environment["node"] = strconv.Itoa(int(nodeID))
+	}
+	return b, nil
+}
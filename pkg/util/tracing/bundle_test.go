@@ -0,0 +1,53 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestBuildBundle(t *testing.T) {
+	tr := NewTracerWithOptions(TracerOptions{
+		GlobalTags: opentracing.Tags{"cluster": "c1"},
+	}).(*Tracer)
+	tr.SetNodeID(3)
+
+	if _, err := BuildBundle(tr.StartSpan("noop")); err == nil {
+		t.Error("expected an error for a span that is not recording")
+	}
+
+	sp := tr.StartSpan("stmt", ForceTrace)
+	StartChildSpan("child", sp, false /* separateRecording */).Finish()
+	sp.Finish()
+
+	b, err := BuildBundle(sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Recording) != 2 || b.Recording[0].Operation != "stmt" {
+		t.Errorf("unexpected recording %v", b.Recording)
+	}
+	if !b.Forced {
+		t.Error("expected the trace to be forced")
+	}
+	if _, ok := b.Config["trace.sample_rate"]; !ok {
+		t.Errorf("expected the tracing settings in the config, got %v", b.Config)
+	}
+	if b.Environment["cluster"] != "c1" || b.Environment["node"] != "3" {
+		t.Errorf("unexpected environment %v", b.Environment)
+	}
+}
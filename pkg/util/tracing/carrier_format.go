@@ -0,0 +1,101 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"strconv"
+	"strings"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// carrierFormat is the format in which Inject writes the trace and span IDs.
+// Extract accepts all the formats regardless of the setting, so the format can
+// be changed once all the nodes of a cluster understand the new format.
+type carrierFormat int64
+
+const (
+	// carrierFormatLegacy writes the IDs under the crdb-tracer-traceid and
+	// crdb-tracer-spanid keys, which all versions understand.
+	carrierFormatLegacy carrierFormat = iota
+	// carrierFormatBoth writes the IDs in both the legacy and the traceparent
+	// formats, for mixed-version clusters and for interoperating with external
+	// systems.
+	carrierFormatBoth
+	// carrierFormatTraceparent writes the IDs in a W3C Trace Context
+	// traceparent field only.
+	carrierFormatTraceparent
+)
+
+var carrierFormatSetting = settings.RegisterEnumSetting(
+	"trace.carrier.format",
+	"format of the trace and span IDs in span contexts sent to other nodes; "+
+		"all formats are accepted, but older versions only understand legacy",
+	"legacy",
+	map[int64]string{
+		int64(carrierFormatLegacy):      "legacy",
+		int64(carrierFormatBoth):        "both",
+		int64(carrierFormatTraceparent): "traceparent",
+	},
+)
+
+// fieldNameTraceparent is the carrier field holding the IDs in the W3C Trace
+// Context format: "00-<32 hex digit trace ID>-<16 hex digit span ID>-<flags>".
+const fieldNameTraceparent = "traceparent"
+
+// traceparentSampled is the "sampled" flag of the traceparent field.
+const traceparentSampled = "01"
+
+// injectSpanMeta writes the trace and span IDs to a carrier, in the format
+// selected by trace.carrier.format.
+func injectSpanMeta(m spanMeta, w opentracing.TextMapWriter) {
+	format := carrierFormat(carrierFormatSetting.Get())
+	if format != carrierFormatTraceparent {
+		w.Set(fieldNameTraceID, strconv.FormatUint(m.TraceID, 16))
+		w.Set(fieldNameSpanID, strconv.FormatUint(m.SpanID, 16))
+	}
+	if format != carrierFormatLegacy {
+		w.Set(fieldNameTraceparent, formatTraceparent(m))
+	}
+}
+
+// formatTraceparent returns the value of the fieldNameTraceparent field.
+func formatTraceparent(m spanMeta) string {
+	return "00-" + TraceID128String(m.TraceID) + "-" + FormatSpanID(m.SpanID) + "-" + traceparentSampled
+}
+
+// parseTraceparent parses the value of the fieldNameTraceparent field. The
+// trace ID is truncated to its low 64 bits; see ParseTraceID.
+func parseTraceparent(v string) (spanMeta, error) {
+	parts := strings.Split(v, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return spanMeta{}, errors.Errorf("invalid traceparent %q", v)
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return spanMeta{}, errors.Errorf("invalid traceparent %q", v)
+	}
+	traceID, err := ParseTraceID(parts[1])
+	if err != nil {
+		return spanMeta{}, err
+	}
+	spanID, err := strconv.ParseUint(parts[2], 16, 64)
+	if err != nil {
+		return spanMeta{}, errors.Errorf("invalid traceparent %q", v)
+	}
+	return spanMeta{TraceID: traceID, SpanID: spanID}, nil
+}
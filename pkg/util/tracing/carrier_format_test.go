@@ -0,0 +1,139 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestCarrierFormats(t *testing.T) {
+	tr := NewTracer()
+	tr2 := NewTracer()
+
+	sp := tr.StartSpan("a", Recordable)
+	defer sp.Finish()
+	StartRecording(sp, SnowballRecording)
+	meta := sp.(*span).spanMeta
+
+	for _, tc := range []struct {
+		format      carrierFormat
+		legacy      bool
+		traceparent bool
+	}{
+		{carrierFormatLegacy, true, false},
+		{carrierFormatBoth, true, true},
+		{carrierFormatTraceparent, false, true},
+	} {
+		t.Run(fmt.Sprint(tc.format), func(t *testing.T) {
+			defer settings.TestingSetEnum(&carrierFormatSetting, int64(tc.format))()
+			carrier := make(opentracing.TextMapCarrier)
+			if err := tr.Inject(sp.Context(), opentracing.TextMap, carrier); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := carrier[fieldNameTraceID]; ok != tc.legacy {
+				t.Errorf("expected legacy fields: %t, got %v", tc.legacy, carrier)
+			}
+			if _, ok := carrier[fieldNameTraceparent]; ok != tc.traceparent {
+				t.Errorf("expected traceparent field: %t, got %v", tc.traceparent, carrier)
+			}
+
+			// Regardless of the format, the context is extracted with its baggage.
+			wireContext, err := tr2.Extract(opentracing.TextMap, carrier)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sc := wireContext.(*spanContext)
+			if sc.spanMeta != meta {
+				t.Errorf("expected %s, got %s", meta, sc.spanMeta)
+			}
+			if sc.Baggage[Snowball] == "" {
+				t.Error("expected snowball baggage")
+			}
+		})
+	}
+
+	// Carriers written by external systems.
+	for _, tc := range []struct {
+		carrier opentracing.TextMapCarrier
+		exp     spanMeta
+		err     bool
+	}{
+		{
+			carrier: opentracing.TextMapCarrier{
+				"traceparent": "00-00000000000000000000000000000abc-0000000000000def-01",
+			},
+			exp: spanMeta{TraceID: 0xabc, SpanID: 0xdef},
+		},
+		// The legacy fields take precedence.
+		{
+			carrier: opentracing.TextMapCarrier{
+				fieldNameTraceID: "1",
+				fieldNameSpanID:  "2",
+				"traceparent":    "00-00000000000000000000000000000abc-0000000000000def-01",
+			},
+			exp: spanMeta{TraceID: 1, SpanID: 2},
+		},
+		// Future versions can have more fields.
+		{
+			carrier: opentracing.TextMapCarrier{
+				"traceparent": "01-00000000000000000000000000000abc-0000000000000def-01-xyz",
+			},
+			exp: spanMeta{TraceID: 0xabc, SpanID: 0xdef},
+		},
+		// Trace IDs that don't fit in 64 bits are truncated.
+		{
+			carrier: opentracing.TextMapCarrier{
+				"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000def-01",
+			},
+			exp: spanMeta{TraceID: 0xa3ce929d0e0e4736, SpanID: 0xdef},
+		},
+		{
+			carrier: opentracing.TextMapCarrier{
+				fieldNameTraceID: "1",
+				fieldNameSpanID:  "2",
+				"traceparent":    "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000def-01",
+			},
+			exp: spanMeta{TraceID: 1, SpanID: 2},
+		},
+		{
+			carrier: opentracing.TextMapCarrier{"traceparent": "00-abc-def-01"},
+			err:     true,
+		},
+	} {
+		wireContext, err := tr2.Extract(opentracing.TextMap, tc.carrier)
+		if tc.err {
+			if err != opentracing.ErrSpanContextCorrupted {
+				t.Errorf("%v: expected corrupted context error, got %v", tc.carrier, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.exp == (spanMeta{}) {
+			if _, ok := wireContext.(noopSpanContext); !ok {
+				t.Errorf("%v: expected noop context, got %v", tc.carrier, wireContext)
+			}
+			continue
+		}
+		if m := wireContext.(*spanContext).spanMeta; m != tc.exp {
+			t.Errorf("%v: expected %s, got %s", tc.carrier, tc.exp, m)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// The components of the system that spans are classified into; see
+// WithComponent. Other names can be used too.
+const (
+	ComponentSQL     = "sql"
+	ComponentKV      = "kv"
+	ComponentStorage = "storage"
+	ComponentRPC     = "rpc"
+)
+
+type componentOption string
+
+func (o componentOption) apply(so *spanOptions) {
+	so.component = string(o)
+}
+
+func (o componentOption) Apply(*opentracing.StartSpanOptions) {}
+
+// WithComponent sets the span's standard "component" tag and accounts for the
+// span in the component's stats (see Tracer.ComponentStats).
+func WithComponent(component string) SpanOption {
+	return componentOption(component)
+}
+
+// Component is the equivalent of WithComponent for StartSpan.
+func Component(component string) opentracing.StartSpanOption {
+	return componentOption(component)
+}
+
+// ComponentStats are the stats of the spans of a component.
+type ComponentStats struct {
+	// Spans is the number of spans created.
+	Spans int64
+	// Duration is the total duration of the finished spans.
+	Duration time.Duration
+	// RecordedBytes estimates the size of the messages captured in the spans'
+	// recordings.
+	RecordedBytes int64
+}
+
+// componentCounters accumulates the ComponentStats of a component. The fields
+// are accessed atomically.
+type componentCounters struct {
+	spans         int64
+	duration      int64
+	recordedBytes int64
+}
+
+// componentStats tracks the stats of each component seen by a Tracer.
+type componentStats struct {
+	mu struct {
+		syncutil.RWMutex
+		components map[string]*componentCounters
+	}
+}
+
+// get returns the counters of a component, creating them if needed. Returns
+// nil for the empty component.
+func (cs *componentStats) get(component string) *componentCounters {
+	if component == "" {
+		return nil
+	}
+	cs.mu.RLock()
+	c, ok := cs.mu.components[component]
+	cs.mu.RUnlock()
+	if ok {
+		return c
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if c, ok := cs.mu.components[component]; ok {
+		return c
+	}
+	if cs.mu.components == nil {
+		cs.mu.components = make(map[string]*componentCounters)
+	}
+	c = new(componentCounters)
+	cs.mu.components[component] = c
+	return c
+}
+
+// ComponentStats returns the stats of the spans of each component seen so
+// far; see WithComponent.
+func (t *Tracer) ComponentStats() map[string]ComponentStats {
+	cs := &t.componentStats
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	res := make(map[string]ComponentStats, len(cs.mu.components))
+	for name, c := range cs.mu.components {
+		res[name] = ComponentStats{
+			Spans:         atomic.LoadInt64(&c.spans),
+			Duration:      time.Duration(atomic.LoadInt64(&c.duration)),
+			RecordedBytes: atomic.LoadInt64(&c.recordedBytes),
+		}
+	}
+	return res
+}
+
+// The methods below can be called on a nil receiver (for spans without a
+// component).
+
+func (c *componentCounters) addSpan() {
+	if c != nil {
+		atomic.AddInt64(&c.spans, 1)
+	}
+}
+
+func (c *componentCounters) addDuration(d time.Duration) {
+	if c != nil {
+		atomic.AddInt64(&c.duration, int64(d))
+	}
+}
+
+// addComponentDuration adds the span's duration to the stats of its
+// component. Called when the span finishes.
+func (s *span) addComponentDuration() {
+	if s.component != nil {
+		s.component.addDuration(s.duration())
+	}
+}
+
+func (c *componentCounters) addRecorded(n int64) {
+	if c != nil {
+		atomic.AddInt64(&c.recordedBytes, n)
+	}
+}
+
+// setComponent classifies a new span.
+func (s *span) setComponent(component string) {
+	if component == "" {
+		return
+	}
+	s.component = s.tracer.componentStats.get(component)
+	s.component.addSpan()
+	s.SetTag(string(otext.Component), component)
+}
+
+var componentSampleRates = settings.RegisterValidatedStringSetting(
+	"trace.sample_rate.by_component",
+	"comma-separated sampling rates overriding trace.sample_rate for the traces "+
+		"whose root span belongs to a component, in the form <component>=<rate> "+
+		"(e.g. 'storage=0.1')",
+	"",
+	func(v string) error {
+		_, err := parseComponentSampleRates(v)
+		return err
+	},
+)
+
+func parseComponentSampleRates(v string) (map[string]float64, error) {
+	var rates map[string]float64
+	for _, rule := range strings.Split(v, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		i := strings.Index(rule, "=")
+		if i <= 0 {
+			return nil, errors.Errorf("invalid component sampling rate %q", rule)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rule[i+1:]), 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, errors.Errorf("invalid component sampling rate %q", rule)
+		}
+		if rates == nil {
+			rates = make(map[string]float64)
+		}
+		rates[strings.TrimSpace(rule[:i])] = rate
+	}
+	return rates, nil
+}
+
+// parsedComponentSampleRates caches the parsed value of the setting.
+type parsedComponentSampleRates struct {
+	raw   string
+	rates map[string]float64
+}
+
+var componentSampleRatesCache atomic.Value
+
+// componentSampleRate returns the sampling rate for the traces of a component,
+// or the given base rate if the component has no specific rate.
+func componentSampleRate(component string, base float64) float64 {
+	raw := componentSampleRates.Get()
+	if raw == "" || component == "" {
+		return base
+	}
+	p, ok := componentSampleRatesCache.Load().(parsedComponentSampleRates)
+	if !ok || p.raw != raw {
+		rates, _ := parseComponentSampleRates(raw)
+		p = parsedComponentSampleRates{raw: raw, rates: rates}
+		componentSampleRatesCache.Store(p)
+	}
+	if rate, ok := p.rates[component]; ok {
+		return rate
+	}
+	return base
+}
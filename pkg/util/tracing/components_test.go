@@ -0,0 +1,77 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	lightstep "github.com/lightstep/lightstep-tracer-go"
+	opentracing "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestComponentStats(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+
+	sp := tr.Start("query", WithComponent(ComponentSQL), WithForceReal())
+	StartRecording(sp, SingleNodeRecording)
+	sp.LogKV("event", "x")
+	child := tr.Start("scan", WithComponent(ComponentKV), WithParent(sp))
+	child.FinishWithOptions(opentracing.FinishOptions{FinishTime: time.Now().Add(time.Second)})
+	sp.Finish()
+	tr.StartSpan("unclassified", Recordable).Finish()
+
+	if v := GetSpanTags(sp)[string(otext.Component)]; v != ComponentSQL {
+		t.Errorf("expected the component tag, got %v", v)
+	}
+	stats := tr.ComponentStats()
+	if len(stats) != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if s := stats[ComponentSQL]; s.Spans != 1 || s.RecordedBytes == 0 {
+		t.Errorf("unexpected sql stats %+v", s)
+	}
+	if s := stats[ComponentKV]; s.Spans != 1 || s.Duration < time.Second || s.RecordedBytes != 0 {
+		t.Errorf("unexpected kv stats %+v", s)
+	}
+}
+
+func TestComponentSampleRates(t *testing.T) {
+	for _, v := range []string{"", "storage=0.1", " sql = 1 , kv=0"} {
+		if _, err := parseComponentSampleRates(v); err != nil {
+			t.Errorf("%q: unexpected error %v", v, err)
+		}
+	}
+	for _, v := range []string{"storage", "=0.1", "storage=x", "storage=2"} {
+		if _, err := parseComponentSampleRates(v); err == nil {
+			t.Errorf("%q: expected error", v)
+		}
+	}
+
+	defer settings.TestingSetString(&componentSampleRates, "storage=0")()
+	tr := NewTracer().(*Tracer)
+	tr.setShadowTracer(lightStepManager{}, lightstep.NewTracer(lightstep.Options{}))
+	defer tr.Close()
+	for i := 0; i < 10; i++ {
+		tr.StartSpan("a", Component(ComponentStorage)).Finish()
+		tr.StartSpan("b", Component(ComponentSQL)).Finish()
+	}
+	if s := tr.SamplingStats(); s.Kept != 10 || s.Dropped != 10 {
+		t.Errorf("unexpected sampling stats %+v", s)
+	}
+}
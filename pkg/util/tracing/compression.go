@@ -0,0 +1,215 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+)
+
+// RecordingCompression is a compression algorithm for serialized recordings.
+type RecordingCompression byte
+
+const (
+	// RecordingCompressionNone stores the recording uncompressed.
+	RecordingCompressionNone RecordingCompression = iota
+	// RecordingCompressionSnappy compresses the recording with snappy (framing
+	// format). It is fast and is a good choice for recordings that are
+	// transmitted between nodes.
+	RecordingCompressionSnappy
+	// RecordingCompressionGzip compresses the recording with gzip. It is slower
+	// than snappy but compresses better, which makes it a good choice for
+	// recordings that are stored.
+	RecordingCompressionGzip
+)
+
+// recordingMagic starts every serialized recording produced by
+// MarshalRecording; it is followed by a version byte, the compression byte and
+// (since version 2) a byte with the RecordingSchemaVersion of the spans.
+const recordingMagic = "CRDBREC"
+
+const recordingFormatVersion = 2
+
+// recordingFormatV1Schema is the schema of recordings serialized with version 1
+// of the format, which didn't record it.
+const recordingFormatV1Schema = RecordingSchemaV2
+
+// MarshalRecording serializes a recording, compressed with the given algorithm.
+// The result can be read back with UnmarshalRecording or NewRecordingReader.
+func MarshalRecording(rec Recording, compression RecordingCompression) ([]byte, error) {
+	return MarshalRecordingForSchema(rec, compression, CurrentRecordingSchema)
+}
+
+// MarshalRecordingForSchema is like MarshalRecording, but downgrades the
+// recording to the given schema (see DowngradeRecording) so that it can be
+// read by nodes running older versions without losing information.
+func MarshalRecordingForSchema(
+	rec Recording, compression RecordingCompression, schema RecordingSchemaVersion,
+) ([]byte, error) {
+	rec = DowngradeRecording(rec, schema)
+	var buf bytes.Buffer
+	w, err := newRecordingWriter(&buf, compression, schema)
+	if err != nil {
+		return nil, err
+	}
+	var lenBuf [binary.MaxVarintLen64]byte
+	for i := range rec {
+		data, err := rec[i].Marshal()
+		if err != nil {
+			return nil, err
+		}
+		n := binary.PutUvarint(lenBuf[:], uint64(len(data)))
+		if _, err := w.Write(lenBuf[:n]); err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalRecording is the inverse of MarshalRecording.
+func UnmarshalRecording(data []byte) (Recording, error) {
+	r, err := NewRecordingReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var rec Recording
+	for {
+		sp, err := r.Next()
+		if err == io.EOF {
+			return rec, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rec = append(rec, sp)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// newRecordingWriter writes the header and returns a writer for the
+// (compressed) spans. The writer must be closed.
+func newRecordingWriter(
+	w io.Writer, compression RecordingCompression, schema RecordingSchemaVersion,
+) (io.WriteCloser, error) {
+	header := append([]byte(recordingMagic), recordingFormatVersion, byte(compression))
+	header = append(header, byte(schema))
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	switch compression {
+	case RecordingCompressionNone:
+		return nopWriteCloser{w}, nil
+	case RecordingCompressionSnappy:
+		return snappy.NewBufferedWriter(w), nil
+	case RecordingCompressionGzip:
+		return gzip.NewWriter(w), nil
+	default:
+		return nil, errors.Errorf("unknown recording compression %d", compression)
+	}
+}
+
+// RecordingReader reads the spans of a recording serialized by
+// MarshalRecording one at a time, decompressing them as it goes, so that large
+// recordings can be processed without materializing them in memory.
+type RecordingReader struct {
+	r      *bufio.Reader
+	schema RecordingSchemaVersion
+}
+
+// NewRecordingReader reads the header of a serialized recording and returns a
+// RecordingReader for its spans.
+func NewRecordingReader(r io.Reader) (*RecordingReader, error) {
+	header := make([]byte, len(recordingMagic)+2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errors.Wrap(err, "reading recording header")
+	}
+	if string(header[:len(recordingMagic)]) != recordingMagic {
+		return nil, errors.New("not a serialized recording")
+	}
+	schema := recordingFormatV1Schema
+	switch v := header[len(recordingMagic)]; v {
+	case 1:
+	case recordingFormatVersion:
+		var b [1]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, errors.Wrap(err, "reading recording header")
+		}
+		schema = RecordingSchemaVersion(b[0])
+	default:
+		return nil, errors.Errorf("unsupported recording format version %d", v)
+	}
+	var src io.Reader
+	switch c := RecordingCompression(header[len(recordingMagic)+1]); c {
+	case RecordingCompressionNone:
+		src = r
+	case RecordingCompressionSnappy:
+		src = snappy.NewReader(r)
+	case RecordingCompressionGzip:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		src = gz
+	default:
+		return nil, errors.Errorf("unknown recording compression %d", c)
+	}
+	return &RecordingReader{r: bufio.NewReader(src), schema: schema}, nil
+}
+
+// Schema returns the schema of the spans in the recording. Spans of schemas
+// newer than CurrentRecordingSchema can still be read, but the fields that
+// this version doesn't know about are lost.
+func (rr *RecordingReader) Schema() RecordingSchemaVersion {
+	return rr.schema
+}
+
+// Next returns the next span in the recording, or io.EOF if there are no more
+// spans. Spans of older schemas are upgraded (see UpgradeRecording).
+func (rr *RecordingReader) Next() (RecordedSpan, error) {
+	l, err := binary.ReadUvarint(rr.r)
+	if err != nil {
+		if err == io.EOF {
+			return RecordedSpan{}, io.EOF
+		}
+		return RecordedSpan{}, errors.Wrap(err, "reading recording")
+	}
+	data := make([]byte, l)
+	if _, err := io.ReadFull(rr.r, data); err != nil {
+		return RecordedSpan{}, errors.Wrap(err, "reading recording")
+	}
+	var sp RecordedSpan
+	if err := sp.Unmarshal(data); err != nil {
+		return RecordedSpan{}, err
+	}
+	upgradeSpan(&sp)
+	return sp, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+)
+
+// ContentionEvent describes a wait for a lock (e.g. an intent) held by another
+// transaction.
+type ContentionEvent struct {
+	// Key is the key on which the lock is held.
+	Key fmt.Stringer
+	// WaitingTxn is the ID of the transaction that waited; nil for
+	// non-transactional requests.
+	WaitingTxn fmt.Stringer
+	// HolderTxn is the ID of the transaction holding the lock.
+	HolderTxn fmt.Stringer
+	// Duration is the time spent waiting.
+	Duration time.Duration
+}
+
+// TagContentionTime is the total time spent waiting for locks by a span, as
+// recorded by RecordContention.
+const TagContentionTime = "contention_time"
+
+// RecordContention records a contention event in the span, as a structured
+// "contention" event. It also updates the TagContentionTime tag.
+func RecordContention(os opentracing.Span, ev ContentionEvent) {
+	if os == nil {
+		return
+	}
+	if _, noop := os.(*noopSpan); noop {
+		return
+	}
+	fields := []otlog.Field{
+		otlog.String("event", "contention"),
+		otlog.Object("key", ev.Key),
+	}
+	if ev.WaitingTxn != nil {
+		fields = append(fields, otlog.Object("waiting_txn", ev.WaitingTxn))
+	}
+	fields = append(fields,
+		otlog.Object("holder_txn", ev.HolderTxn),
+		otlog.String("wait", ev.Duration.String()),
+	)
+	os.LogFields(fields...)
+
+	s, ok := os.(*span)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	s.mu.contentionTime += ev.Duration
+	contentionTime := s.mu.contentionTime
+	s.mu.Unlock()
+	s.SetTag(TagContentionTime, contentionTime.String())
+}
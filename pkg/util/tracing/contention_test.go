@@ -0,0 +1,51 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+)
+
+type testStringer string
+
+func (s testStringer) String() string { return string(s) }
+
+func TestRecordContention(t *testing.T) {
+	tr := NewTracer()
+	sp := tr.StartSpan("a", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	RecordContention(sp, ContentionEvent{
+		Key:        testStringer("/Table/51/1/1"),
+		WaitingTxn: testStringer("a1"),
+		HolderTxn:  testStringer("b2"),
+		Duration:   time.Second,
+	})
+	RecordContention(sp, ContentionEvent{
+		Key:       testStringer("/Table/51/1/2"),
+		HolderTxn: testStringer("c3"),
+		Duration:  500 * time.Millisecond,
+	})
+	sp.Finish()
+
+	if err := TestingCheckRecordedSpans(GetRecording(sp), `
+		span a:
+			tags: contention_time=1.5s
+			event: contention  key: /Table/51/1/1  waiting_txn: a1  holder_txn: b2  wait: 1s
+			event: contention  key: /Table/51/1/2  holder_txn: c3  wait: 500ms
+	`); err != nil {
+		t.Fatal(err)
+	}
+}
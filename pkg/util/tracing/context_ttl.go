@@ -0,0 +1,49 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// contextTTL is the age past which extracted span contexts are ignored. It
+// should be well above the maximum clock offset.
+var contextTTL = settings.RegisterDurationSetting(
+	"trace.context_ttl",
+	"if nonzero, span contexts are stamped on injection and contexts older than this are ignored on extraction",
+	0,
+)
+
+// fieldNameInjectTime is the carrier field holding the time (in nanoseconds
+// since the Unix epoch) at which the context was injected.
+const fieldNameInjectTime = prefixTracerState + "injected"
+
+// formatInjectTime returns the value of the fieldNameInjectTime field.
+func formatInjectTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 16)
+}
+
+// isStaleInjectTime returns true if the given fieldNameInjectTime value is
+// older than the TTL. A zero TTL disables the check.
+func isStaleInjectTime(v string, ttl time.Duration, now time.Time) (bool, error) {
+	nanos, err := strconv.ParseInt(v, 16, 64)
+	if err != nil {
+		return false, err
+	}
+	return ttl > 0 && now.Sub(time.Unix(0, nanos)) > ttl, nil
+}
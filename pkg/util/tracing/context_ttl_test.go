@@ -0,0 +1,66 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestContextTTL(t *testing.T) {
+	tr := NewTracer()
+	sp := tr.StartSpan("a", Recordable)
+	defer sp.Finish()
+
+	// Without a TTL, contexts are not stamped.
+	carrier := make(opentracing.TextMapCarrier)
+	if err := tr.Inject(sp.Context(), opentracing.TextMap, carrier); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := carrier[fieldNameInjectTime]; ok {
+		t.Fatalf("unexpected injection time: %v", carrier)
+	}
+
+	defer settings.TestingSetDuration(&contextTTL, time.Minute)()
+
+	carrier = make(opentracing.TextMapCarrier)
+	if err := tr.Inject(sp.Context(), opentracing.TextMap, carrier); err != nil {
+		t.Fatal(err)
+	}
+	wireContext, err := tr.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, noop := wireContext.(noopSpanContext); noop {
+		t.Fatal("fresh context was dropped")
+	}
+
+	carrier[fieldNameInjectTime] = formatInjectTime(time.Now().Add(-time.Hour))
+	wireContext, err = tr.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, noop := wireContext.(noopSpanContext); !noop {
+		t.Fatalf("expected stale context to be dropped, got %+v", wireContext)
+	}
+
+	carrier[fieldNameInjectTime] = "xyz"
+	if _, err := tr.Extract(opentracing.TextMap, carrier); err != opentracing.ErrSpanContextCorrupted {
+		t.Fatalf("expected corrupted context error, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import opentracing "github.com/opentracing/opentracing-go"
+
+// TagCorrelationID is the tag holding the correlation ID of a trace (see
+// WithCorrelationID). It is indexed by default in the buffer of recent
+// traces, so the traces of an operation can be found with
+// Tracer.RecentTraces(TagCorrelationID, id).
+const TagCorrelationID = "correlation_id"
+
+// CorrelationIDBaggage is the baggage item carrying the correlation ID to the
+// descendants of the span started with WithCorrelationID, from which it is
+// passed on to the traces they start (e.g. with WithDetachedTrace).
+const CorrelationIDBaggage = "correlation-id"
+
+type correlationIDOption string
+
+// WithCorrelationID returns a StartSpanOption which tags the new span, and the
+// root spans of the traces started from its descendants, with the given
+// TagCorrelationID, to group the traces of a long-lived operation.
+func WithCorrelationID(id string) opentracing.StartSpanOption {
+	return correlationIDOption(id)
+}
+
+func (correlationIDOption) Apply(*opentracing.StartSpanOptions) {}
+
+// SetCorrelationID is like WithCorrelationID, for a span that has already
+// been started; only the descendants started after the call are affected.
+func SetCorrelationID(os opentracing.Span, id string) {
+	if _, noop := os.(*noopSpan); noop {
+		return
+	}
+	os.SetTag(TagCorrelationID, id)
+	os.SetBaggageItem(CorrelationIDBaggage, id)
+}
+
+// correlationIDFor returns the correlation ID of a new span: the one given
+// through WithCorrelationID, if any, or the one inherited from the parent.
+func correlationIDFor(so *spanOptions) string {
+	if so.correlationID != "" || so.parent == nil {
+		return so.correlationID
+	}
+	return so.parent.Baggage[CorrelationIDBaggage]
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestCorrelationID(t *testing.T) {
+	defer settings.TestingSetString(&recordingRoutesSetting, "*:"+RecordingSinkRecent)()
+	tr := NewTracer().(*Tracer)
+
+	txn := tr.StartSpan("txn", Recordable, WithCorrelationID("txn1"))
+	stmt := tr.StartSpan("stmt", opentracing.ChildOf(txn.Context()), Recordable)
+	bg := tr.StartSpan("bg", opentracing.FollowsFrom(stmt.Context()), WithDetachedTrace(), Recordable)
+	unrelated := tr.StartSpan("unrelated", Recordable)
+
+	for _, tc := range []struct {
+		sp  opentracing.Span
+		exp interface{}
+	}{
+		{txn, "txn1"},
+		{stmt, nil},
+		{bg, "txn1"},
+		{unrelated, nil},
+	} {
+		if v := GetSpanTags(tc.sp)[TagCorrelationID]; v != tc.exp {
+			t.Errorf("%s: expected correlation ID %v, got %v", tc.sp.(*span).operation, tc.exp, v)
+		}
+	}
+
+	for _, sp := range []opentracing.Span{unrelated, bg, stmt, txn} {
+		sp.Finish()
+	}
+	// The traces of the txn can be grouped.
+	recs := tr.RecentTraces(TagCorrelationID, "txn1")
+	if len(recs) != 2 || recs[0][0].Operation != "txn" || recs[1][0].Operation != "bg" {
+		t.Errorf("unexpected recordings %v", recs)
+	}
+}
+
+func TestSetCorrelationID(t *testing.T) {
+	tr := NewTracer()
+
+	txn := tr.StartSpan("txn", Recordable)
+	SetCorrelationID(txn, "txn1")
+	bg := tr.StartSpan("bg", opentracing.FollowsFrom(txn.Context()), WithDetachedTrace(), Recordable)
+	for _, sp := range []opentracing.Span{txn, bg} {
+		if v := GetSpanTags(sp)[TagCorrelationID]; v != "txn1" {
+			t.Errorf("%s: expected correlation ID txn1, got %v", sp.(*span).operation, v)
+		}
+		sp.Finish()
+	}
+
+	// Noop spans are left alone.
+	SetCorrelationID(tr.StartSpan("noop"), "txn2")
+}
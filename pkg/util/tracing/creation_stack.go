@@ -0,0 +1,73 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"runtime"
+)
+
+// TagCreationStack is the tag set on spans with the call stack at the point
+// where they were created, when the Tracer was created with the CreationStacks
+// option.
+const TagCreationStack = "creation_stack"
+
+// maxCreationStackDepth is the maximum number of frames captured in a span's
+// creation stack.
+const maxCreationStackDepth = 16
+
+// creationStack is the value of the TagCreationStack tag (and of the stacks
+// captured by RecordError). Only the program counters are captured when the
+// span is created; they are symbolized when the tag is formatted, which
+// generally only happens for spans that are looked at.
+type creationStack []uintptr
+
+var _ fmt.Stringer = creationStack(nil)
+
+// String formats the stack as a list of "function file:line" entries,
+// innermost first, separated by semicolons.
+func (cs creationStack) String() string {
+	var buf bytes.Buffer
+	frames := runtime.CallersFrames(cs)
+	for {
+		f, more := frames.Next()
+		if buf.Len() > 0 {
+			buf.WriteString("; ")
+		}
+		fmt.Fprintf(&buf, "%s %s:%d", f.Function, filepath.Base(f.File), f.Line)
+		if !more {
+			break
+		}
+	}
+	return buf.String()
+}
+
+// maybeSetCreationStack tags the span with the stack of the caller, if the
+// tracer is configured to do so. skip is the number of frames of the tracer
+// between the caller and this function.
+func (s *span) maybeSetCreationStack(skip int) {
+	if !s.tracer.creationStacks {
+		return
+	}
+	pcs := make([]uintptr, maxCreationStackDepth)
+	// Also skip runtime.Callers and this function.
+	n := runtime.Callers(skip+2, pcs)
+	if n == 0 {
+		return
+	}
+	s.SetTag(TagCreationStack, creationStack(pcs[:n]))
+}
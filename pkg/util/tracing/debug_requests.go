@@ -0,0 +1,314 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"golang.org/x/net/trace"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// debugLatencyBuckets are the lower bounds of the latency buckets in which
+// completed requests are grouped, like on the /debug/requests page.
+var debugLatencyBuckets = [...]time.Duration{
+	0,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+	100 * time.Second,
+}
+
+// debugRequestsPerBucket is the number of completed requests retained in each
+// latency bucket (and in the errors bucket) of each family.
+const debugRequestsPerBucket = 10
+
+// DebugEvent is an event of a DebugRequest.
+type DebugEvent struct {
+	Time time.Time
+	What string
+}
+
+// DebugRequest is a traced request, as shown on the /debug/requests page.
+type DebugRequest struct {
+	Family string
+	// Title is the operation name of the request's span.
+	Title string
+	Start time.Time
+	// Elapsed is the duration of the request, or the time since it started if
+	// it is still active.
+	Elapsed time.Duration
+	Active  bool
+	Failed  bool
+	Events  []DebugEvent
+}
+
+// DebugLatencyBucket groups the completed requests of a family whose duration
+// is at least MinLatency (and less than the MinLatency of the next bucket).
+type DebugLatencyBucket struct {
+	MinLatency time.Duration
+	// Count is the number of requests that completed in this bucket since the
+	// process started.
+	Count int64
+	// Recent contains the most recently completed requests, most recent first.
+	Recent []DebugRequest
+}
+
+// DebugFamily contains the requests of an x/net/trace family.
+type DebugFamily struct {
+	Family string
+	// Active contains the requests that are still running, oldest first.
+	Active  []DebugRequest
+	Buckets []DebugLatencyBucket
+	// Errors contains the most recently completed requests that failed, most
+	// recent first.
+	Errors []DebugRequest
+}
+
+// DebugRequests returns the data that the /debug/requests page displays for
+// the spans sent to x/net/trace (i.e. while trace.debug.enable is set, for
+// tracers using the default EventSink) as structured values. Families are
+// sorted by name.
+func DebugRequests() []DebugFamily {
+	now := time.Now()
+	debugFamilies.Lock()
+	fams := make([]*debugFamily, 0, len(debugFamilies.m))
+	for _, fam := range debugFamilies.m {
+		fams = append(fams, fam)
+	}
+	debugFamilies.Unlock()
+
+	result := make([]DebugFamily, len(fams))
+	for i, fam := range fams {
+		result[i] = fam.snapshot(now)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Family < result[j].Family
+	})
+	return result
+}
+
+// debugFamilies holds the families returned by DebugRequests. The lock is only
+// taken when a family is created or listed; requests only lock their family,
+// like in x/net/trace.
+var debugFamilies = struct {
+	syncutil.Mutex
+	m map[string]*debugFamily
+}{m: make(map[string]*debugFamily)}
+
+// getDebugFamily returns the family with the given name, creating it if
+// needed.
+func getDebugFamily(name string) *debugFamily {
+	debugFamilies.Lock()
+	defer debugFamilies.Unlock()
+	fam, ok := debugFamilies.m[name]
+	if !ok {
+		fam = &debugFamily{name: name}
+		fam.mu.active = make(map[*debugRequest]struct{})
+		debugFamilies.m[name] = fam
+	}
+	return fam
+}
+
+type debugFamily struct {
+	name string
+
+	mu struct {
+		syncutil.Mutex
+		active  map[*debugRequest]struct{}
+		buckets [len(debugLatencyBuckets)]debugRequestRing
+		errors  debugRequestRing
+	}
+}
+
+func (fam *debugFamily) snapshot(now time.Time) DebugFamily {
+	fam.mu.Lock()
+	defer fam.mu.Unlock()
+	res := DebugFamily{
+		Family:  fam.name,
+		Buckets: make([]DebugLatencyBucket, len(debugLatencyBuckets)),
+		Errors:  fam.mu.errors.snapshot(now),
+	}
+	for r := range fam.mu.active {
+		res.Active = append(res.Active, r.snapshot(now))
+	}
+	sort.Slice(res.Active, func(i, j int) bool {
+		return res.Active[i].Start.Before(res.Active[j].Start)
+	})
+	for i := range fam.mu.buckets {
+		res.Buckets[i] = DebugLatencyBucket{
+			MinLatency: debugLatencyBuckets[i],
+			Count:      fam.mu.buckets[i].count,
+			Recent:     fam.mu.buckets[i].snapshot(now),
+		}
+	}
+	return res
+}
+
+// debugRequestRing retains the most recent requests added to it.
+type debugRequestRing struct {
+	buf   [debugRequestsPerBucket]*debugRequest
+	next  int
+	count int64
+}
+
+func (b *debugRequestRing) add(r *debugRequest) {
+	b.buf[b.next] = r
+	b.next = (b.next + 1) % debugRequestsPerBucket
+	b.count++
+}
+
+// snapshot returns the retained requests, most recent first.
+func (b *debugRequestRing) snapshot(now time.Time) []DebugRequest {
+	var result []DebugRequest
+	for i := 1; i <= debugRequestsPerBucket; i++ {
+		r := b.buf[(b.next-i+debugRequestsPerBucket)%debugRequestsPerBucket]
+		if r == nil {
+			break
+		}
+		result = append(result, r.snapshot(now))
+	}
+	return result
+}
+
+// debugRequest records the events of a span for DebugRequests. It implements
+// SpanEvents.
+type debugRequest struct {
+	family *debugFamily
+	title  string
+	start  time.Time
+
+	mu struct {
+		syncutil.Mutex
+		events   []debugEvent
+		elapsed  time.Duration
+		finished bool
+		failed   bool
+	}
+}
+
+// debugEvent is an event which is formatted lazily, like in x/net/trace.
+type debugEvent struct {
+	time   time.Time
+	format string
+	args   []interface{}
+}
+
+var _ SpanEvents = &debugRequest{}
+
+func newDebugRequest(fam *debugFamily, title string) *debugRequest {
+	r := &debugRequest{family: fam, title: title, start: time.Now()}
+	fam.mu.Lock()
+	fam.mu.active[r] = struct{}{}
+	fam.mu.Unlock()
+	return r
+}
+
+// LazyPrintf is part of the SpanEvents interface.
+func (r *debugRequest) LazyPrintf(format string, a ...interface{}) {
+	r.mu.Lock()
+	if len(r.mu.events) < maxLogsPerSpan {
+		r.mu.events = append(r.mu.events, debugEvent{time: time.Now(), format: format, args: a})
+	}
+	r.mu.Unlock()
+}
+
+// SetError marks the request as failed.
+func (r *debugRequest) SetError() {
+	r.mu.Lock()
+	r.mu.failed = true
+	r.mu.Unlock()
+}
+
+// Finish is part of the SpanEvents interface.
+func (r *debugRequest) Finish() {
+	r.mu.Lock()
+	if r.mu.finished {
+		r.mu.Unlock()
+		return
+	}
+	r.mu.finished = true
+	r.mu.elapsed = time.Since(r.start)
+	elapsed, failed := r.mu.elapsed, r.mu.failed
+	r.mu.Unlock()
+
+	bucket := 0
+	for i, min := range debugLatencyBuckets {
+		if elapsed >= min {
+			bucket = i
+		}
+	}
+
+	fam := r.family
+	fam.mu.Lock()
+	defer fam.mu.Unlock()
+	delete(fam.mu.active, r)
+	fam.mu.buckets[bucket].add(r)
+	if failed {
+		fam.mu.errors.add(r)
+	}
+}
+
+func (r *debugRequest) snapshot(now time.Time) DebugRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := DebugRequest{
+		Family:  r.family.name,
+		Title:   r.title,
+		Start:   r.start,
+		Elapsed: r.mu.elapsed,
+		Active:  !r.mu.finished,
+		Failed:  r.mu.failed,
+		Events:  make([]DebugEvent, len(r.mu.events)),
+	}
+	if res.Active {
+		res.Elapsed = now.Sub(r.start)
+	}
+	for i, e := range r.mu.events {
+		res.Events[i] = DebugEvent{Time: e.time, What: fmt.Sprintf(e.format, e.args...)}
+	}
+	return res
+}
+
+// netTraceEvents sends the events of a span both to x/net/trace and to
+// DebugRequests.
+type netTraceEvents struct {
+	tr trace.Trace
+	r  *debugRequest
+}
+
+var _ SpanEvents = netTraceEvents{}
+
+// LazyPrintf is part of the SpanEvents interface.
+func (e netTraceEvents) LazyPrintf(format string, a ...interface{}) {
+	e.tr.LazyPrintf(format, a...)
+	e.r.LazyPrintf(format, a...)
+}
+
+// SetError marks the request as failed.
+func (e netTraceEvents) SetError() {
+	e.tr.SetError()
+	e.r.SetError()
+}
+
+// Finish is part of the SpanEvents interface.
+func (e netTraceEvents) Finish() {
+	e.tr.Finish()
+	e.r.Finish()
+}
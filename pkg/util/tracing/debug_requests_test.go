@@ -0,0 +1,92 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	otext "github.com/opentracing/opentracing-go/ext"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestDebugRequests(t *testing.T) {
+	defer settings.TestingSetBool(&enableNetTrace, true)()
+	tr := NewTracer()
+
+	family := func() DebugFamily {
+		for _, f := range DebugRequests() {
+			if f.Family == netTraceFamily {
+				return f
+			}
+		}
+		t.Fatalf("family %s not found", netTraceFamily)
+		return DebugFamily{}
+	}
+	// withTitle returns the requests of the test's spans.
+	withTitle := func(reqs []DebugRequest, title string) []DebugRequest {
+		var res []DebugRequest
+		for _, r := range reqs {
+			if r.Title == title {
+				res = append(res, r)
+			}
+		}
+		return res
+	}
+	countCompleted := func(f DebugFamily) int64 {
+		var count int64
+		for _, b := range f.Buckets {
+			count += b.Count
+			if len(b.Recent) > debugRequestsPerBucket {
+				t.Errorf("too many requests retained: %d", len(b.Recent))
+			}
+		}
+		return count
+	}
+
+	active := tr.StartSpan("test-debug-active")
+	active.LogKV("event", "working")
+	defer active.Finish()
+
+	before := countCompleted(family())
+	for i := 0; i < debugRequestsPerBucket+2; i++ {
+		sp := tr.StartSpan("test-debug-done")
+		if i == 0 {
+			otext.Error.Set(sp, true)
+		}
+		sp.Finish()
+	}
+
+	f := family()
+	if len(f.Buckets) != len(debugLatencyBuckets) {
+		t.Fatalf("expected %d buckets, got %d", len(debugLatencyBuckets), len(f.Buckets))
+	}
+	act := withTitle(f.Active, "test-debug-active")
+	if len(act) != 1 || !act[0].Active {
+		t.Fatalf("expected one active request, got %+v", f.Active)
+	}
+	if ev := act[0].Events; len(ev) != 1 || ev[0].What != "working" {
+		t.Errorf("unexpected events %+v", ev)
+	}
+	if r := withTitle(f.Active, "test-debug-done"); len(r) != 0 {
+		t.Errorf("unexpected active requests %+v", r)
+	}
+	if count := countCompleted(f) - before; count != debugRequestsPerBucket+2 {
+		t.Errorf("expected %d requests, got %d", debugRequestsPerBucket+2, count)
+	}
+	if r := withTitle(f.Errors, "test-debug-done"); len(r) != 1 || !r[0].Failed {
+		t.Errorf("expected one failed request, got %+v", f.Errors)
+	}
+}
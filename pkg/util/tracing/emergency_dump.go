@@ -0,0 +1,139 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Limits on the work done by EmergencyDump, so that it doesn't hold up a
+// crash.
+const (
+	// emergencyDumpTimeout bounds the time spent collecting the data.
+	emergencyDumpTimeout = 2 * time.Second
+	// emergencyDumpMaxRecordings is the maximum number of recordings written
+	// for each Tracer, most relevant first: those of the open spans, then of
+	// the most recent failed traces, then of the most recent traces.
+	emergencyDumpMaxRecordings = 10
+	// emergencyDumpMaxBytes bounds the size of the output.
+	emergencyDumpMaxBytes = 256 << 10
+)
+
+// EmergencyDump writes the open spans and the buffered recordings of all the
+// Tracers of the process to w, on a best-effort basis. It is meant for crash
+// handlers, so the output is bounded and it gives up after a timeout.
+func EmergencyDump(w io.Writer) {
+	done := make(chan []byte, 1)
+	go func() {
+		var buf bytes.Buffer
+		tracerRegistry.ForEach(func(t *Tracer) {
+			if buf.Len() < emergencyDumpMaxBytes {
+				t.emergencyDump(&buf)
+			}
+		})
+		done <- truncateEmergencyDump(buf.Bytes())
+	}()
+	select {
+	case data := <-done:
+		_, _ = w.Write(data)
+	case <-time.After(emergencyDumpTimeout):
+		_, _ = io.WriteString(w, "tracing: timed out collecting the in-flight traces\n")
+	}
+}
+
+// emergencyDump writes the in-flight traces of the tracer to buf; see
+// EmergencyDump.
+func (t *Tracer) emergencyDump(buf *bytes.Buffer) {
+	t.activeSpans.Lock()
+	open := make([]*span, 0, len(t.activeSpans.spans))
+	for s := range t.activeSpans.spans {
+		open = append(open, s)
+	}
+	t.activeSpans.Unlock()
+
+	// writeRecording writes a recording unless one of the limits is reached,
+	// in which case it returns false.
+	recordings := 0
+	writeRecording := func(header string, rec Recording) bool {
+		if recordings >= emergencyDumpMaxRecordings || buf.Len() >= emergencyDumpMaxBytes {
+			return false
+		}
+		recordings++
+		fmt.Fprintf(buf, "tracing: %s:\n%s", header, FormatRecordedSpans(rec))
+		return true
+	}
+
+	if len(open) > 0 {
+		sort.Slice(open, func(i, j int) bool {
+			return open[i].startTime.Before(open[j].startTime)
+		})
+		fmt.Fprintf(buf, "tracing: %d open spans:\n", len(open))
+		now := t.now()
+		var groups []*spanGroup
+		seen := make(map[*spanGroup]bool)
+		for _, s := range open {
+			if buf.Len() >= emergencyDumpMaxBytes {
+				return
+			}
+			fmt.Fprintln(buf, AbandonedSpan{
+				Operation: s.operation,
+				TraceID:   s.TraceID,
+				SpanID:    s.SpanID,
+				Age:       now.Sub(s.startTime),
+				Tags:      GetSpanTags(s),
+			})
+			s.mu.Lock()
+			g := s.mu.recordingGroup
+			s.mu.Unlock()
+			if g != nil && s.isRecording() && !seen[g] {
+				seen[g] = true
+				groups = append(groups, g)
+			}
+		}
+		for _, g := range groups {
+			if !writeRecording("recording of open spans", g.getSpans()) {
+				return
+			}
+		}
+	}
+
+	for _, rec := range t.ErrorRecordings() {
+		if !writeRecording("recording of a recent failed trace", rec) {
+			return
+		}
+	}
+	for _, rec := range t.RecentTraces("", "") {
+		if !writeRecording("recording of a recent trace", rec) {
+			return
+		}
+	}
+}
+
+// truncateEmergencyDump cuts data to emergencyDumpMaxBytes, at the end of a
+// line, noting the truncation.
+func truncateEmergencyDump(data []byte) []byte {
+	if len(data) <= emergencyDumpMaxBytes {
+		return data
+	}
+	data = data[:emergencyDumpMaxBytes]
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		data = data[:i+1]
+	}
+	return append(data, fmt.Sprintf("tracing: output truncated to %d bytes\n", len(data))...)
+}
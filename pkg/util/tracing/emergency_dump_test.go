@@ -0,0 +1,80 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	otext "github.com/opentracing/opentracing-go/ext"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestEmergencyDump(t *testing.T) {
+	defer settings.TestingSetDuration(&spanGCMaxAge, time.Hour)()
+	tr := NewTracer().(*Tracer)
+	defer tr.Close()
+
+	failed := tr.StartSpan("failed-op", Recordable)
+	otext.Error.Set(failed, true)
+	failed.Finish()
+
+	open := tr.StartSpan("open-op", Recordable)
+	StartRecording(open, SingleNodeRecording)
+	open.LogKV("event", "in-flight-event")
+	defer open.Finish()
+
+	var buf bytes.Buffer
+	EmergencyDump(&buf)
+	for _, exp := range []string{
+		"open spans:",
+		`span "open-op"`,
+		"in-flight-event",
+		"recording of a recent failed trace",
+		"failed-op",
+	} {
+		if !strings.Contains(buf.String(), exp) {
+			t.Errorf("expected %q in:\n%s", exp, buf.String())
+		}
+	}
+}
+
+func TestEmergencyDumpLimits(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	defer tr.Close()
+
+	for i := 0; i < emergencyDumpMaxRecordings+5; i++ {
+		sp := tr.StartSpan("failed-op", Recordable)
+		otext.Error.Set(sp, true)
+		sp.Finish()
+	}
+	var buf bytes.Buffer
+	tr.emergencyDump(&buf)
+	if n := strings.Count(buf.String(), "recording of a recent failed trace"); n != emergencyDumpMaxRecordings {
+		t.Errorf("expected %d recordings, got %d", emergencyDumpMaxRecordings, n)
+	}
+
+	data := bytes.Repeat([]byte("0123456789\n"), emergencyDumpMaxBytes/5)
+	truncated := truncateEmergencyDump(data)
+	if len(truncated) > emergencyDumpMaxBytes+100 {
+		t.Errorf("expected the output to be truncated, got %d bytes", len(truncated))
+	}
+	if !bytes.HasSuffix(truncated, []byte("0123456789\ntracing: output truncated to 262141 bytes\n")) {
+		t.Errorf("unexpected end of output %q", truncated[len(truncated)-100:])
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	otext "github.com/opentracing/opentracing-go/ext"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// maxErrorRecordings is the number of recordings retained by each Tracer's
+// error buffer.
+const maxErrorRecordings = 16
+
+// errorRecordings is a ring buffer containing the recordings of the most recent
+// traces that failed. It is populated regardless of the sampling decisions for
+// the shadow tracer, to help with chasing sporadic failures.
+type errorRecordings struct {
+	syncutil.Mutex
+	buf [maxErrorRecordings]Recording
+	// next is the position in buf where the next recording goes.
+	next int
+	// count is the number of recordings in buf.
+	count int
+}
+
+func (b *errorRecordings) add(rec Recording) {
+	b.Lock()
+	b.buf[b.next] = rec
+	b.next = (b.next + 1) % maxErrorRecordings
+	if b.count < maxErrorRecordings {
+		b.count++
+	}
+	b.Unlock()
+}
+
+// ErrorRecordings returns the recordings of the last few traces whose root span
+// was marked as failed, most recent first. For traces that were not recording,
+// only the root span is returned.
+func (t *Tracer) ErrorRecordings() []Recording {
+	b := &t.errorRecordings
+	b.Lock()
+	defer b.Unlock()
+	result := make([]Recording, 0, b.count)
+	for i := 1; i <= b.count; i++ {
+		result = append(result, b.buf[(b.next-i+maxErrorRecordings)%maxErrorRecordings])
+	}
+	return result
+}
+
+// maybeMarkFailed notes if the given tag marks the span as failed.
+func (s *span) maybeMarkFailed(key string, value interface{}) {
+	if key != string(otext.Error) {
+		return
+	}
+	if failed, ok := value.(bool); ok && failed {
+		atomic.StoreInt32(&s.failed, 1)
+	}
+}
+
+// maybeRetainErrorRecording adds the span's recording to the tracer's error
+// buffer if this is a failed root span. Called when the span finishes.
+func (s *span) maybeRetainErrorRecording() {
+	if s.parentSpanID != 0 || atomic.LoadInt32(&s.failed) == 0 {
+		return
+	}
+	s.tracer.errorRecordings.add(s.finishedRecording())
+	s.markRetained()
+}
+
+// finishedRecording returns the recording of a finished span or, if the span
+// was not recording, a recording containing only the span itself (without any
+// log messages) and the spans recorded because of VerboseOnError.
+func (s *span) finishedRecording() Recording {
+	if rec := GetRecording(s); rec != nil {
+		return rec
+	}
+	s.mu.Lock()
+	duration := s.mu.duration
+	s.mu.Unlock()
+	if duration == 0 {
+		duration = time.Nanosecond
+	}
+	var tags map[string]string
+	if atomic.LoadInt32(&s.failed) != 0 {
+		tags = map[string]string{string(otext.Error): "true"}
+	}
+	s.mu.Lock()
+	if t, ok := s.mu.tags[TagCorrelationID]; ok {
+		// Keep the recording associated with its correlation ID.
+		if tags == nil {
+			tags = make(map[string]string)
+		}
+		tags[TagCorrelationID] = fmt.Sprint(t.value)
+	}
+	s.mu.Unlock()
+	rec := Recording{{
+		TraceID:      s.TraceID,
+		SpanID:       s.SpanID,
+		Operation:    s.operation,
+		StartTime:    s.startTime,
+		Duration:     duration,
+		ClockReading: s.tracer.now(),
+		Tags:         tags,
+	}}
+	// Include the spans recorded after the trace was upgraded because of an
+	// error, if any; see VerboseOnError.
+	if g := s.cost.verboseGroup(); g != nil {
+		rec = append(rec, g.recording(nil)...)
+	}
+	return rec
+}
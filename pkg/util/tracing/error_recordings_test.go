@@ -0,0 +1,107 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"testing"
+
+	otext "github.com/opentracing/opentracing-go/ext"
+)
+
+func TestErrorRecordings(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	tr.SetForceRealSpans(true)
+
+	// A recording root span that fails.
+	sp := tr.StartSpan("a")
+	StartRecording(sp, SingleNodeRecording)
+	child := StartChildSpan("b", sp, false /* separateRecording */)
+	child.LogKV("x", 1)
+	// Errors on non-root spans don't count.
+	otext.Error.Set(child, true)
+	child.Finish()
+	otext.Error.Set(sp, true)
+	sp.Finish()
+
+	// A successful span.
+	tr.StartSpan("c").Finish()
+
+	// A non-recording root span that fails.
+	sp = tr.StartSpan("d")
+	otext.Error.Set(sp, true)
+	sp.Finish()
+
+	recs := tr.ErrorRecordings()
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 recordings, got %d", len(recs))
+	}
+	if err := TestingCheckRecordedSpans(recs[0], `
+		span d:
+			tags: error=true
+	`); err != nil {
+		t.Error(err)
+	}
+	if err := TestingCheckRecordedSpans(recs[1], `
+		span a:
+			tags: error=true
+		span b:
+			tags: error=true
+			x: 1
+	`); err != nil {
+		t.Error(err)
+	}
+
+	// Only the most recent recordings are retained.
+	for i := 0; i < maxErrorRecordings+5; i++ {
+		sp := tr.StartSpan(fmt.Sprintf("e%d", i))
+		otext.Error.Set(sp, true)
+		sp.Finish()
+	}
+	recs = tr.ErrorRecordings()
+	if len(recs) != maxErrorRecordings {
+		t.Fatalf("expected %d recordings, got %d", maxErrorRecordings, len(recs))
+	}
+	if op, exp := recs[0][0].Operation, fmt.Sprintf("e%d", maxErrorRecordings+4); op != exp {
+		t.Errorf("expected most recent recording to be %s, got %s", exp, op)
+	}
+}
+
+func TestErrorRecordingsTracingDisabled(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+
+	// Noop spans are not retained.
+	sp := tr.StartSpan("noop")
+	otext.Error.Set(sp, true)
+	sp.Finish()
+	if recs := tr.ErrorRecordings(); len(recs) != 0 {
+		t.Fatalf("expected no recordings, got %v", recs)
+	}
+
+	// Recordable spans are real, so they are retained.
+	sp = tr.StartSpan("recordable", Recordable)
+	otext.Error.Set(sp, true)
+	sp.Finish()
+	recs := tr.ErrorRecordings()
+	if len(recs) != 1 {
+		t.Fatalf("expected 1 recording, got %d", len(recs))
+	}
+	if err := TestingCheckRecordedSpans(recs[0], `
+		span recordable:
+			tags: error=true
+	`); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// TraceEvent is an event recorded with RecordEvent.
+type TraceEvent struct {
+	TraceID uint64
+	Time    time.Time
+	Message string
+}
+
+// ctxEventBufferKey is an empty type for the handle associated with the
+// EventBuffer value (see context.Value).
+type ctxEventBufferKey struct{}
+
+// EventBuffer collects events for hot paths where creating a span per operation
+// is too expensive. See WithEventBuffer, RecordEvent and Flush.
+type EventBuffer struct {
+	traceID uint64
+	mu      struct {
+		syncutil.Mutex
+		events  []bufferedEvent
+		dropped int
+	}
+}
+
+// maxBufferedEvents bounds the number of events in an EventBuffer; the
+// buffers are meant to be small, since they are often discarded.
+const maxBufferedEvents = 128
+
+type bufferedEvent struct {
+	time time.Time
+	// msg is a stringMessage or, if the formatting was deferred, a lazyMessage.
+	msg fmt.Stringer
+}
+
+type stringMessage string
+
+func (s stringMessage) String() string { return string(s) }
+
+// WithEventBuffer returns a context with a new EventBuffer, along with the
+// buffer. The events are associated with the trace of the span in the context,
+// if any.
+func WithEventBuffer(ctx context.Context) (context.Context, *EventBuffer) {
+	b := &EventBuffer{}
+	if s, ok := opentracing.SpanFromContext(ctx).(*span); ok {
+		b.traceID = s.TraceID
+	}
+	return context.WithValue(ctx, ctxEventBufferKey{}, b), b
+}
+
+// EventBufferFromContext returns the EventBuffer in the context, or nil.
+func EventBufferFromContext(ctx context.Context) *EventBuffer {
+	if val := ctx.Value(ctxEventBufferKey{}); val != nil {
+		return val.(*EventBuffer)
+	}
+	return nil
+}
+
+// RecordEvent records a printf-style event in the EventBuffer in the context;
+// it is a noop if there is no buffer. As with Recordf, the formatting is
+// deferred until the events are retrieved if the arguments are immutable.
+func RecordEvent(ctx context.Context, format string, args ...interface{}) {
+	b := EventBufferFromContext(ctx)
+	if b == nil {
+		return
+	}
+	if argsAreImmutable(args) {
+		b.add(lazyMessage{format: format, args: args})
+	} else {
+		b.add(stringMessage(fmt.Sprintf(format, args...)))
+	}
+}
+
+// BufferEvent records a message in the EventBuffer in the context; it is a
+// noop if there is no buffer. The message ends up in a span only if the owner
+// of the buffer flushes it (e.g. because the operation turned out to be slow,
+// see RunWithRetroactiveSpan); otherwise it is discarded with the buffer.
+func BufferEvent(ctx context.Context, msg string) {
+	if b := EventBufferFromContext(ctx); b != nil {
+		b.add(stringMessage(msg))
+	}
+}
+
+func (b *EventBuffer) add(msg fmt.Stringer) {
+	now := time.Now()
+	b.mu.Lock()
+	if len(b.mu.events) < maxBufferedEvents {
+		b.mu.events = append(b.mu.events, bufferedEvent{time: now, msg: msg})
+	} else {
+		b.mu.dropped++
+	}
+	b.mu.Unlock()
+}
+
+// Events returns the events recorded so far. The buffer can be nil.
+func (b *EventBuffer) Events() []TraceEvent {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	res := make([]TraceEvent, len(b.mu.events))
+	for i, e := range b.mu.events {
+		res[i] = TraceEvent{TraceID: b.traceID, Time: e.time, Message: e.msg.String()}
+	}
+	return res
+}
+
+// Flush moves the events recorded so far into the span, as log messages with
+// the time of the respective events. The buffer can be nil.
+func (b *EventBuffer) Flush(os opentracing.Span) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	events, dropped := b.mu.events, b.mu.dropped
+	b.mu.events, b.mu.dropped = nil, 0
+	b.mu.Unlock()
+	if dropped > 0 {
+		events = append(events, bufferedEvent{
+			time: time.Now(),
+			msg:  stringMessage(fmt.Sprintf("%d buffered events dropped", dropped)),
+		})
+	}
+
+	s, ok := os.(*span)
+	if !ok || s.shadowTr != nil || s.events != nil {
+		// We can't preserve the timestamps.
+		if _, noop := os.(*noopSpan); noop || os == nil {
+			return
+		}
+		for _, e := range events {
+			os.LogFields(otlog.String("event", e.msg.String()))
+		}
+		return
+	}
+	if !s.isVerbose() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range events {
+		if len(s.mu.recordedLogs) >= maxLogsPerSpan {
+			break
+		}
+		s.mu.recordedLogs = append(s.mu.recordedLogs, opentracing.LogRecord{
+			Timestamp: e.time,
+			Fields:    []otlog.Field{otlog.Object("event", e.msg)},
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+)
+
+func TestEventBuffer(t *testing.T) {
+	// Without a buffer, events are discarded.
+	RecordEvent(context.Background(), "ignored")
+	BufferEvent(context.Background(), "ignored")
+	EventBufferFromContext(context.Background()).Flush(nil)
+
+	tr := NewTracer()
+	sp := tr.StartSpan("a", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	ctx := opentracing.ContextWithSpan(context.Background(), sp)
+
+	ctx, b := WithEventBuffer(ctx)
+	if EventBufferFromContext(ctx) != b {
+		t.Fatal("buffer not found in context")
+	}
+	for i := 0; i < 3; i++ {
+		RecordEvent(ctx, "key %d", i)
+	}
+	events := b.Events()
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	if e := events[1]; e.TraceID != sp.(*span).TraceID || e.Message != "key 1" {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	// The buffer is bounded.
+	for i := 0; i < maxBufferedEvents; i++ {
+		BufferEvent(ctx, "overflow")
+	}
+	if n := len(b.Events()); n != maxBufferedEvents {
+		t.Fatalf("expected %d events, got %d", maxBufferedEvents, n)
+	}
+	b.mu.Lock()
+	b.mu.events = b.mu.events[:3]
+	b.mu.Unlock()
+
+	b.Flush(sp)
+	if len(b.Events()) != 0 {
+		t.Fatal("expected empty buffer after flush")
+	}
+	sp.Finish()
+	if err := TestingCheckRecordedSpans(GetRecording(sp), `
+		span a:
+			event: key 0
+			event: key 1
+			event: key 2
+			event: 3 buffered events dropped
+	`); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sync/atomic"
+
+	"golang.org/x/net/trace"
+)
+
+// EventSink receives the events of real spans as they happen. By default,
+// events go to x/net/trace when trace.debug.enable is set.
+type EventSink interface {
+	// Enabled is called when a span is started; if it returns false, the
+	// span's events are not sent to the sink. Spans are forced to be real spans
+	// while the sink is enabled, so this should return false when nobody is
+	// looking at the events.
+	Enabled() bool
+	// NewSpanEvents is called when a span is started (while the sink is
+	// enabled) and returns the destination of the span's events.
+	NewSpanEvents(operation string) SpanEvents
+}
+
+// SpanEvents receives the events of a span. It is implemented by
+// x/net/trace.Trace.
+//
+// If a SpanEvents also implements SetError(), it is called before Finish for
+// the spans that were marked as failed (by setting the standard "error" tag).
+type SpanEvents interface {
+	// LazyPrintf adds an event. The arguments may be retained and formatted
+	// later; they must not be modified after the call.
+	LazyPrintf(format string, a ...interface{})
+	// Finish is called when the span is finished.
+	Finish()
+}
+
+// netTraceFamily is the x/net/trace family of the spans' traces.
+const netTraceFamily = "tracing"
+
+var netTraceDebugFamily = getDebugFamily(netTraceFamily)
+
+// netTraceSink is the default EventSink, which sends events to x/net/trace.
+type netTraceSink struct{}
+
+var _ EventSink = netTraceSink{}
+
+// Enabled is part of the EventSink interface.
+func (netTraceSink) Enabled() bool {
+	return enableNetTrace.Get()
+}
+
+// NewSpanEvents is part of the EventSink interface.
+func (netTraceSink) NewSpanEvents(operation string) SpanEvents {
+	tr := trace.New(netTraceFamily, operation)
+	tr.SetMaxEvents(maxLogsPerSpan)
+	return netTraceEvents{tr: tr, r: newDebugRequest(netTraceDebugFamily, operation)}
+}
+
+// finishEvents finishes the span's events, if any. Called when the span
+// finishes.
+func (s *span) finishEvents() {
+	if s.events == nil {
+		return
+	}
+	if e, ok := s.events.(interface{ SetError() }); ok && atomic.LoadInt32(&s.failed) != 0 {
+		e.SetError()
+	}
+	s.events.Finish()
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+type testEventSink struct {
+	enabled bool
+	spans   map[string]*testSpanEvents
+}
+
+func (s *testEventSink) Enabled() bool { return s.enabled }
+
+func (s *testEventSink) NewSpanEvents(operation string) SpanEvents {
+	ev := &testSpanEvents{}
+	s.spans[operation] = ev
+	return ev
+}
+
+type testSpanEvents struct {
+	events   []string
+	finished bool
+}
+
+func (ev *testSpanEvents) LazyPrintf(format string, a ...interface{}) {
+	ev.events = append(ev.events, fmt.Sprintf(format, a...))
+}
+
+func (ev *testSpanEvents) Finish() { ev.finished = true }
+
+func TestEventSink(t *testing.T) {
+	sink := &testEventSink{spans: make(map[string]*testSpanEvents)}
+	tr := NewTracerWithOptions(TracerOptions{
+		GlobalTags: opentracing.Tags{"node": 1},
+		EventSink:  sink,
+	})
+
+	// A disabled sink doesn't cause real spans.
+	if sp := tr.StartSpan("a"); !IsBlackHoleSpan(sp) {
+		t.Fatal("expected black hole span")
+	}
+
+	sink.enabled = true
+	sp := tr.StartSpan("b")
+	sp.SetTag("k", "v")
+	sp.LogKV("event", "hello")
+	sp.Finish()
+
+	ev := sink.spans["b"]
+	if ev == nil || !ev.finished {
+		t.Fatalf("expected finished span events, got %+v", ev)
+	}
+	if exp := []string{"node:1", "k:v", "hello"}; !reflect.DeepEqual(ev.events, exp) {
+		t.Errorf("expected events %v, got %v", exp, ev.events)
+	}
+	if _, ok := sink.spans["a"]; ok {
+		t.Error("unexpected events for span started while the sink was disabled")
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// execTasksEnabled controls whether real spans open a task in the Go execution
+// tracer, annotated with the trace and span IDs.
+var execTasksEnabled = execTasksSupported &&
+	envutil.EnvOrDefaultBool("COCKROACH_EXECUTION_TRACE_TASKS", false)
+
+// maybeStartExecTask opens an execution tracer task for the span, if enabled.
+// The task is nested under the parent's task, if there is one.
+func (s *span) maybeStartExecTask(parent *execTask) {
+	if execTasksEnabled {
+		s.execTask = startExecTask(parent, s.operation, s.spanMeta)
+	}
+}
+
+// WithExecutionRegion runs fn inside a region of the Go execution tracer, as
+// part of the task of the span in ctx (if any).
+func WithExecutionRegion(ctx context.Context, regionType string, fn func()) {
+	if sp, ok := opentracing.SpanFromContext(ctx).(*span); ok && sp.execTask.active() {
+		sp.execTask.withRegion(regionType, fn)
+		return
+	}
+	fn()
+}
@@ -0,0 +1,31 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build !go1.11
+
+package tracing
+
+// execTasksSupported is false: runtime/trace user annotations are not
+// available before go1.11.
+const execTasksSupported = false
+
+type execTask struct{}
+
+func startExecTask(parent *execTask, operation string, meta spanMeta) execTask {
+	return execTask{}
+}
+
+func (t *execTask) active() bool                            { return false }
+func (t *execTask) end()                                    {}
+func (t *execTask) withRegion(regionType string, fn func()) { fn() }
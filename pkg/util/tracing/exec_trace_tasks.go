@@ -0,0 +1,59 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build go1.11
+
+package tracing
+
+import (
+	"context"
+	"runtime/trace"
+)
+
+// execTasksSupported is true if runtime/trace supports user annotations.
+const execTasksSupported = true
+
+// execTask is the Go execution tracer task associated with a span.
+type execTask struct {
+	ctx  context.Context
+	task *trace.Task
+}
+
+func startExecTask(parent *execTask, operation string, meta spanMeta) execTask {
+	if !trace.IsEnabled() {
+		// No execution trace is being captured.
+		return execTask{}
+	}
+	ctx := context.Background()
+	if parent != nil && parent.active() {
+		ctx = parent.ctx
+	}
+	ctx, task := trace.NewTask(ctx, operation)
+	trace.Log(ctx, "span", meta.String())
+	return execTask{ctx: ctx, task: task}
+}
+
+func (t *execTask) active() bool {
+	return t.task != nil
+}
+
+func (t *execTask) end() {
+	if t.task != nil {
+		t.task.End()
+	}
+}
+
+func (t *execTask) withRegion(regionType string, fn func()) {
+	trace.WithRegion(t.ctx, regionType, fn)
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"runtime/trace"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestExecutionTraceTasks(t *testing.T) {
+	if !execTasksSupported {
+		t.Skip("execution tracer annotations not supported")
+	}
+	defer func(prev bool) { execTasksEnabled = prev }(execTasksEnabled)
+	execTasksEnabled = true
+
+	tr := NewTracer()
+
+	// No execution trace is being captured: no task is created.
+	sp := tr.StartSpan("idle", Recordable)
+	if sp.(*span).execTask.active() {
+		t.Error("unexpected execution tracer task")
+	}
+	sp.Finish()
+
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Fatal(err)
+	}
+	defer trace.Stop()
+
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SingleNodeRecording)
+	if !root.(*span).execTask.active() {
+		t.Fatal("expected execution tracer task")
+	}
+	child := StartChildSpan("child", root, false /* separateRecording */)
+	if !child.(*span).execTask.active() {
+		t.Fatal("expected execution tracer task on child")
+	}
+	remoteChild := tr.StartSpan("remote", opentracing.ChildOf(root.Context()))
+	if !remoteChild.(*span).execTask.active() {
+		t.Fatal("expected execution tracer task on remote child")
+	}
+
+	ran := false
+	ctx := opentracing.ContextWithSpan(context.Background(), child)
+	WithExecutionRegion(ctx, "region", func() { ran = true })
+	if !ran {
+		t.Error("region function not run")
+	}
+	remoteChild.Finish()
+	child.Finish()
+	root.Finish()
+}
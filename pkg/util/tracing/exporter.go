@@ -0,0 +1,58 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import "sync/atomic"
+
+// SpanExporter receives the recorded spans as they finish. Exporters are
+// configured through TracerOptions.
+type SpanExporter interface {
+	// Export is called by Finish with the local spans that finished. It must
+	// not block, and the spans must not be modified.
+	Export(spans []RecordedSpan) error
+}
+
+// SpanExporterFunc is an adapter to use a function as a SpanExporter.
+type SpanExporterFunc func(spans []RecordedSpan) error
+
+// Export is part of the SpanExporter interface.
+func (f SpanExporterFunc) Export(spans []RecordedSpan) error {
+	return f(spans)
+}
+
+// maybeExport hands the span over to the tracer's exporters if it is
+// recording. Called when the span finishes.
+func (s *span) maybeExport() {
+	if len(s.tracer.exporters) == 0 || !s.isRecording() || !tracingEnabled.Get() {
+		return
+	}
+	s.mu.Lock()
+	group := s.mu.recordingGroup
+	s.mu.Unlock()
+	spans := []RecordedSpan{s.getRecordedSpan(s.tracer.now(), group != nil && group.structural)}
+	for _, e := range s.tracer.exporters {
+		if err := e.Export(spans); err != nil {
+			atomic.AddInt64(&s.tracer.exportErrors, 1)
+		} else {
+			s.markRetained()
+		}
+	}
+}
+
+// SpanExportErrors returns the number of errors returned by the tracer's
+// SpanExporters.
+func (t *Tracer) SpanExportErrors() int64 {
+	return atomic.LoadInt64(&t.exportErrors)
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestSpanExporter(t *testing.T) {
+	var exported []RecordedSpan
+	tr := NewTracerWithOptions(TracerOptions{
+		SpanExporters: []SpanExporter{
+			SpanExporterFunc(func(spans []RecordedSpan) error {
+				exported = append(exported, spans...)
+				return nil
+			}),
+			SpanExporterFunc(func([]RecordedSpan) error {
+				return errors.New("unavailable")
+			}),
+		},
+	}).(*Tracer)
+
+	// Spans that are not recording are not exported.
+	sp := tr.StartSpan("not recording", Recordable)
+	sp.Finish()
+
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SingleNodeRecording)
+	child := StartChildSpan("child", root, false /* separateRecording */)
+	child.LogKV("event", "x")
+	child.Finish()
+	root.Finish()
+
+	if err := TestingCheckRecordedSpans(exported, `
+		span child:
+		  event: x
+		span root:
+	`); err != nil {
+		t.Fatal(err)
+	}
+	if exported[0].ParentSpanID != exported[1].SpanID || exported[0].Duration <= 0 {
+		t.Errorf("unexpected exported spans %+v", exported)
+	}
+	if n := tr.SpanExportErrors(); n != 2 {
+		t.Errorf("expected 2 export errors, got %d", n)
+	}
+}
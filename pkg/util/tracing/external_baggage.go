@@ -0,0 +1,91 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	opentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// externalBaggagePolicy controls how ExtractExternal handles baggage.
+type externalBaggagePolicy int64
+
+const (
+	// externalBaggageDrop discards all the baggage.
+	externalBaggageDrop externalBaggagePolicy = iota
+	// externalBaggageAccept keeps the baggage as is.
+	externalBaggageAccept
+	// externalBaggageNamespace keeps the baggage, but prepends
+	// prefixExternalBaggage to the keys.
+	externalBaggageNamespace
+)
+
+// prefixExternalBaggage is prepended to the keys of external baggage items by
+// the namespace policy, so that they can't be mistaken for our own items (e.g.
+// Snowball).
+const prefixExternalBaggage = "ext-"
+
+var externalBaggage = settings.RegisterEnumSetting(
+	"trace.external_baggage.policy",
+	"how baggage in span contexts coming from external clients is handled",
+	"drop",
+	map[int64]string{
+		int64(externalBaggageDrop):      "drop",
+		int64(externalBaggageAccept):    "accept",
+		int64(externalBaggageNamespace): "namespace",
+	},
+)
+
+// externalPassThrough enables the pass-through mode for external contexts; see
+// ExtractExternal.
+var externalPassThrough = settings.RegisterBoolSetting(
+	"trace.external_context.pass_through.enabled",
+	"if set, operations that are not traced but continue an external trace propagate its trace and span IDs and baggage to the requests they issue",
+	false,
+)
+
+// ExtractExternal is like Extract, for carriers that don't come from another
+// node. Their baggage is handled according to trace.external_baggage.policy,
+// and trace.external_context.pass_through.enabled makes noop spans re-emit the
+// external context.
+func (t *Tracer) ExtractExternal(
+	format interface{}, carrier interface{},
+) (opentracing.SpanContext, error) {
+	osc, err := t.Extract(format, carrier)
+	if err != nil {
+		return osc, err
+	}
+	sc, ok := osc.(*spanContext)
+	if !ok {
+		return osc, nil
+	}
+	sc.external = true
+	if len(sc.Baggage) == 0 {
+		return sc, nil
+	}
+	switch externalBaggagePolicy(externalBaggage.Get()) {
+	case externalBaggageAccept:
+	case externalBaggageNamespace:
+		baggage := make(map[string]string, len(sc.Baggage))
+		for k, v := range sc.Baggage {
+			baggage[prefixExternalBaggage+k] = v
+		}
+		sc.Baggage = baggage
+	default:
+		sc.Baggage = nil
+	}
+	return sc, nil
+}
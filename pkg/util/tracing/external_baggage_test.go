@@ -0,0 +1,115 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestExtractExternal(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	carrier := opentracing.TextMapCarrier{
+		fieldNameTraceID:          "1",
+		fieldNameSpanID:           "2",
+		prefixBaggage + Snowball:  "1",
+		prefixBaggage + "request": "x",
+	}
+
+	testCases := []struct {
+		policy   externalBaggagePolicy
+		expected map[string]string
+	}{
+		{externalBaggageDrop, nil},
+		{externalBaggageAccept, map[string]string{Snowball: "1", "request": "x"}},
+		{externalBaggageNamespace, map[string]string{"ext-" + Snowball: "1", "ext-request": "x"}},
+	}
+	for _, tc := range testCases {
+		func() {
+			defer settings.TestingSetEnum(&externalBaggage, int64(tc.policy))()
+			wireContext, err := tr.ExtractExternal(opentracing.TextMap, carrier)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := wireContext.(*spanContext).Baggage; !reflect.DeepEqual(b, tc.expected) {
+				t.Errorf("%d: expected baggage %v, got %v", tc.policy, tc.expected, b)
+			}
+			// Snowball recording is only triggered if the baggage is accepted as is.
+			sp := tr.StartSpan("a", opentracing.ChildOf(wireContext))
+			if rec := GetRecording(sp) != nil; rec != (tc.policy == externalBaggageAccept) {
+				t.Errorf("%d: unexpected recording state %t", tc.policy, rec)
+			}
+			sp.Finish()
+		}()
+	}
+}
+
+func TestExternalPassThrough(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	carrier := opentracing.TextMapCarrier{
+		fieldNameTraceID:          "1",
+		fieldNameSpanID:           "2",
+		prefixBaggage + "request": "x",
+	}
+	defer settings.TestingSetEnum(&externalBaggage, int64(externalBaggageAccept))()
+
+	extract := func() opentracing.SpanContext {
+		wireContext, err := tr.ExtractExternal(opentracing.TextMap, carrier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wireContext
+	}
+
+	// By default, the external context is dropped by the noop span.
+	sp := tr.StartSpan("a", opentracing.ChildOf(extract()))
+	if _, noop := sp.Context().(noopSpanContext); !noop {
+		t.Fatalf("expected noop context, got %+v", sp.Context())
+	}
+
+	defer settings.TestingSetBool(&externalPassThrough, true)()
+	sp = tr.StartSpan("a", opentracing.ChildOf(extract()))
+	if !IsBlackHoleSpan(sp) {
+		t.Fatal("expected a noop span")
+	}
+	if v := sp.BaggageItem("request"); v != "x" {
+		t.Errorf("expected request baggage, got %q", v)
+	}
+	// The external context survives local child spans and is re-emitted by
+	// Inject.
+	child := StartChildSpan("child", sp, false /* separateRecording */)
+	grandchild := tr.StartSpan("grandchild", opentracing.ChildOf(child.Context()))
+	out := make(opentracing.TextMapCarrier)
+	if err := tr.Inject(grandchild.Context(), opentracing.TextMap, out); err != nil {
+		t.Fatal(err)
+	}
+	wireContext, err := tr.Extract(opentracing.TextMap, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc, ok := wireContext.(*spanContext)
+	if !ok || sc.TraceID != 1 || sc.SpanID != 2 || sc.Baggage["request"] != "x" {
+		t.Errorf("external context not passed through: %+v", wireContext)
+	}
+
+	// Contexts from other nodes are not affected.
+	sp = tr.StartSpan("b", opentracing.ChildOf(wireContext))
+	if _, noop := sp.Context().(noopSpanContext); !noop {
+		t.Errorf("expected noop context, got %+v", sp.Context())
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	opentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// ExtractCache remembers the span context most recently extracted through it,
+// keyed on the contents of the carrier. It is safe for concurrent use.
+type ExtractCache struct {
+	tracer opentracing.Tracer
+
+	mu struct {
+		syncutil.Mutex
+		format interface{}
+		key    string
+		sc     opentracing.SpanContext
+	}
+}
+
+// NewExtractCache creates an ExtractCache that extracts span contexts using
+// the given tracer.
+func NewExtractCache(tracer opentracing.Tracer) *ExtractCache {
+	return &ExtractCache{tracer: tracer}
+}
+
+// ExtractCached is like Extract, but returns the previously extracted span
+// context if the carrier has the same contents as last time. Only TextMap and
+// HTTPHeaders carriers are cached.
+func (c *ExtractCache) ExtractCached(
+	format interface{}, carrier interface{},
+) (opentracing.SpanContext, error) {
+	reader, ok := carrier.(opentracing.TextMapReader)
+	if !ok || (format != opentracing.TextMap && format != opentracing.HTTPHeaders) {
+		return c.tracer.Extract(format, carrier)
+	}
+	entries := make(opentracing.TextMapCarrier)
+	if err := reader.ForeachKey(func(k, v string) error {
+		entries[k] = v
+		return nil
+	}); err != nil {
+		return c.tracer.Extract(format, carrier)
+	}
+	key := string(encodeCarrier(entries))
+
+	c.mu.Lock()
+	if c.mu.sc != nil && c.mu.format == format && c.mu.key == key {
+		sc := c.mu.sc
+		c.mu.Unlock()
+		return sc, nil
+	}
+	c.mu.Unlock()
+
+	// The original carrier is passed on for the BaggageAuthorizer.
+	sc, err := c.tracer.Extract(format, carrier)
+	if err != nil {
+		return sc, err
+	}
+	c.mu.Lock()
+	c.mu.format = format
+	c.mu.key = key
+	c.mu.sc = sc
+	c.mu.Unlock()
+	return sc, nil
+}
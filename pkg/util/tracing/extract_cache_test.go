@@ -0,0 +1,65 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestExtractCache(t *testing.T) {
+	tr := NewTracer()
+	tr.(*Tracer).SetForceRealSpans(true)
+	c := NewExtractCache(tr)
+
+	inject := func(sp opentracing.Span) opentracing.HTTPHeadersCarrier {
+		carrier := make(opentracing.HTTPHeadersCarrier)
+		if err := tr.Inject(sp.Context(), opentracing.HTTPHeaders, carrier); err != nil {
+			t.Fatal(err)
+		}
+		return carrier
+	}
+	sp1 := tr.StartSpan("a")
+	sp1.SetBaggageItem("k", "v")
+	sp2 := tr.StartSpan("b")
+
+	sc1, err := c.ExtractCached(opentracing.HTTPHeaders, inject(sp1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc1.(*spanContext).SpanID != sp1.(*span).SpanID || sc1.(*spanContext).Baggage["k"] != "v" {
+		t.Fatalf("unexpected context %+v", sc1)
+	}
+	// The same context is returned for a carrier with the same contents.
+	if sc, err := c.ExtractCached(opentracing.HTTPHeaders, inject(sp1)); err != nil || sc != sc1 {
+		t.Errorf("expected cached context, got %+v (%v)", sc, err)
+	}
+	// A different carrier results in a new extraction.
+	sc2, err := c.ExtractCached(opentracing.HTTPHeaders, inject(sp2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc2 == sc1 || sc2.(*spanContext).SpanID != sp2.(*span).SpanID {
+		t.Errorf("unexpected context %+v", sc2)
+	}
+	// Errors are not cached.
+	bad := opentracing.TextMapCarrier{fieldNameTraceID: "xyz", fieldNameSpanID: "1"}
+	for i := 0; i < 2; i++ {
+		if _, err := c.ExtractCached(opentracing.TextMap, bad); err == nil {
+			t.Error("expected error")
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import opentracing "github.com/opentracing/opentracing-go"
+
+// ForceTraceBaggage is the baggage item which makes every span of a trace a
+// real span with snowball recording, regardless of sampling. It is privileged,
+// so it is subject to the BaggageAuthorizer. See ForceTrace.
+const ForceTraceBaggage = "trace-force"
+
+// samplingReasonForced is the TagSamplingReason of traces kept because of
+// ForceTraceBaggage.
+const samplingReasonForced = "forced"
+
+type forceTraceOption struct{}
+
+// ForceTrace is a StartSpanOption which forces the trace of the new span to be
+// fully traced; see ForceTraceBaggage. The span is always a real span.
+var ForceTrace opentracing.StartSpanOption = forceTraceOption{}
+
+func (forceTraceOption) Apply(*opentracing.StartSpanOptions) {}
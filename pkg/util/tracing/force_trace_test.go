@@ -0,0 +1,88 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	lightstep "github.com/lightstep/lightstep-tracer-go"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestForceTrace(t *testing.T) {
+	defer settings.TestingSetFloat(&sampleRate, 0)()
+	tr := NewTracer().(*Tracer)
+	tr.setShadowTracer(lightStepManager{}, lightstep.NewTracer(lightstep.Options{}))
+	defer tr.Close()
+
+	// Forced traces bypass sampling and are recorded.
+	sp := tr.StartSpan("query", ForceTrace)
+	if sp.(*span).shadowTr == nil || GetRecording(sp) == nil {
+		t.Fatal("expected a shadow span with a recording")
+	}
+	if r := GetSpanTags(sp)[TagSamplingReason]; r != samplingReasonForced {
+		t.Errorf("unexpected sampling reason %v", r)
+	}
+	if v := sp.BaggageItem(ForceTraceBaggage); v != "1" {
+		t.Errorf("expected force baggage, got %q", v)
+	}
+
+	// A trace that was not sampled is sent to the shadow tracer from the point
+	// where it is forced.
+	root := tr.StartSpan("root", Recordable)
+	if root.(*span).shadowTr != nil {
+		t.Fatal("expected the trace not to be sampled")
+	}
+	root.SetBaggageItem(ForceTraceBaggage, "1")
+	child := tr.StartSpan("child", opentracing.ChildOf(root.Context()))
+	if child.(*span).shadowTr == nil || GetRecording(child) == nil {
+		t.Fatal("expected a shadow span with a recording")
+	}
+
+	// The item propagates to other nodes, subject to the authorizer.
+	carrier := make(opentracing.TextMapCarrier)
+	if err := tr.Inject(sp.Context(), opentracing.TextMap, carrier); err != nil {
+		t.Fatal(err)
+	}
+	tr2 := NewTracer().(*Tracer)
+	tr2.SetBaggageAuthorizer(func(_ interface{}, key, _ string) bool {
+		return key != ForceTraceBaggage
+	})
+	wireContext, err := tr2.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := wireContext.(*spanContext).Baggage[ForceTraceBaggage]; v != "" {
+		t.Errorf("expected force baggage to be rejected, got %q", v)
+	}
+	tr2.SetBaggageAuthorizer(nil)
+	wireContext, err = tr2.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := wireContext.(*spanContext).Baggage[ForceTraceBaggage]; v != "" {
+		t.Errorf("expected force baggage to be rejected without an authorizer, got %q", v)
+	}
+	tr2.SetBaggageAuthorizer(func(interface{}, string, string) bool { return true })
+	wireContext, err = tr2.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := tr2.StartSpan("remote", opentracing.ChildOf(wireContext))
+	if remote.BaggageItem(ForceTraceBaggage) != "1" || GetRecording(remote) == nil {
+		t.Error("expected a forced remote span")
+	}
+}
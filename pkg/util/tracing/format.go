@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -27,9 +28,15 @@ import (
 	otlog "github.com/opentracing/opentracing-go/log"
 )
 
+// summaryCollapseThreshold is the duration under which Summary collapses
+// children into a single line.
+const summaryCollapseThreshold = time.Millisecond
+
 type traceLogData struct {
 	opentracing.LogRecord
 	depth int
+	// Location of the code that logged the event; see FieldLocation.
+	loc string
 }
 
 type traceLogs []traceLogData
@@ -48,11 +55,16 @@ func (l traceLogs) Swap(i, j int) {
 
 // FormatRecordedSpans formats the given spans for human consumption, showing the
 // relationship using nesting and times as both relative to the previous event
-// and cumulative.
+// and cumulative. The locations of the events are omitted, unless the
+// WithLocations option is passed.
 //
 // TODO(andrei): this should be unified with
 // SessionTracing.GenerateSessionTraceVTable.
-func FormatRecordedSpans(spans []RecordedSpan) string {
+func FormatRecordedSpans(spans []RecordedSpan, opts ...FormatOption) string {
+	var o formatOptions
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
 	m := make(map[uint64]*RecordedSpan)
 	for i, sp := range spans {
 		m[sp.SpanID] = &spans[i]
@@ -91,15 +103,20 @@ func FormatRecordedSpans(spans []RecordedSpan) string {
 		}
 		logs = append(logs, traceLogData{LogRecord: lr, depth: d})
 		for _, l := range sp.Logs {
+			loc, fields := splitLocation(l.Fields)
 			lr := opentracing.LogRecord{
 				Timestamp: l.Time,
-				Fields:    make([]otlog.Field, len(l.Fields)),
+				Fields:    make([]otlog.Field, 0, len(fields)),
 			}
-			for i, f := range l.Fields {
-				lr.Fields[i] = otlog.String(f.Key, f.Value)
+			for _, f := range fields {
+				if f.Key == FieldStructured {
+					// The event is also rendered as text, under the "event" key.
+					continue
+				}
+				lr.Fields = append(lr.Fields, otlog.String(f.Key, f.Value))
 			}
 
-			logs = append(logs, traceLogData{LogRecord: lr, depth: d})
+			logs = append(logs, traceLogData{LogRecord: lr, depth: d, loc: loc})
 		}
 	}
 	sort.Sort(logs)
@@ -117,8 +134,211 @@ func FormatRecordedSpans(spans []RecordedSpan) string {
 			}
 			fmt.Fprintf(&buf, "%s:%v", f.Key(), f.Value())
 		}
+		if o.locations && entry.loc != "" {
+			fmt.Fprintf(&buf, "  %s%s", o.locationPrefix, entry.loc)
+		}
 		buf.WriteByte('\n')
 		last = entry.Timestamp
 	}
 	return buf.String()
 }
+
+// String formats the recording as an indented tree of spans, with their
+// durations and tags, e.g.:
+//
+//   root: 10.000ms
+//       child: 2.000ms [k=v]
+//       child2: unfinished
+func (r Recording) String() string {
+	var buf bytes.Buffer
+	r.walk(func(sp *RecordedSpan, depth int) {
+		buf.WriteString(strings.Repeat("    ", depth))
+		fmt.Fprintf(&buf, "%s: %s", sp.Operation, formatSpanDuration(sp))
+		if tags := formatSpanTags(sp); tags != "" {
+			fmt.Fprintf(&buf, " [%s]", tags)
+		}
+		buf.WriteByte('\n')
+	})
+	return buf.String()
+}
+
+// ToDot formats the recording as a graphviz graph, with a node for each span
+// and edges from parents to children.
+func (r Recording) ToDot() string {
+	var buf bytes.Buffer
+	buf.WriteString("digraph recording {\n")
+	buf.WriteString("  node [shape=box];\n")
+	inRecording := make(map[uint64]bool, len(r))
+	for i := range r {
+		inRecording[r[i].SpanID] = true
+	}
+	r.walk(func(sp *RecordedSpan, _ int) {
+		label := fmt.Sprintf("%s\n%s", sp.Operation, formatSpanDuration(sp))
+		if tags := formatSpanTags(sp); tags != "" {
+			label += "\n" + tags
+		}
+		fmt.Fprintf(&buf, "  s%d [label=%s];\n", sp.SpanID, strconv.Quote(label))
+		if inRecording[sp.ParentSpanID] && sp.ParentSpanID != sp.SpanID {
+			fmt.Fprintf(&buf, "  s%d -> s%d;\n", sp.ParentSpanID, sp.SpanID)
+		}
+	})
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// Summary formats the recording as a tree in which children are sorted by
+// decreasing duration and annotated with their share of the parent's duration.
+// Children under a millisecond are collapsed into a single line.
+func (r Recording) Summary() string {
+	roots, children := r.tree()
+	var buf bytes.Buffer
+	visited := make([]bool, len(r))
+	// hide marks a collapsed span and its descendants as visited.
+	var hide func(i int)
+	hide = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		for _, c := range children[r[i].SpanID] {
+			hide(c)
+		}
+	}
+	// parentDuration is 0 for roots, or if the parent is unfinished.
+	var visit func(i, depth int, parentDuration time.Duration)
+	visit = func(i, depth int, parentDuration time.Duration) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		sp := &r[i]
+		buf.WriteString(strings.Repeat("    ", depth))
+		fmt.Fprintf(&buf, "%s: %s", sp.Operation, formatSpanDuration(sp))
+		if sp.Duration != 0 && parentDuration != 0 {
+			fmt.Fprintf(&buf, " (%s)", formatShare(sp.Duration, parentDuration))
+		}
+		if tags := formatSpanTags(sp); tags != "" {
+			fmt.Fprintf(&buf, " [%s]", tags)
+		}
+		buf.WriteByte('\n')
+
+		// Sort the children by decreasing duration, with the unfinished ones
+		// last; the short ones are then contiguous.
+		kids := append([]int(nil), children[sp.SpanID]...)
+		sort.SliceStable(kids, func(a, b int) bool {
+			da, db := r[kids[a]].Duration, r[kids[b]].Duration
+			if (da == 0) != (db == 0) {
+				return db == 0
+			}
+			return da > db
+		})
+		var collapsed int
+		var collapsedDuration time.Duration
+		flush := func() {
+			if collapsed == 0 {
+				return
+			}
+			buf.WriteString(strings.Repeat("    ", depth+1))
+			fmt.Fprintf(&buf, "%d spans under %s: %.3fms",
+				collapsed, summaryCollapseThreshold, 1000*collapsedDuration.Seconds())
+			if sp.Duration != 0 {
+				fmt.Fprintf(&buf, " (%s)", formatShare(collapsedDuration, sp.Duration))
+			}
+			buf.WriteByte('\n')
+			collapsed, collapsedDuration = 0, 0
+		}
+		for _, c := range kids {
+			if d := r[c].Duration; d != 0 && d < summaryCollapseThreshold && !visited[c] {
+				collapsed++
+				collapsedDuration += d
+				hide(c)
+				continue
+			}
+			flush()
+			visit(c, depth+1, sp.Duration)
+		}
+		flush()
+	}
+	for _, i := range roots {
+		visit(i, 0, 0)
+	}
+	// Spans that are part of a cycle (which can only happen with corrupted
+	// data) are not reachable from any root.
+	for i := range r {
+		visit(i, 0, 0)
+	}
+	return buf.String()
+}
+
+// walk calls fn for each span in the recording, in depth-first order; spans
+// whose parent is not part of the recording are considered roots. Children
+// are visited in the order in which they appear in the recording.
+func (r Recording) walk(fn func(sp *RecordedSpan, depth int)) {
+	roots, children := r.tree()
+	visited := make([]bool, len(r))
+	var visit func(i, depth int)
+	visit = func(i, depth int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		fn(&r[i], depth)
+		for _, c := range children[r[i].SpanID] {
+			visit(c, depth+1)
+		}
+	}
+	for _, i := range roots {
+		visit(i, 0)
+	}
+	// Spans that are part of a cycle (which can only happen with corrupted
+	// data) are not reachable from any root.
+	for i := range r {
+		visit(i, 0)
+	}
+}
+
+// tree returns the indexes of the roots of the recording (the spans whose
+// parent is not part of it) and the indexes of the children of each span, in
+// the order in which they appear in the recording.
+func (r Recording) tree() (roots []int, children map[uint64][]int) {
+	children = make(map[uint64][]int)
+	inRecording := make(map[uint64]bool, len(r))
+	for i := range r {
+		inRecording[r[i].SpanID] = true
+	}
+	for i := range r {
+		if p := r[i].ParentSpanID; inRecording[p] && p != r[i].SpanID {
+			children[p] = append(children[p], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+	return roots, children
+}
+
+func formatSpanDuration(sp *RecordedSpan) string {
+	if sp.Duration == 0 {
+		return "unfinished"
+	}
+	return fmt.Sprintf("%.3fms", 1000*sp.Duration.Seconds())
+}
+
+func formatSpanTags(sp *RecordedSpan) string {
+	if len(sp.Tags) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(sp.Tags))
+	for k := range sp.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		keys[i] = k + "=" + sp.Tags[k]
+	}
+	return strings.Join(keys, " ")
+}
+
+// formatShare formats d as a percentage of total.
+func formatShare(d, total time.Duration) string {
+	return fmt.Sprintf("%.1f%%", 100*float64(d)/float64(total))
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// gRPCComponentTag is the component tag of the spans of RPCs.
+var gRPCComponentTag = opentracing.Tag{Key: string(otext.Component), Value: "gRPC"}
+
+// rpcCarrier is the carrier from which the span contexts of incoming RPCs are
+// extracted. It gives BaggageAuthorizers access to the RPC's context.
+type rpcCarrier struct {
+	metadataCarrier
+	ctx context.Context
+}
+
+// IncomingRPCContext returns the context of the incoming RPC if the carrier
+// comes from one. BaggageAuthorizers can use it to authenticate the client.
+func IncomingRPCContext(carrier interface{}) (context.Context, bool) {
+	c, ok := carrier.(rpcCarrier)
+	if !ok {
+		return nil, false
+	}
+	return c.ctx, true
+}
+
+// ServerInterceptor returns a gRPC unary server interceptor which opens a span
+// for each RPC, continuing the trace propagated by the client (if any). The
+// server's timing is sent back to traced clients in the trailer; see
+// ClientInterceptor.
+func ServerInterceptor(tr *Tracer) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		received := time.Now()
+		md, _ := metadata.FromIncomingContext(ctx)
+		// Extract always returns a valid context, which is a noop context if
+		// there is nothing to extract.
+		wireContext, _ := tr.Extract(opentracing.TextMap, rpcCarrier{metadataCarrier(md), ctx})
+		sp := tr.StartSpan(info.FullMethod, otext.RPCServerOption(wireContext), gRPCComponentTag)
+		defer sp.Finish()
+		resp, err := handler(opentracing.ContextWithSpan(ctx, sp), req)
+		if err != nil {
+			otext.Error.Set(sp, true)
+			sp.LogKV("event", "error", "message", err.Error())
+		}
+		if _, noop := wireContext.(noopSpanContext); !noop {
+			trailer := metadata.MD{}
+			InjectServerTiming(ServerTiming{Received: received, Sent: time.Now()}, metadataCarrier(trailer))
+			// SetTrailer only fails if the RPC is already done, in which case the
+			// client doesn't need the timing.
+			_ = grpc.SetTrailer(ctx, trailer)
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a gRPC stream server interceptor which opens
+// a span for each stream that continues a trace propagated by the client. The
+// span contexts are extracted through an ExtractCache per client connection,
+// since the streams of a connection generally carry the same context.
+func StreamServerInterceptor(tr *Tracer) grpc.StreamServerInterceptor {
+	caches := &connExtractCaches{tracer: tr}
+	return func(
+		srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		ctx := ss.Context()
+		md, _ := metadata.FromIncomingContext(ctx)
+		wireContext, _ := caches.get(ctx).ExtractCached(
+			opentracing.TextMap, rpcCarrier{metadataCarrier(md), ctx})
+		if _, noop := wireContext.(noopSpanContext); noop || wireContext == nil {
+			// Streams are often long-lived; they are only traced on request.
+			return handler(srv, ss)
+		}
+		sp := tr.StartSpan(info.FullMethod, otext.RPCServerOption(wireContext), gRPCComponentTag)
+		defer sp.Finish()
+		err := handler(srv, tracedServerStream{ServerStream: ss, ctx: opentracing.ContextWithSpan(ctx, sp)})
+		if err != nil {
+			otext.Error.Set(sp, true)
+			sp.LogKV("event", "error", "message", err.Error())
+		}
+		return err
+	}
+}
+
+// tracedServerStream is a grpc.ServerStream whose context holds the span of
+// the stream.
+type tracedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context is part of the grpc.ServerStream interface.
+func (s tracedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// maxConnExtractCaches bounds the number of client connections for which an
+// ExtractCache is kept.
+const maxConnExtractCaches = 1024
+
+// connExtractCaches holds an ExtractCache per client connection, identified by
+// its remote address.
+type connExtractCaches struct {
+	tracer *Tracer
+
+	mu struct {
+		syncutil.Mutex
+		caches map[string]*ExtractCache
+	}
+}
+
+func (c *connExtractCaches) get(ctx context.Context) *ExtractCache {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return NewExtractCache(c.tracer)
+	}
+	key := p.Addr.String()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cache, ok := c.mu.caches[key]; ok {
+		return cache
+	}
+	if c.mu.caches == nil {
+		c.mu.caches = make(map[string]*ExtractCache)
+	}
+	if len(c.mu.caches) >= maxConnExtractCaches {
+		// Evict an arbitrary connection; it is most likely gone.
+		for k := range c.mu.caches {
+			delete(c.mu.caches, k)
+			break
+		}
+	}
+	cache := NewExtractCache(c.tracer)
+	c.mu.caches[key] = cache
+	return cache
+}
+
+// ClientInterceptor returns a gRPC unary client interceptor which opens an
+// RPCSpan for each RPC (see StartRPCSpan) and propagates the context of its
+// span, if any, to the server. The span is tagged with the breakdown of the
+// RPC's latency if the server sent its timing; see ServerInterceptor.
+func ClientInterceptor(tr *Tracer) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, resp interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		ctx, rpcSp := tr.StartRPCSpan(ctx, method)
+		if sp, ok := rpcSp.Span().(*span); ok {
+			otext.SpanKindRPCClient.Set(sp)
+			gRPCComponentTag.Set(sp)
+			md, _ := metadata.FromOutgoingContext(ctx)
+			md = md.Copy()
+			if err := tr.Inject(sp.Context(), opentracing.TextMap, metadataCarrier(md)); err == nil {
+				ctx = metadata.NewOutgoingContext(ctx, md)
+			}
+		}
+		var trailer metadata.MD
+		err := invoker(ctx, method, req, resp, cc, append(opts, grpc.Trailer(&trailer))...)
+		if st, ok, _ := ExtractServerTiming(metadataCarrier(trailer)); ok {
+			rpcSp.SetServerTiming(st)
+		}
+		rpcSp.Finish(err)
+		return err
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"net"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+type testRPCKey struct{}
+
+func TestServerInterceptor(t *testing.T) {
+	client := NewTracer()
+	server := NewTracer().(*Tracer)
+
+	sp := client.StartSpan("client", Recordable)
+	StartRecording(sp, SnowballRecording)
+	sp.SetBaggageItem(ForceTraceBaggage, "1")
+	md := metadata.MD{}
+	if err := client.Inject(sp.Context(), opentracing.TextMap, metadataCarrier(md)); err != nil {
+		t.Fatal(err)
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	ctx = context.WithValue(ctx, testRPCKey{}, "internal")
+
+	var authorized []string
+	server.SetBaggageAuthorizer(func(carrier interface{}, key, _ string) bool {
+		rpcCtx, ok := IncomingRPCContext(carrier)
+		if !ok {
+			t.Errorf("expected an RPC carrier, got %T", carrier)
+			return false
+		}
+		authorized = append(authorized, key)
+		return rpcCtx.Value(testRPCKey{}) == "internal" && key == Snowball
+	})
+
+	interceptor := ServerInterceptor(server)
+	info := &grpc.UnaryServerInfo{FullMethod: "/test/Method"}
+	if _, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		sp := opentracing.SpanFromContext(ctx)
+		if GetSpanTag(sp, string(otext.SpanKind)) != otext.SpanKindRPCServerEnum {
+			t.Errorf("expected a server span, got tags %v", GetSpanTags(sp))
+		}
+		if !sp.(*span).isRecording() {
+			t.Error("expected the server span to be recording")
+		}
+		if v := sp.BaggageItem(ForceTraceBaggage); v != "" {
+			t.Errorf("expected force baggage to be rejected, got %q", v)
+		}
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(authorized) != 2 {
+		t.Errorf("expected the authorizer to be called for 2 items, got %v", authorized)
+	}
+}
+
+func TestClientInterceptor(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	sp := tr.StartSpan("parent", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	ctx := opentracing.ContextWithSpan(context.Background(), sp)
+
+	interceptor := ClientInterceptor(tr)
+	if err := interceptor(ctx, "/test/Method", nil, nil, nil, func(
+		ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption,
+	) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		wireContext, err := tr.Extract(opentracing.TextMap, metadataCarrier(md))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sc, ok := wireContext.(*spanContext); !ok || sc.TraceID != sp.(*span).TraceID {
+			t.Errorf("expected the trace to be propagated, got %v", md)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sp.Finish()
+
+	rec := GetRecording(sp)
+	if len(rec) != 2 || rec[1].Operation != "/test/Method" ||
+		rec[1].Tags[string(otext.SpanKind)] != string(otext.SpanKindRPCClientEnum) {
+		t.Fatalf("expected a client span, got %v", rec)
+	}
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	client := NewTracer()
+	server := NewTracer().(*Tracer)
+	server.SetForceRealSpans(true)
+
+	sp := client.StartSpan("client", Recordable)
+	StartRecording(sp, SnowballRecording)
+	md := metadata.MD{}
+	if err := client.Inject(sp.Context(), opentracing.TextMap, metadataCarrier(md)); err != nil {
+		t.Fatal(err)
+	}
+	addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 26257}
+	ctx := peer.NewContext(metadata.NewIncomingContext(context.Background(), md), &peer.Peer{Addr: addr})
+
+	interceptor := StreamServerInterceptor(server)
+	info := &grpc.StreamServerInfo{FullMethod: "/test/Stream"}
+	for i := 0; i < 2; i++ {
+		if err := interceptor(nil, testServerStream{ctx: ctx}, info, func(
+			srv interface{}, ss grpc.ServerStream,
+		) error {
+			srvSp := opentracing.SpanFromContext(ss.Context()).(*span)
+			if srvSp.TraceID != sp.(*span).TraceID || !srvSp.isRecording() {
+				t.Errorf("expected a recording span continuing the trace, got %+v", srvSp)
+			}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Streams without a trace are not traced.
+	if err := interceptor(nil, testServerStream{ctx: context.Background()}, info, func(
+		srv interface{}, ss grpc.ServerStream,
+	) error {
+		if sp := opentracing.SpanFromContext(ss.Context()); sp != nil {
+			t.Errorf("unexpected span %v", sp)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The streams of a connection share an ExtractCache.
+	caches := &connExtractCaches{tracer: server}
+	other := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 2), Port: 26257},
+	})
+	if c1, c2, c3 := caches.get(ctx), caches.get(ctx), caches.get(other); c1 != c2 || c1 == c3 {
+		t.Error("expected one cache per connection")
+	}
+}
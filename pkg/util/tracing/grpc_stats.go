@@ -0,0 +1,175 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"strings"
+	"sync/atomic"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+)
+
+// Tags set by the GRPCStatsHandler when an RPC ends.
+const (
+	TagRPCMsgsSent     = "rpc.msgs_sent"
+	TagRPCMsgsReceived = "rpc.msgs_received"
+)
+
+// GRPCStatsHandler is a gRPC stats.Handler which opens a span for each RPC and
+// connection and annotates it with the lifecycle events reported by gRPC.
+// Client handlers only trace RPCs issued with a span in the context.
+type GRPCStatsHandler struct {
+	tracer opentracing.Tracer
+	client bool
+}
+
+var _ stats.Handler = &GRPCStatsHandler{}
+
+// NewGRPCClientStatsHandler returns a GRPCStatsHandler for client connections.
+func NewGRPCClientStatsHandler(tr opentracing.Tracer) *GRPCStatsHandler {
+	return &GRPCStatsHandler{tracer: tr, client: true}
+}
+
+// NewGRPCServerStatsHandler returns a GRPCStatsHandler for a server.
+func NewGRPCServerStatsHandler(tr opentracing.Tracer) *GRPCStatsHandler {
+	return &GRPCStatsHandler{tracer: tr}
+}
+
+// grpcSpanState is the state of a span opened by the GRPCStatsHandler.
+type grpcSpanState struct {
+	sp opentracing.Span
+	// Number of messages sent and received; accessed atomically, since a
+	// stream can be used concurrently for sending and receiving.
+	msgsSent, msgsRecv int64
+}
+
+type grpcRPCSpanKey struct{}
+
+type grpcConnSpanKey struct{}
+
+// TagRPC is part of the stats.Handler interface.
+func (h *GRPCStatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	var sp opentracing.Span
+	if h.client {
+		parent := opentracing.SpanFromContext(ctx)
+		if parent == nil || (IsBlackHoleSpan(parent) && !isCarrierSpan(parent)) {
+			return ctx
+		}
+		ctx, sp = ChildSpan(ctx, info.FullMethodName)
+		otext.SpanKindRPCClient.Set(sp)
+		md, _ := metadata.FromOutgoingContext(ctx)
+		md = md.Copy()
+		if err := h.tracer.Inject(sp.Context(), opentracing.HTTPHeaders, metadataCarrier(md)); err == nil {
+			ctx = metadata.NewOutgoingContext(ctx, md)
+		}
+	} else {
+		md, _ := metadata.FromIncomingContext(ctx)
+		wireContext, _ := h.tracer.Extract(opentracing.HTTPHeaders, metadataCarrier(md))
+		sp = h.tracer.StartSpan(info.FullMethodName, otext.RPCServerOption(wireContext))
+		ctx = opentracing.ContextWithSpan(ctx, sp)
+	}
+	return context.WithValue(ctx, grpcRPCSpanKey{}, &grpcSpanState{sp: sp})
+}
+
+// HandleRPC is part of the stats.Handler interface.
+func (h *GRPCStatsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	st, ok := ctx.Value(grpcRPCSpanKey{}).(*grpcSpanState)
+	if !ok || IsBlackHoleSpan(st.sp) {
+		return
+	}
+	switch s := s.(type) {
+	case *stats.OutHeader:
+		st.sp.LogKV("event", "headers sent")
+	case *stats.InHeader:
+		st.sp.LogKV("event", "headers received")
+	case *stats.OutPayload:
+		if atomic.AddInt64(&st.msgsSent, 1) == 1 {
+			st.sp.LogKV("event", "first message sent", "bytes", s.WireLength)
+		}
+	case *stats.InPayload:
+		if atomic.AddInt64(&st.msgsRecv, 1) == 1 {
+			st.sp.LogKV("event", "first message received", "bytes", s.WireLength)
+		}
+	case *stats.OutTrailer:
+		st.sp.LogKV("event", "trailers sent")
+	case *stats.InTrailer:
+		st.sp.LogKV("event", "trailers received")
+	case *stats.End:
+		st.sp.SetTag(TagRPCMsgsSent, atomic.LoadInt64(&st.msgsSent))
+		st.sp.SetTag(TagRPCMsgsReceived, atomic.LoadInt64(&st.msgsRecv))
+		if s.Error != nil {
+			otext.Error.Set(st.sp, true)
+			st.sp.LogKV("error", s.Error.Error())
+		}
+		st.sp.FinishWithOptions(opentracing.FinishOptions{FinishTime: s.EndTime})
+	}
+}
+
+// TagConn is part of the stats.Handler interface.
+func (h *GRPCStatsHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	sp := h.tracer.StartSpan("grpc conn")
+	if IsBlackHoleSpan(sp) {
+		return ctx
+	}
+	if info.RemoteAddr != nil {
+		sp.SetTag(string(otext.PeerHostname), info.RemoteAddr.String())
+	}
+	if h.client {
+		otext.SpanKindRPCClient.Set(sp)
+	} else {
+		otext.SpanKindRPCServer.Set(sp)
+	}
+	return context.WithValue(ctx, grpcConnSpanKey{}, sp)
+}
+
+// HandleConn is part of the stats.Handler interface.
+func (h *GRPCStatsHandler) HandleConn(ctx context.Context, s stats.ConnStats) {
+	sp, ok := ctx.Value(grpcConnSpanKey{}).(opentracing.Span)
+	if !ok {
+		return
+	}
+	switch s.(type) {
+	case *stats.ConnBegin:
+		sp.LogKV("event", "connection established")
+	case *stats.ConnEnd:
+		sp.Finish()
+	}
+}
+
+// metadataCarrier adapts gRPC metadata to the opentracing TextMap interfaces.
+type metadataCarrier metadata.MD
+
+// Set is part of the opentracing.TextMapWriter interface.
+func (c metadataCarrier) Set(key, val string) {
+	// gRPC metadata keys are lowercase.
+	key = strings.ToLower(key)
+	c[key] = append(c[key], val)
+}
+
+// ForeachKey is part of the opentracing.TextMapReader interface.
+func (c metadataCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, vals := range c {
+		for _, v := range vals {
+			if err := handler(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+)
+
+func TestGRPCStatsHandler(t *testing.T) {
+	tr := NewTracer()
+	tr2 := NewTracer()
+	client := NewGRPCClientStatsHandler(tr)
+	server := NewGRPCServerStatsHandler(tr2)
+	info := &stats.RPCTagInfo{FullMethodName: "/test/Stream"}
+
+	// Without a span in the context, the client doesn't trace the RPC.
+	if ctx := client.TagRPC(context.Background(), info); ctx.Value(grpcRPCSpanKey{}) != nil {
+		t.Fatal("unexpected client span")
+	}
+
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SnowballRecording)
+	clientCtx := client.TagRPC(opentracing.ContextWithSpan(context.Background(), root), info)
+	md, _ := metadata.FromOutgoingContext(clientCtx)
+	serverCtx := server.TagRPC(metadata.NewIncomingContext(context.Background(), md), info)
+
+	serverSp := opentracing.SpanFromContext(serverCtx)
+	if _, ok := serverSp.(*span); !ok {
+		t.Fatal("expected server span")
+	}
+	if serverSp.(*span).TraceID != root.(*span).TraceID {
+		t.Error("trace not propagated")
+	}
+
+	client.HandleRPC(clientCtx, &stats.Begin{Client: true, BeginTime: time.Now()})
+	client.HandleRPC(clientCtx, &stats.OutHeader{Client: true})
+	for i := 0; i < 3; i++ {
+		client.HandleRPC(clientCtx, &stats.OutPayload{Client: true, WireLength: 10})
+		server.HandleRPC(serverCtx, &stats.InPayload{WireLength: 10})
+	}
+	server.HandleRPC(serverCtx, &stats.OutTrailer{})
+	server.HandleRPC(serverCtx, &stats.End{EndTime: time.Now(), Error: errors.New("boom")})
+	client.HandleRPC(clientCtx, &stats.InTrailer{Client: true})
+	client.HandleRPC(clientCtx, &stats.End{Client: true, EndTime: time.Now()})
+
+	if err := TestingCheckRecordedSpans(GetRecording(root), `
+		span root:
+			tags: sb=1
+		span /test/Stream:
+			tags: rpc.msgs_received=0 rpc.msgs_sent=3 sb=1 span.kind=client
+			event: headers sent
+			event: first message sent  bytes: 10
+			event: trailers received
+	`); err != nil {
+		t.Fatal(err)
+	}
+	serverRec := GetRecording(serverSp)
+	if len(serverRec) != 1 || serverRec[0].Tags["error"] != "true" ||
+		serverRec[0].Tags[TagRPCMsgsReceived] != "3" {
+		t.Errorf("unexpected server recording: %+v", serverRec)
+	}
+	root.Finish()
+}
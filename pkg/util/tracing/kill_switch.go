@@ -0,0 +1,88 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	opentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// tracingEnabled is the kill switch of the tracer. When it is unset, only the
+// spans explicitly requested as real spans and the children of recording spans
+// are created,
+// and they are not sent to the shadow tracer, the event sink or the exporters.
+var tracingEnabled = settings.RegisterBoolSetting(
+	"trace.enabled",
+	"if unset, tracing is turned off, except for the spans explicitly requested as recordable "+
+		"(e.g. for SET TRACING); this overrides all the other trace.* settings",
+	true,
+)
+
+// forcesRealSpan returns true if the opentracing options contain the
+// Recordable option.
+func forcesRealSpan(opts []opentracing.StartSpanOption) bool {
+	for _, o := range opts {
+		if _, ok := o.(recordableOption); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isRecordingContext returns true if the span context belongs to a recording
+// span, or carries a snowball trace from another node.
+func isRecordingContext(sc opentracing.SpanContext) bool {
+	c, ok := sc.(*spanContext)
+	return ok && (c.recordingGroup != nil || c.Baggage[Snowball] != "")
+}
+
+// referencesRecording returns true if the opentracing options reference a
+// recording span context; see isRecordingContext.
+func referencesRecording(opts []opentracing.StartSpanOption) bool {
+	var sso opentracing.StartSpanOptions
+	for _, o := range opts {
+		if r, ok := o.(opentracing.SpanReference); ok {
+			if isRecordingContext(r.ReferencedContext) {
+				return true
+			}
+			continue
+		}
+		// Other options (e.g. ext.RPCServerOption) can add references too.
+		o.Apply(&sso)
+	}
+	for _, r := range sso.References {
+		if isRecordingContext(r.ReferencedContext) {
+			return true
+		}
+	}
+	return false
+}
+
+// disabledSpanOptions returns the options of a span started with Start when
+// the kill switch is off, or nil if the span should be a noop span.
+func disabledSpanOptions(opts []SpanOption) *spanOptions {
+	if len(opts) == 0 {
+		return nil
+	}
+	so := &spanOptions{}
+	for _, o := range opts {
+		o.apply(so)
+	}
+	if !so.forceReal && (so.parent == nil || !isRecordingContext(so.parent)) {
+		return nil
+	}
+	return so
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"reflect"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestKillSwitch(t *testing.T) {
+	defer settings.TestingSetFloat(&sampleRate, 1)()
+	var exported int
+	tr := NewTracerWithOptions(TracerOptions{
+		LatencyOperations: []string{"tracked"},
+		SpanExporters: []SpanExporter{SpanExporterFunc(func(spans []RecordedSpan) error {
+			exported += len(spans)
+			return nil
+		})},
+	}).(*Tracer)
+	c := NewTestCollector()
+	tr.SetTestCollector(c)
+	tr.SetForceRealSpans(true)
+
+	defer settings.TestingSetBool(&tracingEnabled, false)()
+
+	for _, sp := range []opentracing.Span{
+		tr.StartSpan("op"),
+		tr.StartSpan("op", ForceTrace),
+		tr.StartSpan("tracked"),
+		tr.Start("op"),
+	} {
+		if !IsBlackHoleSpan(sp) {
+			t.Errorf("expected a noop span, got %T", sp)
+		}
+	}
+
+	// Spans explicitly requested as real spans can still be recorded.
+	if IsBlackHoleSpan(tr.Start("op", WithForceReal(), WithRecording(SingleNodeRecording))) {
+		t.Error("expected a real span")
+	}
+	sp := tr.StartSpan("root", Recordable)
+	if child := StartChildSpan("child", sp, false /* separateRecording */); !IsBlackHoleSpan(child) {
+		t.Error("expected a noop child span")
+	}
+	StartRecording(sp, SingleNodeRecording)
+	child := StartChildSpan("child", sp, false /* separateRecording */)
+	if IsBlackHoleSpan(child) {
+		t.Error("expected the child of a recording span to be recorded")
+	}
+	StartChildSpan("tracked", child, false /* separateRecording */).Finish()
+	child.Finish()
+	sp.Finish()
+	var ops []string
+	for _, rs := range GetRecording(sp) {
+		ops = append(ops, rs.Operation)
+	}
+	if exp := []string{"root", "child", "tracked"}; !reflect.DeepEqual(ops, exp) {
+		t.Errorf("expected recorded spans %v, got %v", exp, ops)
+	}
+	if n := len(c.FinishedSpans()); n != 0 {
+		t.Errorf("expected no spans sent to the shadow tracer, got %d", n)
+	}
+	if exported != 0 {
+		t.Errorf("expected no exported spans, got %d", exported)
+	}
+	if s := tr.LatencySnapshot(); len(s) != 0 {
+		t.Errorf("expected no latency measurements, got %v", s)
+	}
+}
+
+func TestKillSwitchForkCtxSpan(t *testing.T) {
+	tr := NewTracer()
+	defer settings.TestingSetBool(&tracingEnabled, false)()
+
+	sp := tr.StartSpan("root", Recordable)
+	StartRecording(sp, SnowballRecording)
+	ctx := opentracing.ContextWithSpan(context.Background(), sp)
+	_, fork := ForkCtxSpan(ctx, "fork")
+	if IsBlackHoleSpan(fork) {
+		t.Fatal("expected the forked span of a recording span to be recorded")
+	}
+	fork.Finish()
+
+	// The children of remote recording spans are kept too.
+	carrier := make(opentracing.TextMapCarrier)
+	if err := tr.Inject(sp.Context(), opentracing.TextMap, carrier); err != nil {
+		t.Fatal(err)
+	}
+	wireContext, err := tr.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remote := tr.StartSpan("remote", opentracing.ChildOf(wireContext)); IsBlackHoleSpan(remote) {
+		t.Error("expected the child of a remote snowball span to be recorded")
+	}
+	sp.Finish()
+
+	var ops []string
+	for _, rs := range GetRecording(sp) {
+		ops = append(ops, rs.Operation)
+	}
+	if exp := []string{"root", "fork"}; !reflect.DeepEqual(ops, exp) {
+		t.Errorf("expected recorded spans %v, got %v", exp, ops)
+	}
+
+	// Spans referencing contexts that are not recording are still noop spans.
+	other := tr.StartSpan("other", Recordable)
+	if child := tr.StartSpan("child", opentracing.FollowsFrom(other.Context())); !IsBlackHoleSpan(child) {
+		t.Error("expected a noop span")
+	}
+}
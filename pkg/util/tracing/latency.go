@@ -0,0 +1,235 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/codahale/hdrhistogram"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// Parameters of the latency histograms maintained by the tracer. Durations
+// outside of [minTrackedLatency, maxTrackedLatency] are recorded as the closest
+// bound.
+const (
+	minTrackedLatency = int64(time.Microsecond)
+	maxTrackedLatency = int64(10 * time.Second)
+	latencySigFigs    = 2
+)
+
+// Exemplars are kept in power-of-two latency buckets: bucket i holds latencies
+// in [2^i, 2^(i+1)) microseconds (the last bucket is unbounded).
+const (
+	numExemplarBuckets    = 24
+	maxExemplarsPerBucket = 2
+)
+
+// Exemplar links a latency measurement to the trace in which it was made.
+type Exemplar struct {
+	Latency time.Duration
+	TraceID uint64
+}
+
+// exemplarBucket holds the most recent exemplars in a latency bucket.
+type exemplarBucket struct {
+	exemplars [maxExemplarsPerBucket]Exemplar
+	// next is the index of the slot to overwrite; n is the number of valid
+	// exemplars.
+	next, n int
+}
+
+func (b *exemplarBucket) add(e Exemplar) {
+	b.exemplars[b.next] = e
+	b.next = (b.next + 1) % maxExemplarsPerBucket
+	if b.n < maxExemplarsPerBucket {
+		b.n++
+	}
+}
+
+// appendTo appends the exemplars in the bucket to res, oldest first.
+func (b *exemplarBucket) appendTo(res []Exemplar) []Exemplar {
+	start := 0
+	if b.n == maxExemplarsPerBucket {
+		start = b.next
+	}
+	for i := 0; i < b.n; i++ {
+		res = append(res, b.exemplars[(start+i)%maxExemplarsPerBucket])
+	}
+	return res
+}
+
+// exemplarBucketIdx returns the index of the exemplar bucket for a latency.
+func exemplarBucketIdx(d time.Duration) int {
+	i := 0
+	for v := int64(d) / minTrackedLatency; v > 1 && i < numExemplarBuckets-1; v >>= 1 {
+		i++
+	}
+	return i
+}
+
+// OperationLatency is a snapshot of the latency statistics of an operation.
+type OperationLatency struct {
+	// Latency is a histogram of the durations of the operation, in nanoseconds.
+	Latency *hdrhistogram.Histogram
+	// Errors is the number of operations that failed.
+	Errors int64
+	// Exemplars link the most recent measurements of the root spans of
+	// retained traces to their trace IDs. Sorted by latency.
+	Exemplars []Exemplar
+}
+
+// ExemplarAt returns an exemplar for the given quantile (e.g. 0.99) of the
+// latency distribution: an exemplar in the same latency range as the quantile
+// if possible, or else the exemplar with the closest higher latency. Returns
+// false if there is no such exemplar.
+func (l OperationLatency) ExemplarAt(quantile float64) (Exemplar, bool) {
+	if l.Latency == nil || len(l.Exemplars) == 0 {
+		return Exemplar{}, false
+	}
+	v := time.Duration(l.Latency.ValueAtQuantile(quantile * 100))
+	bucket := exemplarBucketIdx(v)
+	for _, e := range l.Exemplars {
+		if exemplarBucketIdx(e.Latency) >= bucket {
+			return e, true
+		}
+	}
+	return Exemplar{}, false
+}
+
+// opLatency accumulates the latency statistics of an operation.
+type opLatency struct {
+	syncutil.Mutex
+	hist      *hdrhistogram.Histogram
+	errors    int64
+	exemplars [numExemplarBuckets]exemplarBucket
+}
+
+// latencyStore maintains latency histograms for a set of operations.
+type latencyStore struct {
+	mu struct {
+		syncutil.Mutex
+		ops map[string]*opLatency
+	}
+}
+
+func (ls *latencyStore) get(operation string) *opLatency {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	l, ok := ls.mu.ops[operation]
+	if !ok {
+		if ls.mu.ops == nil {
+			ls.mu.ops = make(map[string]*opLatency)
+		}
+		l = &opLatency{hist: hdrhistogram.New(minTrackedLatency, maxTrackedLatency, latencySigFigs)}
+		ls.mu.ops[operation] = l
+	}
+	return l
+}
+
+// record adds an operation with the given duration to the statistics. If
+// traceID is not zero, it is kept as an exemplar for the duration.
+func (ls *latencyStore) record(operation string, d time.Duration, failed bool, traceID uint64) {
+	v := int64(d)
+	if v < minTrackedLatency {
+		v = minTrackedLatency
+	} else if v > maxTrackedLatency {
+		v = maxTrackedLatency
+	}
+	l := ls.get(operation)
+	l.Lock()
+	_ = l.hist.RecordValue(v)
+	if failed {
+		l.errors++
+	}
+	if traceID != 0 {
+		l.exemplars[exemplarBucketIdx(d)].add(Exemplar{Latency: d, TraceID: traceID})
+	}
+	l.Unlock()
+}
+
+// snapshot returns a copy of the statistics of all the operations.
+func (ls *latencyStore) snapshot() map[string]OperationLatency {
+	ls.mu.Lock()
+	ops := make(map[string]*opLatency, len(ls.mu.ops))
+	for op, l := range ls.mu.ops {
+		ops[op] = l
+	}
+	ls.mu.Unlock()
+
+	res := make(map[string]OperationLatency, len(ops))
+	for op, l := range ops {
+		l.Lock()
+		ol := OperationLatency{
+			Latency: hdrhistogram.Import(l.hist.Export()),
+			Errors:  l.errors,
+		}
+		for i := range l.exemplars {
+			ol.Exemplars = l.exemplars[i].appendTo(ol.Exemplars)
+		}
+		l.Unlock()
+		sort.SliceStable(ol.Exemplars, func(i, j int) bool {
+			return ol.Exemplars[i].Latency < ol.Exemplars[j].Latency
+		})
+		res[op] = ol
+	}
+	return res
+}
+
+// tracksLatency returns true if the durations of the spans for the given
+// operation are tracked; see TracerOptions.LatencyOperations.
+func (t *Tracer) tracksLatency(operation string) bool {
+	if t.latencyOps == nil || !tracingEnabled.Get() {
+		return false
+	}
+	_, ok := t.latencyOps[operation]
+	return ok
+}
+
+// LatencySnapshot returns the latency statistics of the spans for the
+// operations listed in TracerOptions.LatencyOperations, by operation.
+// Operations for which no span has finished yet are omitted.
+func (t *Tracer) LatencySnapshot() map[string]OperationLatency {
+	return t.spanLatencies.snapshot()
+}
+
+// recordLatency adds the span's duration to the latency statistics of its
+// operation if it is tracked. Called when the span finishes.
+func (s *span) recordLatency() {
+	if !s.tracked {
+		return
+	}
+	s.tracer.spanLatencies.record(
+		s.operation, s.duration(), atomic.LoadInt32(&s.failed) != 0, s.exemplarTraceID(),
+	)
+}
+
+// exemplarTraceID returns the span's trace ID if it is suitable as an
+// exemplar, i.e. if the span is the root of a trace that can be looked up: a
+// trace sent to the shadow tracer or whose recording was retained.
+func (s *span) exemplarTraceID() uint64 {
+	if s.parentSpanID != 0 || (atomic.LoadInt32(&s.retained) == 0 && s.shadowTr == nil) {
+		return 0
+	}
+	return s.TraceID
+}
+
+// markRetained records that the span's recording was kept or exported.
+func (s *span) markRetained() {
+	atomic.StoreInt32(&s.retained, 1)
+}
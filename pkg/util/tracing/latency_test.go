@@ -0,0 +1,151 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestLatencySnapshot(t *testing.T) {
+	tr := NewTracerWithOptions(TracerOptions{
+		LatencyOperations: []string{"tracked"},
+	}).(*Tracer)
+
+	start := time.Now()
+	sp := tr.StartSpan("tracked", opentracing.StartTime(start))
+	// Tracked spans are real spans, even though tracing is disabled.
+	if _, noop := sp.(*noopSpan); noop {
+		t.Fatal("expected a real span")
+	}
+	sp.FinishWithOptions(opentracing.FinishOptions{FinishTime: start.Add(5 * time.Millisecond)})
+
+	// Children of noop spans are tracked too.
+	noop := tr.StartSpan("untracked")
+	if _, ok := noop.(*noopSpan); !ok {
+		t.Fatal("expected a noop span")
+	}
+	_, child := ChildSpan(opentracing.ContextWithSpan(context.Background(), noop), "tracked")
+	otext.Error.Set(child, true)
+	child.Finish()
+
+	snap := tr.LatencySnapshot()
+	if len(snap) != 1 {
+		t.Fatalf("expected a single operation, got %v", snap)
+	}
+	l := snap["tracked"]
+	if n := l.Latency.TotalCount(); n != 2 {
+		t.Fatalf("expected 2 spans, got %d", n)
+	}
+	if max := time.Duration(l.Latency.Max()); max < 5*time.Millisecond {
+		t.Errorf("expected a max latency of at least 5ms, got %s", max)
+	}
+	if l.Errors != 1 {
+		t.Errorf("expected 1 error, got %d", l.Errors)
+	}
+}
+
+func TestLatencyMeasureOnly(t *testing.T) {
+	defer settings.TestingSetBool(&propagateTraceIDs, true)()
+	tr := NewTracerWithOptions(TracerOptions{
+		LatencyOperations: []string{"tracked"},
+	}).(*Tracer)
+
+	sp := tr.StartSpan("tracked")
+	if _, noop := sp.(*noopSpan); noop {
+		t.Fatal("expected a real span")
+	}
+	// The span is not sampled, so its children are noop spans.
+	for _, child := range []opentracing.Span{
+		tr.StartSpan("child", opentracing.ChildOf(sp.Context())),
+		StartChildSpan("child", sp, false /* separateRecording */),
+	} {
+		if _, noop := child.(*noopSpan); !noop {
+			t.Errorf("expected a noop child, got %T", child)
+		}
+	}
+	// Once it is recording, they are part of the recording.
+	StartRecording(sp, SingleNodeRecording)
+	child := tr.StartSpan("child", opentracing.ChildOf(sp.Context()))
+	if _, noop := child.(*noopSpan); noop {
+		t.Error("expected a real child")
+	}
+	child.Finish()
+	sp.Finish()
+}
+
+func TestLatencyExemplars(t *testing.T) {
+	var ls latencyStore
+	for i := 0; i < 100; i++ {
+		ls.record("op", time.Millisecond, false, 0)
+	}
+	// Only the most recent exemplars are kept for each latency range.
+	for i := 1; i <= 3; i++ {
+		ls.record("op", 100*time.Millisecond, false, uint64(i))
+	}
+	ls.record("op", time.Millisecond, false, 10)
+
+	l := ls.snapshot()["op"]
+	exp := []Exemplar{
+		{Latency: time.Millisecond, TraceID: 10},
+		{Latency: 100 * time.Millisecond, TraceID: 2},
+		{Latency: 100 * time.Millisecond, TraceID: 3},
+	}
+	if len(l.Exemplars) != len(exp) {
+		t.Fatalf("expected %v, got %v", exp, l.Exemplars)
+	}
+	for i := range exp {
+		if l.Exemplars[i] != exp[i] {
+			t.Fatalf("expected %v, got %v", exp, l.Exemplars)
+		}
+	}
+
+	if e, ok := l.ExemplarAt(0.5); !ok || e.TraceID != 10 {
+		t.Errorf("unexpected p50 exemplar %v (%t)", e, ok)
+	}
+	if e, ok := l.ExemplarAt(0.999); !ok || e.Latency != 100*time.Millisecond {
+		t.Errorf("unexpected p99.9 exemplar %v (%t)", e, ok)
+	}
+	ls.record("op", 5*time.Second, false, 0)
+	if e, ok := ls.snapshot()["op"].ExemplarAt(1); ok {
+		t.Errorf("unexpected exemplar %v", e)
+	}
+
+	// Root spans of tracked operations whose recordings are retained (here,
+	// because they failed) provide exemplars; the others don't.
+	tr := NewTracerWithOptions(TracerOptions{
+		LatencyOperations: []string{"tracked"},
+	}).(*Tracer)
+	sp := tr.StartSpan("tracked")
+	StartRecording(sp, SingleNodeRecording)
+	otext.Error.Set(sp, true)
+	sp.Finish()
+	recorded := tr.StartSpan("tracked")
+	StartRecording(recorded, SingleNodeRecording)
+	recorded.Finish()
+	untraced := tr.StartSpan("tracked")
+	untraced.Finish()
+	ex := tr.LatencySnapshot()["tracked"].Exemplars
+	if len(ex) != 1 || ex[0].TraceID != sp.(*span).TraceID {
+		t.Errorf("unexpected exemplars %v", ex)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"golang.org/x/net/context"
+)
+
+// LeveledLogger is the logger interface used by several vendored libraries;
+// in particular, it is the same as raft.Logger.
+type LeveledLogger interface {
+	Debug(v ...interface{})
+	Debugf(format string, v ...interface{})
+	Info(v ...interface{})
+	Infof(format string, v ...interface{})
+	Warning(v ...interface{})
+	Warningf(format string, v ...interface{})
+	Error(v ...interface{})
+	Errorf(format string, v ...interface{})
+	Fatal(v ...interface{})
+	Fatalf(format string, v ...interface{})
+	Panic(v ...interface{})
+	Panicf(format string, v ...interface{})
+}
+
+// LibraryLogger is a logger for third-party libraries (raft, grpc) which
+// records their messages in the span of a context and forwards them to another
+// logger.
+type LibraryLogger struct {
+	ctx  context.Context
+	next LeveledLogger
+}
+
+var _ LeveledLogger = &LibraryLogger{}
+
+// NewLibraryLogger creates a LibraryLogger that records messages in the span of
+// the given context (if any) and forwards them to next.
+func NewLibraryLogger(ctx context.Context, next LeveledLogger) *LibraryLogger {
+	return &LibraryLogger{ctx: ctx, next: next}
+}
+
+// record logs the message in the span of the context, if it is a real span.
+// The message is only formatted if it is needed.
+func (l *LibraryLogger) record(level string, format string, v []interface{}) {
+	sp := opentracing.SpanFromContext(l.ctx)
+	if sp == nil || IsBlackHoleSpan(sp) {
+		return
+	}
+	var msg string
+	if format == "" {
+		msg = fmt.Sprint(v...)
+	} else {
+		msg = fmt.Sprintf(format, v...)
+	}
+	sp.LogFields(otlog.String("event", level+": "+msg))
+}
+
+// Debug is part of the LeveledLogger interface.
+func (l *LibraryLogger) Debug(v ...interface{}) {
+	l.record("debug", "", v)
+	l.next.Debug(v...)
+}
+
+// Debugf is part of the LeveledLogger interface.
+func (l *LibraryLogger) Debugf(format string, v ...interface{}) {
+	l.record("debug", format, v)
+	l.next.Debugf(format, v...)
+}
+
+// Info is part of the LeveledLogger interface.
+func (l *LibraryLogger) Info(v ...interface{}) {
+	l.record("info", "", v)
+	l.next.Info(v...)
+}
+
+// Infof is part of the LeveledLogger interface.
+func (l *LibraryLogger) Infof(format string, v ...interface{}) {
+	l.record("info", format, v)
+	l.next.Infof(format, v...)
+}
+
+// Warning is part of the LeveledLogger interface.
+func (l *LibraryLogger) Warning(v ...interface{}) {
+	l.record("warning", "", v)
+	l.next.Warning(v...)
+}
+
+// Warningf is part of the LeveledLogger interface.
+func (l *LibraryLogger) Warningf(format string, v ...interface{}) {
+	l.record("warning", format, v)
+	l.next.Warningf(format, v...)
+}
+
+// Error is part of the LeveledLogger interface.
+func (l *LibraryLogger) Error(v ...interface{}) {
+	l.record("error", "", v)
+	l.next.Error(v...)
+}
+
+// Errorf is part of the LeveledLogger interface.
+func (l *LibraryLogger) Errorf(format string, v ...interface{}) {
+	l.record("error", format, v)
+	l.next.Errorf(format, v...)
+}
+
+// Fatal is part of the LeveledLogger interface.
+func (l *LibraryLogger) Fatal(v ...interface{}) {
+	l.record("fatal", "", v)
+	l.next.Fatal(v...)
+}
+
+// Fatalf is part of the LeveledLogger interface.
+func (l *LibraryLogger) Fatalf(format string, v ...interface{}) {
+	l.record("fatal", format, v)
+	l.next.Fatalf(format, v...)
+}
+
+// Panic is part of the LeveledLogger interface.
+func (l *LibraryLogger) Panic(v ...interface{}) {
+	l.record("panic", "", v)
+	l.next.Panic(v...)
+}
+
+// Panicf is part of the LeveledLogger interface.
+func (l *LibraryLogger) Panicf(format string, v ...interface{}) {
+	l.record("panic", format, v)
+	l.next.Panicf(format, v...)
+}
+
+// The grpclog.Logger methods forward to next directly, so that next sees the
+// same number of frames for every message.
+
+// Fatalln is part of the grpclog.Logger interface.
+func (l *LibraryLogger) Fatalln(v ...interface{}) {
+	l.record("fatal", "", v)
+	l.next.Fatal(v...)
+}
+
+// Print is part of the grpclog.Logger interface.
+func (l *LibraryLogger) Print(v ...interface{}) {
+	l.record("info", "", v)
+	l.next.Info(v...)
+}
+
+// Printf is part of the grpclog.Logger interface.
+func (l *LibraryLogger) Printf(format string, v ...interface{}) {
+	l.record("info", format, v)
+	l.next.Infof(format, v...)
+}
+
+// Println is part of the grpclog.Logger interface.
+func (l *LibraryLogger) Println(v ...interface{}) {
+	l.record("info", "", v)
+	l.next.Info(v...)
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+)
+
+// testLeveledLogger collects the messages it receives.
+type testLeveledLogger struct {
+	LeveledLogger
+	msgs []string
+}
+
+func (l *testLeveledLogger) Infof(format string, v ...interface{}) {
+	l.msgs = append(l.msgs, fmt.Sprintf(format, v...))
+}
+
+func (l *testLeveledLogger) Warning(v ...interface{}) {
+	l.msgs = append(l.msgs, fmt.Sprint(v...))
+}
+
+func TestLibraryLogger(t *testing.T) {
+	next := &testLeveledLogger{}
+
+	// Without a span, messages are only forwarded.
+	NewLibraryLogger(context.Background(), next).Infof("no span %d", 1)
+
+	tr := NewTracer()
+	sp := tr.StartSpan("a", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	l := NewLibraryLogger(opentracing.ContextWithSpan(context.Background(), sp), next)
+	l.Printf("became leader at term %d", 5)
+	l.Warning("slow ", "append")
+	sp.Finish()
+
+	if err := TestingCheckRecordedSpans(GetRecording(sp), `
+		span a:
+			event: info: became leader at term 5
+			event: warning: slow append
+	`); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "[no span 1 became leader at term 5 slow append]"; fmt.Sprint(next.msgs) != exp {
+		t.Fatalf("expected %s, got %s", exp, next.msgs)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import "regexp"
+
+// FieldLocation is the key of the log record field holding the file:line of the
+// code that logged the event.
+const FieldLocation = "location"
+
+// bakedLocationRE matches the events of older recordings, in which the
+// location was prepended to the message.
+var bakedLocationRE = regexp.MustCompile(`^([^ ]+\.go:[0-9]+) (.*)$`)
+
+// Location returns the location of the code that logged the record, or an
+// empty string if it is unknown. Besides the FieldLocation field, it
+// recognizes the locations prepended to the "event" field by older versions.
+func (l *RecordedSpan_LogRecord) Location() string {
+	for _, f := range l.Fields {
+		if f.Key == FieldLocation {
+			return f.Value
+		}
+	}
+	for _, f := range l.Fields {
+		if f.Key == "event" {
+			if m := bakedLocationRE.FindStringSubmatch(f.Value); m != nil {
+				return m[1]
+			}
+		}
+	}
+	return ""
+}
+
+// FormatOption is an option for FormatRecordedSpans.
+type FormatOption interface {
+	apply(*formatOptions)
+}
+
+type formatOptions struct {
+	locations      bool
+	locationPrefix string
+}
+
+type locationsOption string
+
+func (o locationsOption) apply(opts *formatOptions) {
+	opts.locations = true
+	opts.locationPrefix = string(o)
+}
+
+// WithLocations is a FormatRecordedSpans option which renders the location of
+// each event, prefixed with prefix.
+func WithLocations(prefix string) FormatOption {
+	return locationsOption(prefix)
+}
+
+// splitLocation separates the location from the fields of a log record: the
+// FieldLocation field is removed, and a location prepended to the "event"
+// field (by older versions) is stripped from the message. The returned fields
+// are a copy if they had to be modified.
+func splitLocation(
+	fields []RecordedSpan_LogRecord_Field,
+) (loc string, res []RecordedSpan_LogRecord_Field) {
+	res = fields
+	copied := false
+	for i := 0; i < len(res); i++ {
+		f := res[i]
+		switch f.Key {
+		case FieldLocation:
+			loc = f.Value
+		case "event":
+			m := bakedLocationRE.FindStringSubmatch(f.Value)
+			if m == nil {
+				continue
+			}
+			loc = m[1]
+			f.Value = m[2]
+		default:
+			continue
+		}
+		if !copied {
+			res = append([]RecordedSpan_LogRecord_Field(nil), res...)
+			copied = true
+		}
+		if f.Key == FieldLocation {
+			res = append(res[:i], res[i+1:]...)
+			i--
+		} else {
+			res[i] = f
+		}
+	}
+	return loc, res
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"strings"
+	"testing"
+
+	otlog "github.com/opentracing/opentracing-go/log"
+)
+
+func TestLocations(t *testing.T) {
+	tr := NewTracer()
+	sp := tr.StartSpan("s", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	sp.LogFields(otlog.String("event", "structured"), otlog.String(FieldLocation, "util/foo.go:12"))
+	sp.LogFields(otlog.String("event", "util/bar.go:34 baked"))
+	sp.LogFields(otlog.String("event", "none"))
+	sp.Finish()
+
+	rec := GetRecording(sp)
+	if err := TestingCheckRecordedSpans(rec, `
+		span s:
+		  event: structured
+		  event: util/bar.go:34 baked
+		  event: none
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, exp := range []string{"util/foo.go:12", "util/bar.go:34", ""} {
+		if loc := rec[0].Logs[i].Location(); loc != exp {
+			t.Errorf("%d: expected location %q, got %q", i, exp, loc)
+		}
+	}
+
+	s := FormatRecordedSpans(rec)
+	if strings.Contains(s, ".go:") {
+		t.Errorf("expected no locations, got:\n%s", s)
+	}
+	for _, exp := range []string{"event:structured\n", "event:baked\n", "event:none\n"} {
+		if !strings.Contains(s, exp) {
+			t.Errorf("expected %q in:\n%s", exp, s)
+		}
+	}
+
+	s = FormatRecordedSpans(rec, WithLocations("pkg/"))
+	for _, exp := range []string{
+		"event:structured  pkg/util/foo.go:12\n",
+		"event:baked  pkg/util/bar.go:34\n",
+		"event:none\n",
+	} {
+		if !strings.Contains(s, exp) {
+			t.Errorf("expected %q in:\n%s", exp, s)
+		}
+	}
+
+	// The recording itself must not have been modified.
+	if len(rec[0].Logs[0].Fields) != 2 || rec[0].Logs[1].Fields[0].Value != "util/bar.go:34 baked" {
+		t.Errorf("recording was modified: %+v", rec[0].Logs)
+	}
+}
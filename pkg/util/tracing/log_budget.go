@@ -0,0 +1,90 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// LogBudget is the baggage item carrying the number of verbose log messages a
+// recording may still capture. Inject hands over half of the remaining local
+// budget to the remote node.
+const LogBudget = "lb"
+
+// SetLogBudget sets the verbose log budget for the recording of the span (and
+// of the spans on other nodes that become part of it through snowball
+// tracing). It must be called before child spans are created.
+func SetLogBudget(os opentracing.Span, budget int64) {
+	sp, ok := os.(*span)
+	if !ok {
+		return
+	}
+	v := strconv.FormatInt(budget, 10)
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.setBaggageItemLocked(LogBudget, v)
+	if group := sp.mu.recordingGroup; group != nil {
+		group.initLogBudget(v)
+	}
+}
+
+// logBudget is embedded in spanGroup.
+type logBudget struct {
+	// Atomic flag set if the recording has a budget.
+	budgeted int32
+	// remaining is the local budget; accessed atomically.
+	remaining int64
+}
+
+// initLogBudget sets the budget from the value of the LogBudget baggage item.
+// Malformed values are ignored.
+func (b *logBudget) initLogBudget(v string) {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return
+	}
+	atomic.StoreInt64(&b.remaining, n)
+	atomic.StoreInt32(&b.budgeted, 1)
+}
+
+// consumeLogBudget returns false if a message cannot be captured because the
+// budget is exhausted. The receiver can be nil.
+func (b *logBudget) consumeLogBudget() bool {
+	if b == nil || atomic.LoadInt32(&b.budgeted) == 0 {
+		return true
+	}
+	return atomic.AddInt64(&b.remaining, -1) >= 0
+}
+
+// takeLogBudgetShare removes half of the remaining budget, to be handed over
+// to another node. Returns false if the recording has no budget.
+func (b *logBudget) takeLogBudgetShare() (int64, bool) {
+	if atomic.LoadInt32(&b.budgeted) == 0 {
+		return 0, false
+	}
+	for {
+		cur := atomic.LoadInt64(&b.remaining)
+		if cur <= 0 {
+			return 0, true
+		}
+		share := (cur + 1) / 2
+		if atomic.CompareAndSwapInt64(&b.remaining, cur, cur-share) {
+			return share, true
+		}
+	}
+}
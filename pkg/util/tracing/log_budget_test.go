@@ -0,0 +1,70 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestLogBudget(t *testing.T) {
+	tr := NewTracer()
+	tr2 := NewTracer()
+
+	root := tr.StartSpan("root", Recordable)
+	SetLogBudget(root, 10)
+	StartRecording(root, SnowballRecording)
+
+	// Hand over a share of the budget to a "remote" node.
+	carrier := make(opentracing.TextMapCarrier)
+	if err := tr.Inject(root.Context(), opentracing.TextMap, carrier); err != nil {
+		t.Fatal(err)
+	}
+	if v := carrier[prefixBaggage+LogBudget]; v != "5" {
+		t.Fatalf("expected a budget share of 5, got %q", v)
+	}
+	wireContext, err := tr2.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := tr2.StartSpan("remote", opentracing.ChildOf(wireContext))
+	for i := 0; i < 10; i++ {
+		remote.LogKV("event", "remote")
+	}
+	remote.Finish()
+	if n := len(GetRecording(remote)[0].Logs); n != 5 {
+		t.Fatalf("expected 5 remote messages, got %d", n)
+	}
+
+	// The local budget is what remains.
+	for i := 0; i < 10; i++ {
+		Recordf(root, "local %d", i)
+	}
+	root.Finish()
+	if n := len(GetRecording(root)[0].Logs); n != 5 {
+		t.Fatalf("expected 5 local messages, got %d", n)
+	}
+
+	// Without a budget, nothing is limited.
+	sp := tr.StartSpan("a", Recordable)
+	StartRecording(sp, SnowballRecording)
+	for i := 0; i < 20; i++ {
+		sp.LogKV("event", "x")
+	}
+	if n := len(GetRecording(sp)[0].Logs); n != 20 {
+		t.Fatalf("expected 20 messages, got %d", n)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// maintenanceTick is the granularity at which maintenance tasks are run.
+var maintenanceTick = time.Second
+
+// Stopper is the subset of stop.Stopper used to run the Tracer's background
+// work (the stop package depends on this package, so it can't be used
+// directly).
+type Stopper interface {
+	RunWorker(ctx context.Context, f func(context.Context))
+	ShouldQuiesce() <-chan struct{}
+}
+
+// maintenanceTask is periodic background work registered with
+// AddMaintenanceTask.
+type maintenanceTask struct {
+	name     string
+	interval time.Duration
+	fn       func(context.Context)
+	next     time.Time
+}
+
+// maintenance holds the state of the Tracer's background work. The rest of the
+// Tracer doesn't need it: exporters are called synchronously by Finish, the
+// recent traces buffer evicts on insertion and settings are read on use or
+// through OnChange.
+type maintenance struct {
+	syncutil.Mutex
+	tasks []*maintenanceTask
+	// stop is closed by Close to stop the worker; done is closed by the worker
+	// when it exits. done is nil if the worker was never started.
+	stop, done chan struct{}
+	closed     bool
+}
+
+// AddMaintenanceTask registers fn to be run every interval by the worker
+// started by StartMaintenance. Tasks run sequentially and should not block.
+func (t *Tracer) AddMaintenanceTask(name string, interval time.Duration, fn func(context.Context)) {
+	t.maintenance.Lock()
+	defer t.maintenance.Unlock()
+	t.maintenance.tasks = append(t.maintenance.tasks, &maintenanceTask{
+		name:     name,
+		interval: interval,
+		fn:       fn,
+		next:     time.Now().Add(interval),
+	})
+}
+
+// StartMaintenance starts the worker running the maintenance tasks under the
+// given stopper. The worker exits when the stopper quiesces or when the Tracer
+// is closed. Subsequent calls have no effect.
+func (t *Tracer) StartMaintenance(stopper Stopper) {
+	t.maintenance.Lock()
+	defer t.maintenance.Unlock()
+	if t.maintenance.done != nil || t.maintenance.closed {
+		return
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	t.maintenance.stop, t.maintenance.done = stop, done
+	stopper.RunWorker(context.Background(), func(ctx context.Context) {
+		defer close(done)
+		ticker := time.NewTicker(maintenanceTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				t.runMaintenance(ctx, now)
+			case <-stop:
+				return
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
+// runMaintenance runs the tasks that are due.
+func (t *Tracer) runMaintenance(ctx context.Context, now time.Time) {
+	t.maintenance.Lock()
+	var due []*maintenanceTask
+	for _, task := range t.maintenance.tasks {
+		if !now.Before(task.next) {
+			task.next = now.Add(task.interval)
+			due = append(due, task)
+		}
+	}
+	t.maintenance.Unlock()
+	for _, task := range due {
+		task.fn(ctx)
+	}
+}
+
+// stopMaintenance stops the worker (if running) and waits for it to exit.
+// Returns false if it was already called.
+func (t *Tracer) stopMaintenance() bool {
+	t.maintenance.Lock()
+	first := !t.maintenance.closed
+	if first {
+		t.maintenance.closed = true
+		if t.maintenance.stop != nil {
+			close(t.maintenance.stop)
+		}
+	}
+	done := t.maintenance.done
+	t.maintenance.Unlock()
+	if done != nil {
+		<-done
+	}
+	return first
+}
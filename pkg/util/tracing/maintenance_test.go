@@ -0,0 +1,75 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// testStopper is a minimal Stopper.
+type testStopper struct {
+	wg      sync.WaitGroup
+	quiesce chan struct{}
+}
+
+func (s *testStopper) RunWorker(ctx context.Context, f func(context.Context)) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		f(ctx)
+	}()
+}
+
+func (s *testStopper) ShouldQuiesce() <-chan struct{} {
+	return s.quiesce
+}
+
+func TestMaintenance(t *testing.T) {
+	defer func(tick time.Duration) { maintenanceTick = tick }(maintenanceTick)
+	maintenanceTick = time.Millisecond
+
+	t.Run("close", func(t *testing.T) {
+		tr := NewTracer().(*Tracer)
+		ran := make(chan struct{}, 1)
+		tr.AddMaintenanceTask("test", time.Millisecond, func(context.Context) {
+			select {
+			case ran <- struct{}{}:
+			default:
+			}
+		})
+		stopper := &testStopper{quiesce: make(chan struct{})}
+		tr.StartMaintenance(stopper)
+		tr.StartMaintenance(stopper)
+		<-ran
+
+		// Close stops the worker and can be called again.
+		tr.Close()
+		tr.Close()
+		stopper.wg.Wait()
+	})
+
+	t.Run("quiesce", func(t *testing.T) {
+		tr := NewTracer().(*Tracer)
+		stopper := &testStopper{quiesce: make(chan struct{})}
+		tr.StartMaintenance(stopper)
+		close(stopper.quiesce)
+		stopper.wg.Wait()
+		tr.Close()
+	})
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sync/atomic"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// RecordingFidelity determines what a named recording captures.
+type RecordingFidelity int
+
+const (
+	// VerboseFidelity captures the spans, their tags and their log messages.
+	VerboseFidelity RecordingFidelity = iota
+	// StructuralFidelity captures the spans and their tags, but no log
+	// messages; it is meant for always-on recordings.
+	StructuralFidelity
+)
+
+// Values of span.namedState.
+const (
+	namedNone int32 = iota
+	namedStructural
+	namedVerbose
+)
+
+// namedRecording is a recording started with StartNamedRecording.
+type namedRecording struct {
+	name  string
+	group *spanGroup
+}
+
+// StartNamedRecording starts a recording on the span which is independent of
+// StartRecording and of other named recordings. Like with StartRecording, local
+// child spans started from now on are part of it. An existing recording with
+// the same name is replaced.
+func StartNamedRecording(os opentracing.Span, name string, fidelity RecordingFidelity) {
+	if _, noop := os.(*noopSpan); noop {
+		panic("StartNamedRecording called on NoopSpan; use the Force option for StartSpan")
+	}
+	s := os.(*span)
+	group := &spanGroup{structural: fidelity == StructuralFidelity}
+	s.mu.Lock()
+	named := make([]namedRecording, 0, len(s.mu.namedRecordings)+1)
+	for _, r := range s.mu.namedRecordings {
+		if r.name != name {
+			named = append(named, r)
+		}
+	}
+	s.setNamedRecordingsLocked(append(named, namedRecording{name: name, group: group}))
+	s.mu.Unlock()
+	group.addSpan(s)
+}
+
+// GetNamedRecording retrieves the named recording of the span; see
+// GetRecording. Returns nil if the span is not part of a recording with that
+// name.
+func GetNamedRecording(os opentracing.Span, name string, opts ...RecordingOption) Recording {
+	s, ok := os.(*span)
+	if !ok {
+		return nil
+	}
+	s.mu.Lock()
+	group := s.findNamedRecordingLocked(name)
+	s.mu.Unlock()
+	if group == nil {
+		return nil
+	}
+	return group.recording(opts)
+}
+
+// StopNamedRecording stops the named recording on the span and returns it.
+// Returns nil if the span is not part of a recording with that name.
+func StopNamedRecording(os opentracing.Span, name string) Recording {
+	s, ok := os.(*span)
+	if !ok {
+		return nil
+	}
+	s.mu.Lock()
+	group := s.findNamedRecordingLocked(name)
+	if group == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	named := make([]namedRecording, 0, len(s.mu.namedRecordings)-1)
+	for _, r := range s.mu.namedRecordings {
+		if r.name != name {
+			named = append(named, r)
+		}
+	}
+	s.setNamedRecordingsLocked(named)
+	s.mu.Unlock()
+	return group.getSpans()
+}
+
+func (s *span) hasNamedRecordings() bool {
+	return atomic.LoadInt32(&s.namedState) != namedNone
+}
+
+func (s *span) findNamedRecordingLocked(name string) *spanGroup {
+	for _, r := range s.mu.namedRecordings {
+		if r.name == name {
+			return r.group
+		}
+	}
+	return nil
+}
+
+// setNamedRecordingsLocked sets the named recordings of the span. The slice is
+// shared with the span's context (and from there with child spans), so it must
+// not be modified afterwards.
+func (s *span) setNamedRecordingsLocked(named []namedRecording) {
+	state := namedNone
+	for _, r := range named {
+		if !r.group.structural {
+			state = namedVerbose
+			break
+		}
+		state = namedStructural
+	}
+	if len(named) == 0 {
+		named = nil
+	}
+	s.mu.namedRecordings = named
+	atomic.StoreInt32(&s.namedState, state)
+	s.resetContextLocked()
+}
+
+// inheritNamedRecordings makes a new span part of the named recordings of its
+// parent.
+func (s *span) inheritNamedRecordings(named []namedRecording) {
+	s.mu.Lock()
+	s.setNamedRecordingsLocked(named)
+	s.mu.Unlock()
+	for _, r := range named {
+		r.group.addSpan(s)
+	}
+}
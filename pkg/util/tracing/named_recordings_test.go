@@ -0,0 +1,83 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestNamedRecordings(t *testing.T) {
+	tr := NewTracer()
+
+	root := tr.StartSpan("root", Recordable)
+	StartNamedRecording(root, "buffer", StructuralFidelity)
+	if IsBlackHoleSpan(root) || IsVerbose(root) {
+		t.Fatal("expected a structural recording")
+	}
+	root.SetTag("tag", 1)
+	root.LogKV("event", "root 1")
+
+	child := tr.StartSpan("child", opentracing.ChildOf(root.Context()))
+	StartNamedRecording(child, "diag", VerboseFidelity)
+	child.LogKV("event", "child 1")
+	grandchild := StartChildSpan("grandchild", child, false /* separateRecording */)
+	grandchild.LogKV("event", "grandchild 1")
+
+	if err := TestingCheckRecordedSpans(GetNamedRecording(root, "buffer"), `
+		span root:
+			tags: tag=1
+		span child:
+		span grandchild:
+	`); err != nil {
+		t.Fatal(err)
+	}
+	if err := TestingCheckRecordedSpans(GetNamedRecording(child, "diag"), `
+		span child:
+			event: child 1
+		span grandchild:
+			event: grandchild 1
+	`); err != nil {
+		t.Fatal(err)
+	}
+	// The regular recording is not affected.
+	if rec := GetRecording(root); rec != nil {
+		t.Fatalf("unexpected recording: %s", rec)
+	}
+
+	// Stopping one recording doesn't affect the other.
+	if err := TestingCheckRecordedSpans(StopNamedRecording(child, "diag"), `
+		span child:
+			event: child 1
+		span grandchild:
+			event: grandchild 1
+	`); err != nil {
+		t.Fatal(err)
+	}
+	if GetNamedRecording(child, "diag") != nil {
+		t.Error("expected the diag recording to be stopped")
+	}
+	child.LogKV("event", "child 2")
+	if IsVerbose(child) {
+		t.Error("expected child to no longer be verbose")
+	}
+	if rec := GetNamedRecording(root, "buffer"); len(rec) != 3 {
+		t.Errorf("expected 3 spans in the buffer recording, got %s", rec)
+	}
+	grandchild.Finish()
+	child.Finish()
+	root.Finish()
+}
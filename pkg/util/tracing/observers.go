@@ -0,0 +1,156 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// SpanObserver is notified when the real spans of a Tracer start and finish.
+// The callbacks are run synchronously, so they must not block.
+type SpanObserver interface {
+	// OnStart is called when a span is started.
+	OnStart(sp SpanView)
+	// OnFinish is called when a span is finished.
+	OnFinish(sp SpanView)
+}
+
+// SpanView is a read-only view of a span, given to SpanObservers. It should
+// not be retained after the callback returns.
+type SpanView struct {
+	s *span
+}
+
+// Operation returns the operation name of the span.
+func (v SpanView) Operation() string {
+	return v.s.operation
+}
+
+// TraceID returns the ID of the trace of the span.
+func (v SpanView) TraceID() uint64 {
+	return v.s.TraceID
+}
+
+// SpanID returns the ID of the span.
+func (v SpanView) SpanID() uint64 {
+	return v.s.SpanID
+}
+
+// ParentSpanID returns the ID of the parent of the span, or zero for root
+// spans.
+func (v SpanView) ParentSpanID() uint64 {
+	return v.s.parentSpanID
+}
+
+// StartTime returns the time at which the span was started.
+func (v SpanView) StartTime() time.Time {
+	return v.s.startTime
+}
+
+// Duration returns the duration of the span, or -1 if it is not finished.
+func (v SpanView) Duration() time.Duration {
+	return v.s.duration()
+}
+
+// Failed returns true if the span was tagged with error=true.
+func (v SpanView) Failed() bool {
+	return atomic.LoadInt32(&v.s.failed) != 0
+}
+
+// Tags returns a copy of the tags set on the span, formatted as strings.
+func (v SpanView) Tags() map[string]string {
+	v.s.mu.Lock()
+	defer v.s.mu.Unlock()
+	if len(v.s.mu.tags) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(v.s.mu.tags))
+	for k, t := range v.s.mu.tags {
+		tags[k] = fmt.Sprint(t.value)
+	}
+	return tags
+}
+
+type spanObservers struct {
+	// observers holds the []SpanObserver. The slice is replaced on
+	// registration, so that spans can read it without locking.
+	observers atomic.Value
+	// mu serializes registrations.
+	mu syncutil.Mutex
+}
+
+// RegisterSpanObserver registers an observer which is notified when the real
+// spans created by this tracer from then on start and finish. Noop spans are
+// not observed.
+func (t *Tracer) RegisterSpanObserver(o SpanObserver) {
+	so := &t.spanObservers
+	so.mu.Lock()
+	defer so.mu.Unlock()
+	old := so.get()
+	so.observers.Store(append(old[:len(old):len(old)], o))
+}
+
+// get returns the registered observers.
+func (so *spanObservers) get() []SpanObserver {
+	observers, _ := so.observers.Load().([]SpanObserver)
+	return observers
+}
+
+// spanFinishHook is a SpanObserver which is only notified of finished spans.
+type spanFinishHook func(s *span)
+
+// OnStart is part of the SpanObserver interface.
+func (spanFinishHook) OnStart(SpanView) {}
+
+// OnFinish is part of the SpanObserver interface.
+func (h spanFinishHook) OnFinish(v SpanView) {
+	h(v.s)
+}
+
+// registerFinishHooks registers the observers implementing the features of
+// the tracer that act on finished spans. They run in this order, before the
+// observers registered by users.
+func (t *Tracer) registerFinishHooks() {
+	for _, h := range []spanFinishHook{
+		(*span).finishSlowTags,
+		(*span).addComponentDuration,
+		(*span).finishEvents,
+		(*span).maybeExport,
+		(*span).maybeRetainErrorRecording,
+		(*span).maybeRouteRecording,
+		// This goes after the hooks retaining recordings; see exemplarTraceID.
+		(*span).recordLatency,
+	} {
+		t.RegisterSpanObserver(h)
+	}
+}
+
+// notifyStart calls the OnStart callback of the observers.
+func (s *span) notifyStart() {
+	for _, o := range s.tracer.spanObservers.get() {
+		o.OnStart(SpanView{s: s})
+	}
+}
+
+// notifyFinish calls the OnFinish callback of the observers.
+func (s *span) notifyFinish() {
+	for _, o := range s.tracer.spanObservers.get() {
+		o.OnFinish(SpanView{s: s})
+	}
+}
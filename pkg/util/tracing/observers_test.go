@@ -0,0 +1,80 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	otext "github.com/opentracing/opentracing-go/ext"
+)
+
+type testObserver struct {
+	events []string
+}
+
+func (o *testObserver) OnStart(sp SpanView) {
+	o.events = append(o.events, fmt.Sprintf("start %s", sp.Operation()))
+}
+
+func (o *testObserver) OnFinish(sp SpanView) {
+	o.events = append(o.events, fmt.Sprintf(
+		"finish %s finished=%t failed=%t tags=%v",
+		sp.Operation(), sp.Duration() >= 0, sp.Failed(), sp.Tags(),
+	))
+}
+
+func TestSpanObserver(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	o := &testObserver{}
+	tr.RegisterSpanObserver(o)
+
+	// Noop spans are not observed.
+	tr.StartSpan("noop").Finish()
+
+	sp := tr.StartSpan("root", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	child := StartChildSpan("child", sp, false /* separateRecording */)
+	otext.Error.Set(child, true)
+	child.Finish()
+	sp.Finish()
+
+	expected := []string{
+		"start root",
+		"start child",
+		"finish child finished=true failed=true tags=map[error:true]",
+		"finish root finished=true failed=false tags=map[]",
+	}
+	if !reflect.DeepEqual(o.events, expected) {
+		t.Errorf("expected %v, got %v", expected, o.events)
+	}
+}
+
+func TestSpanObserverAfterFinishHooks(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	o := &testObserver{}
+	tr.RegisterSpanObserver(o)
+
+	// The tracer's own hooks run first, so the slow tags are set by the time
+	// the observer is notified.
+	sp := tr.StartSpan("slow", Recordable)
+	TagIfSlow(sp, 0, TagSlow, true)
+	sp.Finish()
+	expected := "finish slow finished=true failed=false tags=map[" + TagSlow + ":true]"
+	if len(o.events) != 2 || o.events[1] != expected {
+		t.Errorf("expected %q, got %v", expected, o.events)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/petermattis/goid"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+var selfMeasurement = settings.RegisterBoolSetting(
+	"trace.self_measurement.enabled",
+	"if set, the tracer measures the time spent in its own StartSpan, log, Finish and Inject "+
+		"code paths; see the tracing.overhead metrics",
+	false,
+)
+
+// overheadPath identifies a code path of the tracer measured when
+// trace.self_measurement.enabled is set.
+type overheadPath int
+
+const (
+	overheadStartSpan overheadPath = iota
+	overheadLog
+	overheadFinish
+	overheadInject
+	numOverheadPaths
+)
+
+// overheadStripes is the number of copies of the counters; concurrent
+// goroutines update different copies (most of the time), so that the counters
+// don't become a point of contention.
+const overheadStripes = 16
+
+type overheadStripe struct {
+	paths [numOverheadPaths]struct {
+		calls, nanos int64
+	}
+	// Pad the stripe to a multiple of the cache line size, to avoid false
+	// sharing between stripes.
+	_ [64]byte
+}
+
+// overheadCounters accumulate the time spent in the tracer's code paths. All
+// the counters are accessed atomically.
+type overheadCounters struct {
+	stripes [overheadStripes]overheadStripe
+}
+
+// record adds the time elapsed since start to the counters of the given path.
+func (c *overheadCounters) record(path overheadPath, start time.Time) {
+	d := time.Since(start)
+	p := &c.stripes[uint64(goid.Get())%overheadStripes].paths[path]
+	atomic.AddInt64(&p.calls, 1)
+	atomic.AddInt64(&p.nanos, int64(d))
+}
+
+// PathOverhead is the cumulative cost of a code path of the tracer.
+type PathOverhead struct {
+	// Number of measured calls.
+	Calls int64
+	// Total time spent in the measured calls.
+	Time time.Duration
+}
+
+// OverheadStats is the cumulative cost of the tracer's main code paths, as
+// measured while trace.self_measurement.enabled is set. The measurements
+// include the time spent in the shadow tracer (if any).
+type OverheadStats struct {
+	// StartSpan covers StartSpan, Start and StartChildSpan.
+	StartSpan PathOverhead
+	// Log covers LogFields and LogKV.
+	Log    PathOverhead
+	Finish PathOverhead
+	Inject PathOverhead
+}
+
+// Total returns the total time spent in the measured code paths.
+func (s OverheadStats) Total() time.Duration {
+	return s.StartSpan.Time + s.Log.Time + s.Finish.Time + s.Inject.Time
+}
+
+// Overhead returns the cost of the tracer's code paths measured so far. This
+// can be used to quantify the overhead of tracing on production workloads.
+func (t *Tracer) Overhead() OverheadStats {
+	var paths [numOverheadPaths]PathOverhead
+	for i := range t.overhead.stripes {
+		st := &t.overhead.stripes[i]
+		for j := range paths {
+			paths[j].Calls += atomic.LoadInt64(&st.paths[j].calls)
+			paths[j].Time += time.Duration(atomic.LoadInt64(&st.paths[j].nanos))
+		}
+	}
+	return OverheadStats{
+		StartSpan: paths[overheadStartSpan],
+		Log:       paths[overheadLog],
+		Finish:    paths[overheadFinish],
+		Inject:    paths[overheadInject],
+	}
+}
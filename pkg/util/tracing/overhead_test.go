@@ -0,0 +1,48 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestOverhead(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	trace := func() {
+		sp := tr.StartSpan("op", Recordable)
+		child := StartChildSpan("child", sp, false /* separateRecording */)
+		sp.LogKV("event", "x")
+		if err := tr.Inject(sp.Context(), opentracing.TextMap, opentracing.TextMapCarrier{}); err != nil {
+			t.Fatal(err)
+		}
+		child.Finish()
+		sp.Finish()
+	}
+
+	trace()
+	if s := tr.Overhead(); s != (OverheadStats{}) {
+		t.Fatalf("expected no measurements, got %+v", s)
+	}
+
+	defer settings.TestingSetBool(&selfMeasurement, true)()
+	trace()
+	s := tr.Overhead()
+	if s.StartSpan.Calls != 2 || s.Log.Calls != 1 || s.Finish.Calls != 2 || s.Inject.Calls != 1 {
+		t.Errorf("unexpected measurements %+v", s)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+var partialTracingSetting = settings.RegisterValidatedStringSetting(
+	"trace.partial.child_sample_rates",
+	"comma-separated rules making traces de-escalate below some operations, in "+
+		"the form <operation>=<rate>: the children of the spans of the operation "+
+		"are only created with the given probability (e.g. 'sql.row=0.01'), while "+
+		"the rest of the trace is fully recorded",
+	"",
+	func(v string) error {
+		_, err := parsePartialTracing(v)
+		return err
+	},
+)
+
+// TagChildrenDropped is set on recorded spans some of whose children were not
+// created because of the trace.partial.child_sample_rates setting.
+const TagChildrenDropped = "children_dropped"
+
+func parsePartialTracing(v string) (map[string]float64, error) {
+	var res map[string]float64
+	for _, rule := range strings.Split(v, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		i := strings.LastIndex(rule, "=")
+		if i < 0 {
+			return nil, errors.Errorf("invalid rule %q: missing '='", rule)
+		}
+		op := strings.TrimSpace(rule[:i])
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rule[i+1:]), 64)
+		if err != nil || op == "" || rate < 0 || rate > 1 {
+			return nil, errors.Errorf("invalid rule %q", rule)
+		}
+		if res == nil {
+			res = make(map[string]float64)
+		}
+		res[op] = rate
+	}
+	return res, nil
+}
+
+// parsedPartialTracing caches the parsed value of the setting.
+type parsedPartialTracing struct {
+	raw   string
+	rates map[string]float64
+}
+
+var partialTracingCache atomic.Value
+
+// partialSampling is the state of a span whose children are sampled; see
+// trace.partial.child_sample_rates. It is shared with the span's context.
+type partialSampling struct {
+	rate float64
+	// Number of children that were not created; accessed atomically.
+	dropped int64
+}
+
+// partialSamplingFor returns the partialSampling of a new span with the given
+// operation, or nil if its children are not sampled.
+func partialSamplingFor(operation string) *partialSampling {
+	raw := partialTracingSetting.Get()
+	if raw == "" {
+		return nil
+	}
+	p, ok := partialTracingCache.Load().(parsedPartialTracing)
+	if !ok || p.raw != raw {
+		rates, _ := parsePartialTracing(raw)
+		p = parsedPartialTracing{raw: raw, rates: rates}
+		partialTracingCache.Store(p)
+	}
+	rate, ok := p.rates[operation]
+	if !ok || rate >= 1 {
+		return nil
+	}
+	return &partialSampling{rate: rate}
+}
+
+// dropChild returns true if a child of the span should not be created, in
+// which case it is counted as dropped. It can be called on a nil receiver.
+func (p *partialSampling) dropChild() bool {
+	if p == nil || rand.Float64() < p.rate {
+		return false
+	}
+	atomic.AddInt64(&p.dropped, 1)
+	return true
+}
+
+// droppedChildren returns the number of children that were dropped. It can be
+// called on a nil receiver.
+func (p *partialSampling) droppedChildren() int64 {
+	if p == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&p.dropped)
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestParsePartialTracing(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		expErr bool
+	}{
+		{in: ""},
+		{in: "sql.row=0.01, scan=0"},
+		{in: "sql.row", expErr: true},
+		{in: "sql.row=x", expErr: true},
+		{in: "sql.row=2", expErr: true},
+		{in: "=0.5", expErr: true},
+	} {
+		if _, err := parsePartialTracing(tc.in); (err != nil) != tc.expErr {
+			t.Errorf("%q: unexpected error %v", tc.in, err)
+		}
+	}
+}
+
+func TestPartialTracing(t *testing.T) {
+	defer settings.TestingSetString(&partialTracingSetting, "rows=0")()
+	tr := NewTracer()
+
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SingleNodeRecording)
+	rows := StartChildSpan("rows", root, false /* separateRecording */)
+	for i := 0; i < 3; i++ {
+		StartChildSpan("row", rows, false /* separateRecording */).Finish()
+	}
+	tr.StartSpan("row", opentracing.ChildOf(rows.Context())).Finish()
+	rows.Finish()
+	// The rest of the trace is recorded.
+	StartChildSpan("other", root, false /* separateRecording */).Finish()
+	root.Finish()
+
+	if err := TestingCheckRecordedSpans(GetRecording(root), `
+		span root:
+		span rows:
+			tags: children_dropped=4
+		span other:
+	`); err != nil {
+		t.Fatal(err)
+	}
+}
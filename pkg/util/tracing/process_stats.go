@@ -0,0 +1,108 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/elastic/gosigar"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// processStatsEnabled controls whether real spans are tagged with the
+// process-wide CPU usage during their lifetime. Reading the CPU usage is a
+// system call, so this is off by default.
+var processStatsEnabled = settings.RegisterBoolSetting(
+	"trace.process_stats.enabled",
+	"if set, spans are tagged with the CPU usage of the whole process while they were open",
+	false,
+)
+
+// Tags set on spans when processStatsEnabled is set. They describe the whole
+// process, not the work done by the span.
+const (
+	// TagProcessCPU is the CPU time used by the process while the span was
+	// open.
+	TagProcessCPU = "process.cpu"
+	// TagProcessCPUPercent is TagProcessCPU as a percentage of the CPU time
+	// available to the process (GOMAXPROCS CPUs).
+	TagProcessCPUPercent = "process.cpu_percent"
+	// TagProcessGoroutines is the number of goroutines when the span
+	// finished.
+	TagProcessGoroutines = "process.goroutines"
+)
+
+// processStats is a snapshot of the process-wide CPU usage.
+type processStats struct {
+	at  time.Time
+	cpu time.Duration
+}
+
+// readProcessStats returns the current CPU usage of the process, or nil if it
+// is not available on this platform.
+func readProcessStats() *processStats {
+	var cpu gosigar.ProcTime
+	if err := cpu.Get(os.Getpid()); err != nil {
+		return nil
+	}
+	// ProcTime is in milliseconds.
+	return &processStats{
+		at:  time.Now(),
+		cpu: time.Duration(cpu.User+cpu.Sys) * time.Millisecond,
+	}
+}
+
+// processStatsDelta summarizes the CPU usage between two snapshots.
+type processStatsDelta struct {
+	cpu        time.Duration
+	cpuPercent float64
+}
+
+// sub returns the CPU usage between prev and s, given the number of CPUs
+// available to the process.
+func (s *processStats) sub(prev *processStats, procs int) processStatsDelta {
+	d := processStatsDelta{cpu: s.cpu - prev.cpu}
+	if wall := s.at.Sub(prev.at); wall > 0 && procs > 0 {
+		d.cpuPercent = 100 * float64(d.cpu) / (float64(wall) * float64(procs))
+	}
+	return d
+}
+
+// maybeStartProcessStats records the CPU usage at the start of the span, if
+// enabled.
+func (s *span) maybeStartProcessStats() {
+	if processStatsEnabled.Get() {
+		s.processStart = readProcessStats()
+	}
+}
+
+// finishProcessStats tags the span with the CPU usage since the span was
+// started.
+func (s *span) finishProcessStats() {
+	if s.processStart == nil {
+		return
+	}
+	end := readProcessStats()
+	if end == nil {
+		return
+	}
+	d := end.sub(s.processStart, runtime.GOMAXPROCS(0))
+	s.SetTag(TagProcessCPU, d.cpu)
+	s.SetTag(TagProcessCPUPercent, d.cpuPercent)
+	s.SetTag(TagProcessGoroutines, runtime.NumGoroutine())
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestProcessStatsDelta(t *testing.T) {
+	start := time.Unix(0, 0)
+	prev := &processStats{at: start, cpu: time.Second}
+	cur := &processStats{at: start.Add(time.Second), cpu: 3 * time.Second}
+	d := cur.sub(prev, 4)
+	if d.cpu != 2*time.Second {
+		t.Errorf("expected cpu 2s, got %s", d.cpu)
+	}
+	if d.cpuPercent != 50 {
+		t.Errorf("expected 50%%, got %f", d.cpuPercent)
+	}
+}
+
+func TestProcessStatsTags(t *testing.T) {
+	if readProcessStats() == nil {
+		t.Skip("process CPU usage not supported")
+	}
+	defer settings.TestingSetBool(&processStatsEnabled, true)()
+
+	tr := NewTracer()
+	sp := tr.StartSpan("a", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	sp.Finish()
+
+	for _, tag := range []string{TagProcessCPU, TagProcessCPUPercent, TagProcessGoroutines} {
+		if GetSpanTag(sp, tag) == nil {
+			t.Errorf("expected tag %s to be set", tag)
+		}
+	}
+}
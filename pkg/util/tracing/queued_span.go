@@ -0,0 +1,57 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+)
+
+// TagQueueWait is set by DequeueSpan to the time the work spent in the queue.
+const TagQueueWait = "queue.wait"
+
+// QueuedSpanMeta is the span context and enqueue time of work waiting in a
+// queue; see EnqueueSpan. The zero value means that the work is not traced.
+type QueuedSpanMeta struct {
+	tracer   opentracing.Tracer
+	ctx      opentracing.SpanContext
+	enqueued time.Time
+}
+
+// EnqueueSpan captures the trace information of work that is being added to an
+// asynchronous queue, if ctx has a span. It is cheap when tracing is disabled.
+func EnqueueSpan(ctx context.Context) QueuedSpanMeta {
+	sp := opentracing.SpanFromContext(ctx)
+	if sp == nil || (IsBlackHoleSpan(sp) && !isCarrierSpan(sp)) {
+		return QueuedSpanMeta{}
+	}
+	return QueuedSpanMeta{tracer: sp.Tracer(), ctx: sp.Context(), enqueued: time.Now()}
+}
+
+// DequeueSpan opens a span that "follows from" the span that enqueued the work
+// with EnqueueSpan, tagged with TagQueueWait. The span should be closed via
+// FinishSpan.
+func DequeueSpan(
+	ctx context.Context, meta QueuedSpanMeta, opName string,
+) (context.Context, opentracing.Span) {
+	if meta.ctx == nil {
+		return ctx, nil
+	}
+	sp := meta.tracer.StartSpan(opName, opentracing.FollowsFrom(meta.ctx))
+	sp.SetTag(TagQueueWait, time.Since(meta.enqueued))
+	return opentracing.ContextWithSpan(ctx, sp), sp
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+)
+
+func TestQueuedSpan(t *testing.T) {
+	tr := NewTracer()
+
+	// Work enqueued without a real span is not traced.
+	noop := opentracing.ContextWithSpan(context.Background(), tr.StartSpan("noop"))
+	for _, ctx := range []context.Context{context.Background(), noop} {
+		meta := EnqueueSpan(ctx)
+		if meta != (QueuedSpanMeta{}) {
+			t.Errorf("expected no trace information, got %+v", meta)
+		}
+		if _, sp := DequeueSpan(context.Background(), meta, "work"); sp != nil {
+			t.Error("unexpected span")
+		}
+	}
+
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SnowballRecording)
+	meta := EnqueueSpan(opentracing.ContextWithSpan(context.Background(), root))
+	root.Finish()
+
+	ctx, sp := DequeueSpan(context.Background(), meta, "work")
+	if opentracing.SpanFromContext(ctx) != sp {
+		t.Fatal("expected the span in the context")
+	}
+	sp.Finish()
+
+	rec := GetRecording(sp)
+	if len(rec) != 2 || rec[1].Operation != "work" {
+		t.Fatalf("unexpected recording: %+v", rec)
+	}
+	work := rec[1]
+	if work.ParentSpanID != rec[0].SpanID ||
+		work.StartOptions.ParentReference != ParentReferenceFollowsFrom {
+		t.Errorf("expected a follows-from span, got %+v", work)
+	}
+	if d, err := time.ParseDuration(work.Tags[TagQueueWait]); err != nil || d < 0 {
+		t.Errorf("unexpected queue wait %q", work.Tags[TagQueueWait])
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// RecordingSinkRecent is the name of a built-in sink which adds the
+// recordings to the tracer's buffer of recent traces (see
+// Tracer.RecentTraces).
+const RecordingSinkRecent = "recent"
+
+// maxRecentTraces is the number of recordings retained by each Tracer's
+// buffer of recent traces.
+const maxRecentTraces = 64
+
+var recentTracesIndexedTags = settings.RegisterStringSetting(
+	"trace.recent.indexed_tags",
+	"comma-separated span tags by which the recent traces buffer is indexed",
+	"sql.stmt,range,node,"+TagCorrelationID,
+)
+
+// recentTraces retains the most recent recordings routed to
+// RecordingSinkRecent, indexed by the tags in trace.recent.indexed_tags.
+type recentTraces struct {
+	syncutil.Mutex
+	// buf holds the recordings, oldest first.
+	buf []Recording
+	// first is the sequence number of buf[0]; the sequence number of buf[i]
+	// is first+i.
+	first uint64
+	// indexedRaw is the value of the setting for which index was built.
+	indexedRaw string
+	// index maps the indexed tag keys to their values, and then to the
+	// (increasing) sequence numbers of the recordings containing a span with
+	// that tag value.
+	index map[string]map[string][]uint64
+}
+
+func (b *recentTraces) add(rec Recording) {
+	b.Lock()
+	defer b.Unlock()
+	b.maybeReindexLocked()
+	if len(b.buf) == maxRecentTraces {
+		b.evictLocked()
+	}
+	seq := b.first + uint64(len(b.buf))
+	b.buf = append(b.buf, rec)
+	b.indexLocked(seq, rec)
+}
+
+// maybeReindexLocked rebuilds the index if the set of indexed tags changed.
+func (b *recentTraces) maybeReindexLocked() {
+	raw := recentTracesIndexedTags.Get()
+	if b.index != nil && raw == b.indexedRaw {
+		return
+	}
+	b.indexedRaw = raw
+	b.index = make(map[string]map[string][]uint64)
+	for _, k := range strings.Split(raw, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			b.index[k] = make(map[string][]uint64)
+		}
+	}
+	for i, rec := range b.buf {
+		b.indexLocked(b.first+uint64(i), rec)
+	}
+}
+
+func (b *recentTraces) indexLocked(seq uint64, rec Recording) {
+	for _, sp := range rec {
+		for k, v := range sp.Tags {
+			values, ok := b.index[k]
+			if !ok {
+				continue
+			}
+			// Several spans of the recording can have the same tag value.
+			if seqs := values[v]; len(seqs) == 0 || seqs[len(seqs)-1] != seq {
+				values[v] = append(seqs, seq)
+			}
+		}
+	}
+}
+
+// evictLocked removes the oldest recording. Since recordings are evicted in
+// the order in which they were added, their entries are at the front of the
+// index lists.
+func (b *recentTraces) evictLocked() {
+	for _, sp := range b.buf[0] {
+		for k, v := range sp.Tags {
+			values, ok := b.index[k]
+			if !ok {
+				continue
+			}
+			if seqs := values[v]; len(seqs) > 0 && seqs[0] == b.first {
+				if len(seqs) == 1 {
+					delete(values, v)
+				} else {
+					values[v] = seqs[1:]
+				}
+			}
+		}
+	}
+	b.buf[0] = nil
+	b.buf = b.buf[1:]
+	b.first++
+}
+
+// RecentTraces returns the recent recordings (see RecordingSinkRecent) which
+// contain a span with the given tag value, most recent first. If tag is empty,
+// all the recordings are returned.
+func (t *Tracer) RecentTraces(tag, value string) []Recording {
+	b := &t.recentTraces
+	b.Lock()
+	defer b.Unlock()
+	b.maybeReindexLocked()
+	var result []Recording
+	if values, ok := b.index[tag]; ok {
+		seqs := values[value]
+		for i := len(seqs) - 1; i >= 0; i-- {
+			result = append(result, b.buf[seqs[i]-b.first])
+		}
+		return result
+	}
+	for i := len(b.buf) - 1; i >= 0; i-- {
+		if tag == "" || hasTag(b.buf[i], tag, value) {
+			result = append(result, b.buf[i])
+		}
+	}
+	return result
+}
+
+func hasTag(rec Recording, tag, value string) bool {
+	for _, sp := range rec {
+		if v, ok := sp.Tags[tag]; ok && v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestRecentTraces(t *testing.T) {
+	defer settings.TestingSetString(&recordingRoutesSetting, "*:"+RecordingSinkRecent)()
+	defer settings.TestingSetString(&recentTracesIndexedTags, "range")()
+	tr := NewTracer().(*Tracer)
+
+	// Trace i touches range i%3 (twice) and is tagged with user i%2.
+	const n = maxRecentTraces + 10
+	for i := 0; i < n; i++ {
+		root := tr.StartSpan(fmt.Sprintf("op%d", i), Recordable)
+		StartRecording(root, SingleNodeRecording)
+		root.SetTag("user", i%2)
+		for j := 0; j < 2; j++ {
+			child := tr.StartSpan("child", opentracing.ChildOf(root.Context()))
+			child.SetTag("range", i%3)
+			child.Finish()
+		}
+		root.Finish()
+	}
+
+	check := func(tag, value string, exp []int) {
+		recs := tr.RecentTraces(tag, value)
+		var ops []string
+		for _, rec := range recs {
+			ops = append(ops, rec[0].Operation)
+		}
+		var expOps []string
+		for _, i := range exp {
+			expOps = append(expOps, fmt.Sprintf("op%d", i))
+		}
+		if fmt.Sprint(ops) != fmt.Sprint(expOps) {
+			t.Errorf("%s=%s: expected %v, got %v", tag, value, expOps, ops)
+		}
+	}
+	// expected returns the retained traces matching the predicate, most recent
+	// first.
+	expected := func(pred func(i int) bool) []int {
+		var res []int
+		for i := n - 1; i >= n-maxRecentTraces; i-- {
+			if pred(i) {
+				res = append(res, i)
+			}
+		}
+		return res
+	}
+
+	if recs := tr.RecentTraces("", ""); len(recs) != maxRecentTraces {
+		t.Fatalf("expected %d recordings, got %d", maxRecentTraces, len(recs))
+	}
+	byRange := func(i int) bool { return i%3 == 1 }
+	byUser := func(i int) bool { return i%2 == 0 }
+	// Indexed lookup.
+	check("range", "1", expected(byRange))
+	check("range", "5", nil)
+	// Lookup by scanning.
+	check("user", "0", expected(byUser))
+
+	// Changing the indexed tags rebuilds the index.
+	defer settings.TestingSetString(&recentTracesIndexedTags, "user")()
+	check("user", "0", expected(byUser))
+	check("range", "1", expected(byRange))
+	if _, ok := tr.recentTraces.index["user"]; !ok {
+		t.Error("expected the user tag to be indexed")
+	}
+}
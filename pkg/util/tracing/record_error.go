@@ -0,0 +1,84 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"runtime"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+)
+
+// Keys of the fields of the events logged by RecordError.
+const (
+	ErrorFieldMessage = "error"
+	ErrorFieldStack   = "stack"
+)
+
+// maxErrorStackDepth caps the depth requested with WithStack.
+const maxErrorStackDepth = 64
+
+// ErrorOption is an option for RecordError.
+type ErrorOption interface {
+	apply(*errorOptions)
+}
+
+type errorOptions struct {
+	stackDepth int
+}
+
+type stackOption int
+
+func (o stackOption) apply(opts *errorOptions) {
+	opts.stackDepth = int(o)
+}
+
+// WithStack is a RecordError option which captures up to depth frames of the
+// call stack of the caller of RecordError, so that the event shows where the
+// error surfaced. The stack is stored in the ErrorFieldStack field; it is only
+// symbolized when the field is formatted.
+func WithStack(depth int) ErrorOption {
+	if depth > maxErrorStackDepth {
+		depth = maxErrorStackDepth
+	}
+	return stackOption(depth)
+}
+
+// RecordError marks the span as failed (by setting the standard "error" tag)
+// and logs an event with the error message in the ErrorFieldMessage field.
+// Nothing is done for nil errors or for spans that are not recording or
+// exporting events.
+func RecordError(sp opentracing.Span, err error, opts ...ErrorOption) {
+	if err == nil || sp == nil || IsBlackHoleSpan(sp) {
+		return
+	}
+	var o errorOptions
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+	otext.Error.Set(sp, true)
+	if o.stackDepth <= 0 {
+		sp.LogFields(otlog.String(ErrorFieldMessage, err.Error()))
+		return
+	}
+	pcs := make([]uintptr, o.stackDepth)
+	// Skip runtime.Callers and this function.
+	n := runtime.Callers(2, pcs)
+	sp.LogFields(
+		otlog.String(ErrorFieldMessage, err.Error()),
+		otlog.Object(ErrorFieldStack, creationStack(pcs[:n])),
+	)
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestRecordError(t *testing.T) {
+	tr := NewTracer()
+	sp := tr.StartSpan("a", Recordable)
+	defer sp.Finish()
+	StartRecording(sp, SingleNodeRecording)
+
+	RecordError(sp, nil)
+	RecordError(sp, errors.New("boom"))
+	RecordError(sp, errors.New("bang"), WithStack(4))
+
+	rec := GetRecording(sp)
+	if rec[0].Tags["error"] != "true" {
+		t.Errorf("expected span to be marked as failed: %v", rec[0].Tags)
+	}
+	logs := rec[0].Logs
+	if len(logs) != 2 {
+		t.Fatalf("expected 2 events, got %+v", logs)
+	}
+	if f := logs[0].Fields; len(f) != 1 || f[0].Key != ErrorFieldMessage || f[0].Value != "boom" {
+		t.Errorf("unexpected fields %+v", f)
+	}
+	f := logs[1].Fields
+	if len(f) != 2 || f[0].Value != "bang" || f[1].Key != ErrorFieldStack {
+		t.Fatalf("unexpected fields %+v", f)
+	}
+	if !strings.Contains(f[1].Value, "TestRecordError") {
+		t.Errorf("expected stack to include the caller, got %s", f[1].Value)
+	}
+	if frames := strings.Count(f[1].Value, "; ") + 1; frames > 4 {
+		t.Errorf("expected at most 4 frames, got %d", frames)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+)
+
+// lazyMessage is a log message whose formatting is deferred until it is
+// needed (i.e. when the recording is collected).
+type lazyMessage struct {
+	format string
+	args   []interface{}
+}
+
+var _ fmt.Stringer = lazyMessage{}
+
+func (m lazyMessage) String() string {
+	return fmt.Sprintf(m.format, m.args...)
+}
+
+// Recordf logs a printf-style message in the span. The formatting is deferred
+// until the recording is collected if all the arguments are immutable values.
+func Recordf(os opentracing.Span, format string, args ...interface{}) {
+	s, ok := os.(*span)
+	if !ok {
+		if _, noop := os.(*noopSpan); !noop {
+			os.LogFields(otlog.String("event", fmt.Sprintf(format, args...)))
+		}
+		return
+	}
+	if s.shadowTr != nil || s.events != nil {
+		// The other sinks need the message right away.
+		s.LogFields(otlog.String("event", fmt.Sprintf(format, args...)))
+		return
+	}
+	if !s.isVerbose() || s.cost.cutOff() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.mu.recordedLogs) >= maxLogsPerSpan || !s.mu.recordingGroup.budget().consumeLogBudget() {
+		return
+	}
+	var value interface{}
+	if argsAreImmutable(args) {
+		value = lazyMessage{format: format, args: args}
+	} else {
+		value = fmt.Sprintf(format, args...)
+	}
+	s.mu.recordedLogs = append(s.mu.recordedLogs, opentracing.LogRecord{
+		Timestamp: s.tracer.now(),
+		Fields:    []otlog.Field{otlog.Object("event", value)},
+	})
+	size := int64(len("event")) + valueSize(value)
+	s.cost.addRecorded(size)
+	s.component.addRecorded(size)
+}
+
+// argsAreImmutable returns true if all the arguments are values that can't
+// change after the call.
+func argsAreImmutable(args []interface{}) bool {
+	for _, a := range args {
+		switch a.(type) {
+		case nil, bool, string,
+			int, int8, int16, int32, int64,
+			uint, uint8, uint16, uint32, uint64, uintptr,
+			float32, float64, complex64, complex128,
+			time.Duration, time.Time:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import "testing"
+
+// countingStringer counts how many times it is formatted.
+type countingStringer struct {
+	count *int
+}
+
+func (c countingStringer) String() string {
+	*c.count++
+	return "counted"
+}
+
+func TestRecordf(t *testing.T) {
+	tr := NewTracer()
+	tr.(*Tracer).SetForceRealSpans(true)
+
+	// Nothing is formatted for spans that are not recording.
+	var count int
+	sp := tr.StartSpan("a")
+	Recordf(sp, "%s", countingStringer{&count})
+	if count != 0 {
+		t.Errorf("message was formatted %d times", count)
+	}
+	Recordf(&tr.(*Tracer).noopSpan, "%s", countingStringer{&count})
+	if count != 0 {
+		t.Errorf("message was formatted %d times", count)
+	}
+
+	StartRecording(sp, SingleNodeRecording)
+	x := 1
+	Recordf(sp, "x=%d", x)
+	// Mutable arguments are formatted right away.
+	p := &x
+	Recordf(sp, "p=%d", *p)
+	m := map[string]int{"x": 1}
+	Recordf(sp, "m=%v", m)
+	m["x"] = 2
+	x = 3
+
+	if err := TestingCheckRecordedSpans(GetRecording(sp), `
+		span a:
+			event: x=1
+			event: p=1
+			event: m=map[x:1]
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Messages over the limit are not formatted.
+	for i := len(sp.(*span).mu.recordedLogs); i < maxLogsPerSpan; i++ {
+		Recordf(sp, "%d", i)
+	}
+	Recordf(sp, "%s", countingStringer{&count})
+	if count != 0 {
+		t.Errorf("message was formatted %d times", count)
+	}
+	if n := len(GetRecording(sp)[0].Logs); n != maxLogsPerSpan {
+		t.Errorf("expected %d logs, got %d", maxLogsPerSpan, n)
+	}
+}
@@ -0,0 +1,410 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sort"
+	"time"
+	"unsafe"
+)
+
+// Recording is a group of recorded spans, as returned by GetRecording. The
+// spans can come from multiple nodes.
+type Recording []RecordedSpan
+
+const (
+	recordedSpanSize = int64(unsafe.Sizeof(RecordedSpan{}))
+	logRecordSize    = int64(unsafe.Sizeof(RecordedSpan_LogRecord{}))
+	logFieldSize     = int64(unsafe.Sizeof(RecordedSpan_LogRecord_Field{}))
+	// mapEntryOverhead is a rough estimate of the memory used by a map entry
+	// with a string key and a string value, not counting the strings' contents.
+	mapEntryOverhead = int64(2*unsafe.Sizeof("")) + 16
+)
+
+// MemSize returns an estimate of the memory used by the recording, in bytes.
+func (r Recording) MemSize() int64 {
+	size := int64(unsafe.Sizeof(r)) + int64(cap(r))*recordedSpanSize
+	for i := range r {
+		sp := &r[i]
+		size += int64(len(sp.Operation))
+		for k, v := range sp.Baggage {
+			size += mapEntryOverhead + int64(len(k)+len(v))
+		}
+		for k, v := range sp.Tags {
+			size += mapEntryOverhead + int64(len(k)+len(v))
+		}
+		size += int64(cap(sp.Logs)) * logRecordSize
+		for j := range sp.Logs {
+			l := &sp.Logs[j]
+			size += int64(cap(l.Fields)) * logFieldSize
+			for _, f := range l.Fields {
+				size += int64(len(f.Key) + len(f.Value))
+			}
+		}
+	}
+	return size
+}
+
+// ApproxWireSize returns an estimate of the number of bytes the recording takes
+// up when serialized as a repeated RecordedSpan field of a proto message (which
+// is how recordings are sent between nodes).
+func (r Recording) ApproxWireSize() int64 {
+	var size int64
+	for i := range r {
+		l := r[i].Size()
+		// Each span is preceded by the field key (one byte for the field numbers
+		// we use) and its length.
+		size += int64(1 + sovRecordedSpan(uint64(l)) + l)
+	}
+	return size
+}
+
+// RoundTimestamps rounds down all the timestamps in the recording to a multiple
+// of the given granularity. The recording is modified in place.
+func (r Recording) RoundTimestamps(granularity time.Duration) {
+	if granularity <= 1 {
+		return
+	}
+	for i := range r {
+		sp := &r[i]
+		start := sp.StartTime.Truncate(granularity)
+		if sp.Duration != 0 {
+			end := sp.StartTime.Add(sp.Duration).Truncate(granularity)
+			sp.Duration = end.Sub(start)
+			if sp.Duration == 0 {
+				// 0 means that the span is not finished.
+				sp.Duration = time.Nanosecond
+			}
+		}
+		sp.StartTime = start
+		sp.ClockReading = sp.ClockReading.Truncate(granularity)
+		for j := range sp.Logs {
+			sp.Logs[j].Time = sp.Logs[j].Time.Truncate(granularity)
+		}
+	}
+}
+
+// EncodeStartOffsets replaces the start times of the spans whose parent is part
+// of the recording with offsets from the parent's start. The recording is
+// modified in place.
+func (r Recording) EncodeStartOffsets() {
+	starts := make(map[uint64]time.Time, len(r))
+	for i := range r {
+		starts[r[i].SpanID] = r[i].StartTime
+	}
+	for i := range r {
+		sp := &r[i]
+		parentStart, ok := starts[sp.ParentSpanID]
+		if sp.ParentSpanID == 0 || !ok || sp.StartOffset != nil {
+			continue
+		}
+		offset := sp.StartTime.Sub(parentStart)
+		sp.StartOffset = &offset
+		// The Unix epoch is encoded as an empty timestamp.
+		sp.StartTime = time.Unix(0, 0).UTC()
+	}
+}
+
+// DecodeStartOffsets is the inverse of EncodeStartOffsets. Offsets whose parent
+// is not part of the recording are left alone.
+//
+// The recording is modified in place.
+func (r Recording) DecodeStartOffsets() {
+	encoded := false
+	for i := range r {
+		if r[i].StartOffset != nil {
+			encoded = true
+			break
+		}
+	}
+	if !encoded {
+		return
+	}
+	byID := make(map[uint64]*RecordedSpan, len(r))
+	for i := range r {
+		byID[r[i].SpanID] = &r[i]
+	}
+	var resolve func(sp *RecordedSpan)
+	resolve = func(sp *RecordedSpan) {
+		if sp.StartOffset == nil {
+			return
+		}
+		parent, ok := byID[sp.ParentSpanID]
+		if !ok {
+			return
+		}
+		offset := *sp.StartOffset
+		// Cleared before resolving the parent to guard against cycles.
+		sp.StartOffset = nil
+		resolve(parent)
+		sp.StartTime = parent.StartTime.Add(offset)
+	}
+	for i := range r {
+		resolve(&r[i])
+	}
+}
+
+// MergeRecordings combines recordings from multiple sources, keeping the most
+// complete version of duplicate spans. Each span comes after its parent, and
+// siblings are ordered by start time.
+func MergeRecordings(recs ...Recording) Recording {
+	type spanKey struct {
+		traceID, spanID uint64
+	}
+	var n int
+	for _, rec := range recs {
+		n += len(rec)
+	}
+	idx := make(map[spanKey]int, n)
+	merged := make(Recording, 0, n)
+	for _, rec := range recs {
+		for _, sp := range rec {
+			k := spanKey{traceID: sp.TraceID, spanID: sp.SpanID}
+			if i, ok := idx[k]; ok {
+				if moreComplete(&sp, &merged[i]) {
+					merged[i] = sp
+				}
+				continue
+			}
+			idx[k] = len(merged)
+			merged = append(merged, sp)
+		}
+	}
+
+	// Order the spans: parents before children, siblings by start time.
+	less := func(a, b int) bool {
+		sa, sb := &merged[a], &merged[b]
+		if !sa.StartTime.Equal(sb.StartTime) {
+			return sa.StartTime.Before(sb.StartTime)
+		}
+		if sa.TraceID != sb.TraceID {
+			return sa.TraceID < sb.TraceID
+		}
+		return sa.SpanID < sb.SpanID
+	}
+	children := make(map[spanKey][]int)
+	var roots []int
+	for i := range merged {
+		sp := &merged[i]
+		parent := spanKey{traceID: sp.TraceID, spanID: sp.ParentSpanID}
+		if _, ok := idx[parent]; ok && sp.ParentSpanID != sp.SpanID {
+			children[parent] = append(children[parent], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+	result := make(Recording, 0, len(merged))
+	visited := make([]bool, len(merged))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		result = append(result, merged[i])
+		c := children[spanKey{traceID: merged[i].TraceID, spanID: merged[i].SpanID}]
+		sort.Slice(c, func(x, y int) bool { return less(c[x], c[y]) })
+		for _, j := range c {
+			visit(j)
+		}
+	}
+	sort.Slice(roots, func(x, y int) bool { return less(roots[x], roots[y]) })
+	for _, r := range roots {
+		visit(r)
+	}
+	// Spans that are part of a cycle (which can only happen with corrupted
+	// data) are not reachable from any root; add them at the end.
+	if len(result) < len(merged) {
+		var rest []int
+		for i := range merged {
+			if !visited[i] {
+				rest = append(rest, i)
+			}
+		}
+		sort.Slice(rest, func(x, y int) bool { return less(rest[x], rest[y]) })
+		for _, i := range rest {
+			visit(i)
+		}
+	}
+	return result
+}
+
+// subtree returns the span with the given ID and all its descendants, in the
+// order in which they appear in the recording.
+func (r Recording) subtree(spanID uint64) Recording {
+	children := make(map[uint64][]uint64)
+	for i := range r {
+		if r[i].ParentSpanID != r[i].SpanID {
+			children[r[i].ParentSpanID] = append(children[r[i].ParentSpanID], r[i].SpanID)
+		}
+	}
+	in := map[uint64]bool{spanID: true}
+	for queue := []uint64{spanID}; len(queue) > 0; queue = queue[1:] {
+		for _, c := range children[queue[0]] {
+			if !in[c] {
+				in[c] = true
+				queue = append(queue, c)
+			}
+		}
+	}
+	var result Recording
+	for i := range r {
+		if in[r[i].SpanID] {
+			result = append(result, r[i])
+		}
+	}
+	return result
+}
+
+// moreComplete returns true if a is a more complete version of the same span
+// than b: finished spans win over unfinished ones, then the version with more
+// events wins, then the version that was collected last.
+func moreComplete(a, b *RecordedSpan) bool {
+	if aDone, bDone := a.Duration != 0, b.Duration != 0; aDone != bDone {
+		return aDone
+	}
+	if len(a.Logs) != len(b.Logs) {
+		return len(a.Logs) > len(b.Logs)
+	}
+	return a.ClockReading.After(b.ClockReading)
+}
+
+// SetClockOffset records the offset of the clock of the node that produced the
+// given (remote) spans relative to the local clock, for spans that don't
+// already have an offset. It is meant to be called by the RPC layer, which
+// measures clock offsets, before importing the spans with ImportRemoteSpans.
+func SetClockOffset(spans []RecordedSpan, offset time.Duration) {
+	for i := range spans {
+		if spans[i].ClockOffset == 0 {
+			spans[i].ClockOffset = offset
+		}
+	}
+}
+
+// AlignRecording translates spans with a known ClockOffset to the local clock,
+// then moves spans that appear to start before their parent forward. The spans
+// are modified in place.
+func AlignRecording(spans []RecordedSpan) {
+	for i := range spans {
+		if off := spans[i].ClockOffset; off != 0 {
+			shiftRecordedSpan(&spans[i], -off)
+			spans[i].ClockOffset = 0
+		}
+	}
+
+	byID := make(map[uint64]int, len(spans))
+	for i := range spans {
+		byID[spans[i].SpanID] = i
+	}
+	children := make(map[int][]int)
+	var roots []int
+	for i := range spans {
+		if p, ok := byID[spans[i].ParentSpanID]; ok && p != i {
+			children[p] = append(children[p], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+	// Remember which spans were collected together before we start changing
+	// the clock readings.
+	source := make([]time.Time, len(spans))
+	for i := range spans {
+		source[i] = spans[i].ClockReading
+	}
+
+	visited := make([]bool, len(spans))
+	var align func(i int, shift time.Duration)
+	align = func(i int, shift time.Duration) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		shiftRecordedSpan(&spans[i], shift)
+		for _, c := range children[i] {
+			var childShift time.Duration
+			if source[c].Equal(source[i]) {
+				childShift = shift
+			}
+			if start := spans[c].StartTime.Add(childShift); start.Before(spans[i].StartTime) {
+				childShift += spans[i].StartTime.Sub(start)
+			}
+			align(c, childShift)
+		}
+	}
+	for _, r := range roots {
+		align(r, 0)
+	}
+}
+
+// shiftRecordedSpan moves all the timestamps of a span by the given amount.
+func shiftRecordedSpan(sp *RecordedSpan, d time.Duration) {
+	if d == 0 {
+		return
+	}
+	sp.StartTime = sp.StartTime.Add(d)
+	if !sp.ClockReading.IsZero() {
+		sp.ClockReading = sp.ClockReading.Add(d)
+	}
+	for i := range sp.Logs {
+		sp.Logs[i].Time = sp.Logs[i].Time.Add(d)
+	}
+}
+
+// ExclusiveTime returns the time spent in the span with the given ID that is
+// not covered by its children. Children in the same parallel group count for
+// their combined wall time.
+func (r Recording) ExclusiveTime(spanID uint64) time.Duration {
+	var self *RecordedSpan
+	var sequential time.Duration
+	type interval struct{ start, end time.Time }
+	groups := make(map[string]interval)
+	for i := range r {
+		sp := &r[i]
+		if sp.SpanID == spanID {
+			self = sp
+			continue
+		}
+		if sp.ParentSpanID != spanID {
+			continue
+		}
+		if sp.ParallelGroup == "" {
+			sequential += sp.Duration
+			continue
+		}
+		end := sp.StartTime.Add(sp.Duration)
+		g, ok := groups[sp.ParallelGroup]
+		if !ok {
+			g = interval{start: sp.StartTime, end: end}
+		}
+		if sp.StartTime.Before(g.start) {
+			g.start = sp.StartTime
+		}
+		if end.After(g.end) {
+			g.end = end
+		}
+		groups[sp.ParallelGroup] = g
+	}
+	if self == nil {
+		return 0
+	}
+	children := sequential
+	for _, g := range groups {
+		children += g.end.Sub(g.start)
+	}
+	if children > self.Duration {
+		return 0
+	}
+	return self.Duration - children
+}
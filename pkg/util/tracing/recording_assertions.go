@@ -0,0 +1,211 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/util/caller"
+)
+
+// TestingT is the subset of testing.TB used by RequireRecording.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// RecordingRequirer makes assertions about the shape of a recording; see
+// RequireRecording. Assertions chained after a failure are not checked.
+type RecordingRequirer struct {
+	t   TestingT
+	rec Recording
+}
+
+// RequireRecording returns a RecordingRequirer for the given recording.
+//
+// Like TestingCheckRecordedSpans, this lives outside of a _test file so that
+// it can be used by tests in other packages.
+func RequireRecording(t TestingT, rec Recording) *RecordingRequirer {
+	return &RecordingRequirer{t: t, rec: rec}
+}
+
+// errorf reports a failed assertion. depth is the number of stack frames
+// between errorf and the test function making the assertion.
+func (r *RecordingRequirer) errorf(depth int, format string, args ...interface{}) {
+	file, line, _ := caller.Lookup(depth + 1)
+	r.t.Errorf("%s:%d %s\nrecording:\n%s", file, line, fmt.Sprintf(format, args...), r.rec)
+}
+
+// Span selects the spans with the given operation name. It is an error if
+// there are none.
+func (r *RecordingRequirer) Span(operation string) *SpanRequirer {
+	var spans []*RecordedSpan
+	for i := range r.rec {
+		if r.rec[i].Operation == operation {
+			spans = append(spans, &r.rec[i])
+		}
+	}
+	if len(spans) == 0 {
+		r.errorf(1, "no span %q", operation)
+		return &SpanRequirer{r: r, desc: operation, failed: true}
+	}
+	return &SpanRequirer{r: r, desc: operation, spans: spans}
+}
+
+// NoSpan checks that the recording has no span with the given operation name.
+func (r *RecordingRequirer) NoSpan(operation string) {
+	for i := range r.rec {
+		if r.rec[i].Operation == operation {
+			r.errorf(1, "unexpected span %q", operation)
+			return
+		}
+	}
+}
+
+// SpanRequirer makes assertions about a set of spans selected from a
+// recording. The assertions that check for the presence of something (e.g.
+// HasEvent) succeed if any of the selected spans satisfies them; the
+// assertions that check for the absence of something (e.g. NoEvent) require
+// all of the selected spans to satisfy them.
+type SpanRequirer struct {
+	r *RecordingRequirer
+	// desc describes how the spans were selected, for error messages.
+	desc  string
+	spans []*RecordedSpan
+	// failed is set when an assertion failed; all following assertions are
+	// no-ops.
+	failed bool
+}
+
+func (s *SpanRequirer) fail(format string, args ...interface{}) *SpanRequirer {
+	s.r.errorf(2, "span %s: %s", s.desc, fmt.Sprintf(format, args...))
+	s.failed = true
+	s.spans = nil
+	return s
+}
+
+func (s *SpanRequirer) filter(desc string, fn func(sp *RecordedSpan) bool) *SpanRequirer {
+	res := &SpanRequirer{r: s.r, desc: s.desc + desc, failed: s.failed}
+	for _, sp := range s.spans {
+		if fn(sp) {
+			res.spans = append(res.spans, sp)
+		}
+	}
+	return res
+}
+
+// WithTag narrows the selection down to the spans that have the given tag.
+// The value is compared to the recorded value in its fmt.Sprint form. It is an
+// error if no span remains.
+func (s *SpanRequirer) WithTag(key string, value interface{}) *SpanRequirer {
+	if s.failed {
+		return s
+	}
+	v := fmt.Sprint(value)
+	res := s.filter(fmt.Sprintf(" with %s=%s", key, v), func(sp *RecordedSpan) bool {
+		tv, ok := sp.Tags[key]
+		return ok && tv == v
+	})
+	if len(res.spans) == 0 {
+		var found []string
+		for _, sp := range s.spans {
+			if tv, ok := sp.Tags[key]; ok {
+				found = append(found, tv)
+			}
+		}
+		return s.fail("no %s=%s tag (found values: %v)", key, v, found)
+	}
+	return res
+}
+
+// Count checks that exactly n spans are selected.
+func (s *SpanRequirer) Count(n int) *SpanRequirer {
+	if s.failed {
+		return s
+	}
+	if len(s.spans) != n {
+		return s.fail("expected %d spans, found %d", n, len(s.spans))
+	}
+	return s
+}
+
+// Child selects the children of the selected spans that have the given
+// operation name. It is an error if there are none.
+func (s *SpanRequirer) Child(operation string) *SpanRequirer {
+	if s.failed {
+		return s
+	}
+	parents := make(map[uint64]struct{}, len(s.spans))
+	for _, sp := range s.spans {
+		parents[sp.SpanID] = struct{}{}
+	}
+	res := &SpanRequirer{r: s.r, desc: s.desc + " > " + operation}
+	for i := range s.r.rec {
+		sp := &s.r.rec[i]
+		if _, ok := parents[sp.ParentSpanID]; ok && sp.Operation == operation {
+			res.spans = append(res.spans, sp)
+		}
+	}
+	if len(res.spans) == 0 {
+		return s.fail("no child %q", operation)
+	}
+	return res
+}
+
+// formatEvent returns the text that HasEvent and NoEvent match against, which
+// is the same as the one used by TestingCheckRecordedSpans (e.g.
+// "event: foo").
+func formatEvent(l RecordedSpan_LogRecord) string {
+	fields := make([]string, len(l.Fields))
+	for i, f := range l.Fields {
+		fields[i] = fmt.Sprintf("%s: %v", f.Key, f.Value)
+	}
+	return strings.Join(fields, " ")
+}
+
+// HasEvent checks that one of the selected spans has an event matching the
+// given regular expression.
+func (s *SpanRequirer) HasEvent(pattern string) *SpanRequirer {
+	if s.failed {
+		return s
+	}
+	re := regexp.MustCompile(pattern)
+	for _, sp := range s.spans {
+		for _, l := range sp.Logs {
+			if re.MatchString(formatEvent(l)) {
+				return s
+			}
+		}
+	}
+	return s.fail("no event matching %q", pattern)
+}
+
+// NoEvent checks that none of the selected spans has an event matching the
+// given regular expression.
+func (s *SpanRequirer) NoEvent(pattern string) *SpanRequirer {
+	if s.failed {
+		return s
+	}
+	re := regexp.MustCompile(pattern)
+	for _, sp := range s.spans {
+		for _, l := range sp.Logs {
+			if e := formatEvent(l); re.MatchString(e) {
+				return s.fail("unexpected event %q", e)
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRequireRecording(t *testing.T) {
+	tr := NewTracer()
+	sp := tr.StartSpan("root", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	for i := 1; i <= 2; i++ {
+		child := StartChildSpan("flow", sp, false /* separateRecording */)
+		child.SetTag("node", i)
+		Recordf(child, "starting on n%d", i)
+		child.Finish()
+	}
+	sp.Finish()
+	rec := GetRecording(sp)
+
+	var rt recordingT
+	req := RequireRecording(&rt, rec)
+	req.Span("flow").Count(2).HasEvent("starting")
+	req.Span("flow").WithTag("node", 2).Count(1).HasEvent(`event: starting on n2$`).NoEvent("n1")
+	req.Span("root").Child("flow").WithTag("node", 1)
+	req.NoSpan("retry")
+	if len(rt.errors) != 0 {
+		t.Fatalf("unexpected errors: %v", rt.errors)
+	}
+
+	testCases := []struct {
+		check func(req *RecordingRequirer)
+		err   string
+	}{
+		{func(req *RecordingRequirer) { req.Span("retry") }, `no span "retry"`},
+		{func(req *RecordingRequirer) { req.NoSpan("flow") }, `unexpected span "flow"`},
+		{
+			func(req *RecordingRequirer) { req.Span("flow").WithTag("node", 3).HasEvent("x") },
+			"span flow: no node=3 tag (found values: [1 2])",
+		},
+		{
+			func(req *RecordingRequirer) { req.Span("flow").WithTag("node", 1).HasEvent("n2") },
+			`span flow with node=1: no event matching "n2"`,
+		},
+		{
+			func(req *RecordingRequirer) { req.Span("flow").NoEvent("n2") },
+			`span flow: unexpected event "event: starting on n2"`,
+		},
+		{
+			func(req *RecordingRequirer) { req.Span("flow").Child("flow") },
+			`span flow: no child "flow"`,
+		},
+		{func(req *RecordingRequirer) { req.Span("root").Count(2) }, "span root: expected 2 spans, found 1"},
+	}
+	for i, tc := range testCases {
+		var rt recordingT
+		tc.check(RequireRecording(&rt, rec))
+		if len(rt.errors) != 1 {
+			t.Errorf("%d: expected one error, got %v", i, rt.errors)
+			continue
+		}
+		// The error points to the assertion in this file.
+		if e := rt.errors[0]; !strings.Contains(e, "recording_assertions_test.go") || !strings.Contains(e, tc.err) {
+			t.Errorf("%d: expected error %q, got %q", i, tc.err, e)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import "github.com/pkg/errors"
+
+// recordingChunkOverhead is an upper bound on the encoded size of the
+// RecordingChunk fields other than the spans.
+const recordingChunkOverhead = 2 * (1 + 5)
+
+// SplitRecording splits a recording into pieces that take up at most maxBytes
+// on the wire each. A span larger than maxBytes ends up alone in a piece.
+func SplitRecording(rec Recording, maxBytes int64) [][]RecordedSpan {
+	var result [][]RecordedSpan
+	var cur []RecordedSpan
+	var curSize int64
+	for i := range rec {
+		size := Recording(rec[i : i+1]).ApproxWireSize()
+		if len(cur) > 0 && curSize+size > maxBytes {
+			result = append(result, cur)
+			cur, curSize = nil, 0
+		}
+		cur = append(cur, rec[i])
+		curSize += size
+	}
+	if len(cur) > 0 {
+		result = append(result, cur)
+	}
+	return result
+}
+
+// ChunkRecording is like SplitRecording, but the chunks carry their position so
+// that ReassembleRecording can accept them in any order.
+func ChunkRecording(rec Recording, maxBytes int64) []RecordingChunk {
+	pieces := SplitRecording(rec, maxBytes-recordingChunkOverhead)
+	if len(pieces) == 0 {
+		return []RecordingChunk{{Seq: 0, NumChunks: 1}}
+	}
+	chunks := make([]RecordingChunk, len(pieces))
+	for i, p := range pieces {
+		chunks[i] = RecordingChunk{
+			Seq:       uint32(i),
+			NumChunks: uint32(len(pieces)),
+			Spans:     p,
+		}
+	}
+	return chunks
+}
+
+// ReassembleRecording puts back together a recording from the chunks produced
+// by ChunkRecording, which can be passed in any order. An error is returned if
+// some chunks are missing or are inconsistent with each other.
+func ReassembleRecording(chunks []RecordingChunk) (Recording, error) {
+	if len(chunks) == 0 {
+		return nil, errors.New("no recording chunks")
+	}
+	n := chunks[0].NumChunks
+	if int(n) != len(chunks) {
+		return nil, errors.Errorf("expected %d recording chunks, got %d", n, len(chunks))
+	}
+	ordered := make([]*RecordingChunk, n)
+	var numSpans int
+	for i := range chunks {
+		c := &chunks[i]
+		if c.NumChunks != n {
+			return nil, errors.Errorf(
+				"inconsistent recording chunks: chunk %d is part of %d chunks, expected %d",
+				c.Seq, c.NumChunks, n)
+		}
+		if c.Seq >= n {
+			return nil, errors.Errorf("invalid recording chunk %d (out of %d)", c.Seq, n)
+		}
+		if ordered[c.Seq] != nil {
+			return nil, errors.Errorf("duplicate recording chunk %d", c.Seq)
+		}
+		ordered[c.Seq] = c
+		numSpans += len(c.Spans)
+	}
+	rec := make(Recording, 0, numSpans)
+	for _, c := range ordered {
+		rec = append(rec, c.Spans...)
+	}
+	return rec, nil
+}
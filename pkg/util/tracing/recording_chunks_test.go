@@ -0,0 +1,89 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChunkRecording(t *testing.T) {
+	var rec Recording
+	for i := 0; i < 20; i++ {
+		rec = append(rec, RecordedSpan{
+			TraceID:      1,
+			SpanID:       uint64(i + 2),
+			ParentSpanID: 1,
+			Operation:    fmt.Sprintf("op%d", i),
+			StartTime:    time.Unix(int64(i), 0).UTC(),
+			Duration:     time.Second,
+		})
+	}
+	// A span that is larger than the limit.
+	rec[7].Tags = map[string]string{"big": strings.Repeat("x", 1000)}
+
+	const maxBytes = 200
+	chunks := ChunkRecording(rec, maxBytes)
+	if len(chunks) < 3 {
+		t.Fatalf("expected at least 3 chunks, got %d", len(chunks))
+	}
+	for _, c := range chunks {
+		if size := c.Size(); size > maxBytes && len(c.Spans) > 1 {
+			t.Errorf("chunk %d has size %d (%d spans)", c.Seq, size, len(c.Spans))
+		}
+	}
+
+	// Round-trip the chunks through their encoding, and reverse their order.
+	var received []RecordingChunk
+	for i := len(chunks) - 1; i >= 0; i-- {
+		data, err := chunks[i].Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c RecordingChunk
+		if err := c.Unmarshal(data); err != nil {
+			t.Fatal(err)
+		}
+		received = append(received, c)
+	}
+	res, err := ReassembleRecording(received)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, rec) {
+		t.Errorf("expected:\n%+v\ngot:\n%+v", rec, res)
+	}
+
+	if _, err := ReassembleRecording(received[1:]); err == nil {
+		t.Error("expected error for missing chunk")
+	}
+	dup := append([]RecordingChunk(nil), received...)
+	dup[0] = dup[1]
+	if _, err := ReassembleRecording(dup); err == nil {
+		t.Error("expected error for duplicate chunk")
+	}
+
+	// An empty recording is sent as a single empty chunk.
+	chunks = ChunkRecording(nil, maxBytes)
+	if len(chunks) != 1 {
+		t.Fatalf("expected a single chunk, got %d", len(chunks))
+	}
+	if res, err := ReassembleRecording(chunks); err != nil || len(res) != 0 {
+		t.Errorf("expected empty recording, got %v, %v", res, err)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// RecordingSink consumes the recordings that are routed to it according to
+// the trace.recording.routes setting. Sinks are registered by name with
+// Tracer.RegisterRecordingSink.
+type RecordingSink interface {
+	// ConsumeRecording is called when a root span finishes, with the recording
+	// of the trace (or, if the trace was not being recorded, a recording
+	// containing only the root span). It must not block.
+	ConsumeRecording(rec Recording)
+}
+
+// RecordingSinkFunc is an adapter to use a function as a RecordingSink.
+type RecordingSinkFunc func(rec Recording)
+
+// ConsumeRecording is part of the RecordingSink interface.
+func (f RecordingSinkFunc) ConsumeRecording(rec Recording) {
+	f(rec)
+}
+
+// RecordingSinkErrors is the name of a built-in sink which adds the
+// recordings to the tracer's error buffer (see Tracer.ErrorRecordings). The
+// recordings of failed spans are always added to it, so routing them there
+// has no effect.
+const RecordingSinkErrors = "errors"
+
+var recordingRoutesSetting = settings.RegisterValidatedStringSetting(
+	"trace.recording.routes",
+	"comma-separated rules routing the recordings of finished root spans to "+
+		"sinks, in the form <match>:<sink>, where <match> is either tag=value, "+
+		"a tag name, 'error' (for failed spans) or '*', and <sink> is 'log', 'recent' or "+
+		"'errors'; the first matching rule wins",
+	"",
+	func(v string) error {
+		_, err := parseRecordingRoutes(v)
+		return err
+	},
+)
+
+// recordingRoute is a parsed rule of the trace.recording.routes setting.
+type recordingRoute struct {
+	// tag is the tag the rule matches; empty for the 'error' and '*' rules.
+	tag string
+	// value is the value of the tag; empty if the rule matches any value.
+	value string
+	// failed is set for the 'error' rule.
+	failed bool
+	sink   string
+}
+
+func parseRecordingRoutes(v string) ([]recordingRoute, error) {
+	var routes []recordingRoute
+	for _, rule := range strings.Split(v, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		i := strings.LastIndex(rule, ":")
+		if i < 0 {
+			return nil, errors.Errorf("invalid recording route %q: missing sink", rule)
+		}
+		match, sink := strings.TrimSpace(rule[:i]), strings.TrimSpace(rule[i+1:])
+		if match == "" || sink == "" {
+			return nil, errors.Errorf("invalid recording route %q", rule)
+		}
+		r := recordingRoute{sink: sink}
+		switch match {
+		case "error":
+			r.failed = true
+		case "*":
+		default:
+			if j := strings.Index(match, "="); j >= 0 {
+				r.tag, r.value = match[:j], match[j+1:]
+				if r.tag == "" || r.value == "" {
+					return nil, errors.Errorf("invalid recording route %q", rule)
+				}
+			} else {
+				r.tag = match
+			}
+		}
+		routes = append(routes, r)
+	}
+	return routes, nil
+}
+
+// parsedRecordingRoutes caches the parsed value of the setting.
+type parsedRecordingRoutes struct {
+	raw    string
+	routes []recordingRoute
+}
+
+var recordingRoutesCache atomic.Value
+
+// getRecordingRoutes returns the current rules.
+func getRecordingRoutes() []recordingRoute {
+	raw := recordingRoutesSetting.Get()
+	if raw == "" {
+		return nil
+	}
+	if p, ok := recordingRoutesCache.Load().(parsedRecordingRoutes); ok && p.raw == raw {
+		return p.routes
+	}
+	routes, _ := parseRecordingRoutes(raw)
+	recordingRoutesCache.Store(parsedRecordingRoutes{raw: raw, routes: routes})
+	return routes
+}
+
+type recordingSinks struct {
+	syncutil.Mutex
+	sinks map[string]RecordingSink
+}
+
+// RegisterRecordingSink registers a sink under the given name, which can be
+// used in the trace.recording.routes setting. Recordings routed to names with
+// no registered sink are dropped. A nil sink removes the registration.
+func (t *Tracer) RegisterRecordingSink(name string, sink RecordingSink) {
+	rs := &t.recordingSinks
+	rs.Lock()
+	defer rs.Unlock()
+	if sink == nil {
+		delete(rs.sinks, name)
+		return
+	}
+	if rs.sinks == nil {
+		rs.sinks = make(map[string]RecordingSink)
+	}
+	rs.sinks[name] = sink
+}
+
+func (rs *recordingSinks) get(name string) RecordingSink {
+	rs.Lock()
+	defer rs.Unlock()
+	return rs.sinks[name]
+}
+
+// matches returns true if the rule applies to the span.
+func (r *recordingRoute) matches(s *span) bool {
+	switch {
+	case r.failed:
+		return atomic.LoadInt32(&s.failed) != 0
+	case r.tag == "":
+		return true
+	}
+	s.mu.Lock()
+	t, ok := s.mu.tags[r.tag]
+	s.mu.Unlock()
+	return ok && (r.value == "" || fmt.Sprint(t.value) == r.value)
+}
+
+// maybeRouteRecording sends the recording of a root span to the sink selected
+// by the trace.recording.routes setting, if any. Called when the span
+// finishes.
+func (s *span) maybeRouteRecording() {
+	if s.parentSpanID != 0 {
+		return
+	}
+	routes := getRecordingRoutes()
+	for i := range routes {
+		if !routes[i].matches(s) {
+			continue
+		}
+		name := routes[i].sink
+		if name == RecordingSinkErrors {
+			if atomic.LoadInt32(&s.failed) == 0 {
+				s.tracer.errorRecordings.add(s.finishedRecording())
+				s.markRetained()
+			}
+		} else if name == RecordingSinkRecent {
+			s.tracer.recentTraces.add(s.finishedRecording())
+			s.markRetained()
+		} else if sink := s.tracer.recordingSinks.get(name); sink != nil {
+			sink.ConsumeRecording(s.finishedRecording())
+			s.markRetained()
+		}
+		return
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestParseRecordingRoutes(t *testing.T) {
+	for _, v := range []string{"", "*:default", "job_type=backup:file, error:errors ,*:x"} {
+		if _, err := parseRecordingRoutes(v); err != nil {
+			t.Errorf("%q: unexpected error %v", v, err)
+		}
+	}
+	for _, v := range []string{"job_type", ":sink", "tag=:sink", "=v:sink", "*:"} {
+		if _, err := parseRecordingRoutes(v); err == nil {
+			t.Errorf("%q: expected error", v)
+		}
+	}
+}
+
+func TestRecordingRoutes(t *testing.T) {
+	defer settings.TestingSetString(
+		&recordingRoutesSetting, "job_type=backup:file,slow:errors,*:default",
+	)()
+	tr := NewTracer().(*Tracer)
+
+	routed := make(map[string][]string)
+	sinkFor := func(name string) RecordingSink {
+		return RecordingSinkFunc(func(rec Recording) {
+			routed[name] = append(routed[name], rec[0].Operation)
+		})
+	}
+	tr.RegisterRecordingSink("file", sinkFor("file"))
+	tr.RegisterRecordingSink("default", sinkFor("default"))
+
+	backup := tr.StartSpan("backup", Recordable)
+	backup.SetTag("job_type", "backup")
+	child := tr.StartSpan("child", Recordable, opentracing.ChildOf(backup.Context()))
+	child.Finish()
+	backup.Finish()
+
+	restore := tr.StartSpan("restore", Recordable)
+	restore.SetTag("job_type", "restore")
+	restore.Finish()
+
+	slow := tr.StartSpan("slow", Recordable)
+	slow.SetTag("slow", true)
+	slow.Finish()
+
+	failed := tr.StartSpan("failed", Recordable)
+	otext.Error.Set(failed, true)
+	failed.Finish()
+
+	if r := routed["file"]; len(r) != 1 || r[0] != "backup" {
+		t.Errorf("unexpected recordings in file sink: %v", r)
+	}
+	if r := routed["default"]; len(r) != 2 || r[0] != "restore" || r[1] != "failed" {
+		t.Errorf("unexpected recordings in default sink: %v", r)
+	}
+	// The failed span is retained anyway; the slow one was routed there.
+	if recs := tr.ErrorRecordings(); len(recs) != 2 ||
+		recs[0][0].Operation != "failed" || recs[1][0].Operation != "slow" {
+		t.Errorf("unexpected error recordings: %v", recs)
+	}
+
+	// Without a registered sink, recordings are dropped.
+	tr.RegisterRecordingSink("default", nil)
+	sp := tr.StartSpan("dropped", Recordable)
+	sp.Finish()
+	if r := routed["default"]; len(r) != 2 {
+		t.Errorf("unexpected recordings in default sink: %v", r)
+	}
+}
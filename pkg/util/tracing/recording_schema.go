@@ -0,0 +1,206 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"encoding/base64"
+	"strconv"
+	"time"
+)
+
+// RecordingSchemaVersion identifies the set of RecordedSpan fields that a node
+// understands. Recordings for older nodes are downgraded by moving the newer
+// fields into tags; UpgradeRecording moves them back.
+type RecordingSchemaVersion uint32
+
+const (
+	// RecordingSchemaV1 is the original schema, with the IDs, operation,
+	// baggage, tags, timing and logs of the span. It is also assumed for peers
+	// that don't advertise a schema.
+	RecordingSchemaV1 RecordingSchemaVersion = 1 + iota
+	// RecordingSchemaV2 adds ClockReading and ClockOffset.
+	RecordingSchemaV2
+	// RecordingSchemaV3 adds NodeID and GoroutineID.
+	RecordingSchemaV3
+	// RecordingSchemaV4 adds ParallelGroup.
+	RecordingSchemaV4
+	// RecordingSchemaV5 adds StartOptions.
+	RecordingSchemaV5
+	// RecordingSchemaV6 adds StartOffset; see EncodeStartOffsets.
+	RecordingSchemaV6
+
+	// CurrentRecordingSchema is the schema of the recordings produced by this
+	// version.
+	CurrentRecordingSchema = RecordingSchemaV6
+)
+
+// Tags holding the fields of downgraded recordings.
+const (
+	tagSchemaClockReading  = "schema.clock_reading"
+	tagSchemaClockOffset   = "schema.clock_offset"
+	tagSchemaNodeID        = "schema.node_id"
+	tagSchemaGoroutineID   = "schema.goroutine_id"
+	tagSchemaParallelGroup = "schema.parallel_group"
+	// The marshaled StartOptions, base64-encoded.
+	tagSchemaStartOptions = "schema.start_options"
+)
+
+// fieldNameRecordingSchema is the carrier field through which snowball traces
+// advertise the recording schema that the requesting node understands. Older
+// nodes ignore it, and its absence means RecordingSchemaV1.
+const fieldNameRecordingSchema = prefixTracerState + "rsv"
+
+// parseRecordingSchema parses the value of the fieldNameRecordingSchema field.
+func parseRecordingSchema(v string) (RecordingSchemaVersion, error) {
+	n, err := strconv.ParseUint(v, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return RecordingSchemaV1, nil
+	}
+	return RecordingSchemaVersion(n), nil
+}
+
+// DowngradeRecording returns a copy of the recording in which the fields that
+// are not part of the given schema are moved into tags, so that a node which
+// only understands that schema doesn't drop them. The input is not modified.
+func DowngradeRecording(rec Recording, schema RecordingSchemaVersion) Recording {
+	if schema >= CurrentRecordingSchema || rec == nil {
+		return rec
+	}
+	res := make(Recording, len(rec))
+	for i := range rec {
+		res[i] = downgradeSpan(rec[i], schema)
+	}
+	return res
+}
+
+func downgradeSpan(sp RecordedSpan, schema RecordingSchemaVersion) RecordedSpan {
+	copied := false
+	setTag := func(k, v string) {
+		if !copied {
+			tags := make(map[string]string, len(sp.Tags)+1)
+			for k, v := range sp.Tags {
+				tags[k] = v
+			}
+			sp.Tags = tags
+			copied = true
+		}
+		sp.Tags[k] = v
+	}
+	if schema < RecordingSchemaV2 {
+		if !sp.ClockReading.IsZero() {
+			setTag(tagSchemaClockReading, sp.ClockReading.Format(time.RFC3339Nano))
+			sp.ClockReading = time.Time{}
+		}
+		if sp.ClockOffset != 0 {
+			setTag(tagSchemaClockOffset, sp.ClockOffset.String())
+			sp.ClockOffset = 0
+		}
+	}
+	if schema < RecordingSchemaV3 {
+		if sp.NodeID != 0 {
+			setTag(tagSchemaNodeID, strconv.FormatInt(int64(sp.NodeID), 10))
+			sp.NodeID = 0
+		}
+		if sp.GoroutineID != 0 {
+			setTag(tagSchemaGoroutineID, strconv.FormatInt(sp.GoroutineID, 10))
+			sp.GoroutineID = 0
+		}
+	}
+	if schema < RecordingSchemaV4 {
+		if sp.ParallelGroup != "" {
+			setTag(tagSchemaParallelGroup, sp.ParallelGroup)
+			sp.ParallelGroup = ""
+		}
+	}
+	if schema < RecordingSchemaV5 {
+		if sp.StartOptions != nil {
+			if data, err := sp.StartOptions.Marshal(); err == nil {
+				setTag(tagSchemaStartOptions, base64.StdEncoding.EncodeToString(data))
+			}
+			sp.StartOptions = nil
+		}
+	}
+	return sp
+}
+
+// UpgradeRecording is the inverse of DowngradeRecording: it moves the fields
+// that were stored in tags back into place. It can be applied to recordings of
+// any schema; spans that weren't downgraded are left alone. Tags with values
+// that can't be parsed are kept as they are. Start offsets are decoded too.
+func UpgradeRecording(rec Recording) {
+	for i := range rec {
+		upgradeSpan(&rec[i])
+	}
+	rec.DecodeStartOffsets()
+}
+
+func upgradeSpan(sp *RecordedSpan) {
+	if len(sp.Tags) == 0 {
+		return
+	}
+	for k, v := range sp.Tags {
+		var ok bool
+		switch k {
+		case tagSchemaClockReading:
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil && sp.ClockReading.IsZero() {
+				sp.ClockReading, ok = t, true
+			}
+		case tagSchemaClockOffset:
+			if d, err := time.ParseDuration(v); err == nil && sp.ClockOffset == 0 {
+				sp.ClockOffset, ok = d, true
+			}
+		case tagSchemaNodeID:
+			if n, err := strconv.ParseInt(v, 10, 32); err == nil && sp.NodeID == 0 {
+				sp.NodeID, ok = int32(n), true
+			}
+		case tagSchemaGoroutineID:
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil && sp.GoroutineID == 0 {
+				sp.GoroutineID, ok = n, true
+			}
+		case tagSchemaParallelGroup:
+			if sp.ParallelGroup == "" {
+				sp.ParallelGroup, ok = v, true
+			}
+		case tagSchemaStartOptions:
+			if data, err := base64.StdEncoding.DecodeString(v); err == nil && sp.StartOptions == nil {
+				var opts RecordedSpan_StartOptions
+				if err := opts.Unmarshal(data); err == nil {
+					sp.StartOptions, ok = &opts, true
+				}
+			}
+		}
+		if ok {
+			delete(sp.Tags, k)
+		}
+	}
+	if len(sp.Tags) == 0 {
+		sp.Tags = nil
+	}
+}
+
+type requesterSchemaOption struct{}
+
+func (requesterSchemaOption) apply(opts *recordingOptions) {
+	opts.forRequester = true
+}
+
+// ForRequester is a GetRecording option which downgrades the recording to the
+// schema of the node that started the snowball trace.
+func ForRequester() RecordingOption {
+	return requesterSchemaOption{}
+}
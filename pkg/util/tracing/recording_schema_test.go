@@ -0,0 +1,170 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestDowngradeRecording(t *testing.T) {
+	rec := Recording{{
+		TraceID:       1,
+		SpanID:        2,
+		Operation:     "op",
+		StartTime:     time.Unix(10, 0).UTC(),
+		Duration:      time.Second,
+		Tags:          map[string]string{"k": "v"},
+		ClockReading:  time.Unix(11, 5).UTC(),
+		ClockOffset:   -3 * time.Millisecond,
+		NodeID:        4,
+		GoroutineID:   123,
+		ParallelGroup: "scan",
+		StartOptions: &RecordedSpan_StartOptions{
+			ParentReference: ParentReferenceFollowsFrom,
+			Tags:            map[string]string{"k": "w"},
+		},
+	}}
+	orig := append(Recording(nil), rec...)
+
+	for schema := RecordingSchemaV1; schema <= CurrentRecordingSchema; schema++ {
+		down := DowngradeRecording(rec, schema)
+		if !reflect.DeepEqual(rec, orig) || len(rec[0].Tags) != 1 {
+			t.Fatalf("%d: input was modified", schema)
+		}
+		sp := down[0]
+		if (sp.NodeID != 0) != (schema >= RecordingSchemaV3) {
+			t.Errorf("%d: unexpected node ID %d", schema, sp.NodeID)
+		}
+		if (sp.Tags[tagSchemaParallelGroup] != "") != (schema < RecordingSchemaV4) {
+			t.Errorf("%d: unexpected tags %v", schema, sp.Tags)
+		}
+		if (sp.StartOptions == nil) != (schema < RecordingSchemaV5) {
+			t.Errorf("%d: unexpected start options %+v", schema, sp.StartOptions)
+		}
+		UpgradeRecording(down)
+		if !reflect.DeepEqual(down, rec) {
+			t.Errorf("%d: recording doesn't round-trip:\n%+v\n%+v", schema, down, rec)
+		}
+	}
+
+	// Tags that don't parse are left alone.
+	bad := Recording{{SpanID: 1, Tags: map[string]string{tagSchemaNodeID: "x"}}}
+	UpgradeRecording(bad)
+	if bad[0].NodeID != 0 || bad[0].Tags[tagSchemaNodeID] != "x" {
+		t.Errorf("unexpected upgrade of bad tag: %+v", bad[0])
+	}
+}
+
+func TestRecordingSchemaNegotiation(t *testing.T) {
+	tr := NewTracer()
+	tr2 := NewTracer()
+
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SnowballRecording)
+	defer root.Finish()
+
+	remoteSpan := func(carrier opentracing.TextMapCarrier) opentracing.Span {
+		wireContext, err := tr2.Extract(opentracing.TextMap, carrier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sp := tr2.StartSpan("remote", opentracing.ChildOf(wireContext), ParallelGroup("g"))
+		sp.Finish()
+		return sp
+	}
+
+	// A requester on this version gets the recording as is.
+	carrier := make(opentracing.TextMapCarrier)
+	if err := tr.Inject(root.Context(), opentracing.TextMap, carrier); err != nil {
+		t.Fatal(err)
+	}
+	rec := GetRecording(remoteSpan(carrier), ForRequester())
+	if rec[0].ParallelGroup != "g" || rec[0].Tags[tagSchemaParallelGroup] != "" {
+		t.Errorf("unexpected downgrade: %+v", rec[0])
+	}
+
+	// A requester which doesn't advertise a schema gets the oldest one.
+	delete(carrier, fieldNameRecordingSchema)
+	rec = GetRecording(remoteSpan(carrier), ForRequester())
+	if rec[0].ParallelGroup != "" || rec[0].Tags[tagSchemaParallelGroup] != "g" ||
+		rec[0].GoroutineID != 0 || !rec[0].ClockReading.IsZero() {
+		t.Errorf("expected downgrade: %+v", rec[0])
+	}
+
+	// Downgraded spans are upgraded on import.
+	if err := ImportRemoteSpans(root, rec); err != nil {
+		t.Fatal(err)
+	}
+	for _, sp := range GetRecording(root) {
+		if sp.Operation == "remote" && (sp.ParallelGroup != "g" || sp.GoroutineID == 0) {
+			t.Errorf("expected upgrade: %+v", sp)
+		}
+	}
+}
+
+func TestMarshalRecordingForSchema(t *testing.T) {
+	rec := Recording{{
+		TraceID:      1,
+		SpanID:       2,
+		Operation:    "op",
+		StartTime:    time.Unix(10, 0).UTC(),
+		Duration:     time.Second,
+		ClockReading: time.Unix(11, 0).UTC(),
+		NodeID:       3,
+	}}
+	data, err := MarshalRecordingForSchema(rec, RecordingCompressionSnappy, RecordingSchemaV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRecordingReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Schema(); s != RecordingSchemaV2 {
+		t.Errorf("expected schema %d, got %d", RecordingSchemaV2, s)
+	}
+	res, err := UnmarshalRecording(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, rec) {
+		t.Errorf("recording doesn't round-trip:\n%+v\n%+v", res, rec)
+	}
+
+	// Version 1 of the format didn't have a schema byte.
+	data, err = MarshalRecording(rec, RecordingCompressionNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := append([]byte(recordingMagic), 1, byte(RecordingCompressionNone))
+	v1 = append(v1, data[len(recordingMagic)+3:]...)
+	r, err = NewRecordingReader(bytes.NewReader(v1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Schema(); s != recordingFormatV1Schema {
+		t.Errorf("expected schema %d, got %d", recordingFormatV1Schema, s)
+	}
+	if res, err := UnmarshalRecording(v1); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, rec) {
+		t.Errorf("recording doesn't round-trip:\n%+v\n%+v", res, rec)
+	}
+}
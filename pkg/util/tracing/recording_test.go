@@ -0,0 +1,383 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestAlignRecording(t *testing.T) {
+	t0 := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return t0.Add(time.Duration(ms) * time.Millisecond) }
+	gateway, remote := at(1000), at(2000)
+
+	spans := []RecordedSpan{
+		{SpanID: 1, StartTime: at(10), ClockReading: gateway},
+		// Remote child whose clock is 5ms behind; it appears to start 3ms before
+		// its parent.
+		{SpanID: 2, ParentSpanID: 1, StartTime: at(7), ClockReading: remote,
+			Logs: []RecordedSpan_LogRecord{{Time: at(8)}}},
+		// Child of 2 recorded on the same node; it moves along with its parent.
+		{SpanID: 3, ParentSpanID: 2, StartTime: at(9), ClockReading: remote},
+		// Remote child with a known clock offset.
+		{SpanID: 4, ParentSpanID: 1, StartTime: at(40), ClockReading: at(3000),
+			ClockOffset: 20 * time.Millisecond},
+		// Local child, consistent with its parent.
+		{SpanID: 5, ParentSpanID: 1, StartTime: at(12), ClockReading: gateway},
+	}
+	AlignRecording(spans)
+
+	var starts []time.Time
+	for _, sp := range spans {
+		starts = append(starts, sp.StartTime)
+	}
+	if exp := []time.Time{at(10), at(10), at(12), at(20), at(12)}; !reflect.DeepEqual(exp, starts) {
+		t.Errorf("expected start times %v, got %v", exp, starts)
+	}
+	if exp := at(11); !spans[1].Logs[0].Time.Equal(exp) {
+		t.Errorf("expected log time %s, got %s", exp, spans[1].Logs[0].Time)
+	}
+	if spans[3].ClockOffset != 0 {
+		t.Errorf("expected clock offset to be cleared, got %s", spans[3].ClockOffset)
+	}
+}
+
+func TestRecordedSpanClockRoundTrip(t *testing.T) {
+	sp := RecordedSpan{
+		SpanID:       1,
+		StartTime:    time.Unix(10, 0).UTC(),
+		ClockReading: time.Unix(20, 5).UTC(),
+		ClockOffset:  -3 * time.Millisecond,
+	}
+	data, err := sp.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res RecordedSpan
+	if err := res.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sp, res) {
+		t.Errorf("expected %+v, got %+v", sp, res)
+	}
+}
+
+func TestMergeRecordings(t *testing.T) {
+	t0 := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return t0.Add(time.Duration(ms) * time.Millisecond) }
+
+	rec1 := Recording{
+		{TraceID: 1, SpanID: 1, Operation: "root", StartTime: at(0), Duration: time.Second},
+		{TraceID: 1, SpanID: 3, ParentSpanID: 1, Operation: "b", StartTime: at(5)},
+	}
+	rec2 := Recording{
+		// A final version of span 3.
+		{TraceID: 1, SpanID: 3, ParentSpanID: 1, Operation: "b", StartTime: at(5), Duration: time.Millisecond},
+		{TraceID: 1, SpanID: 4, ParentSpanID: 3, Operation: "c", StartTime: at(6)},
+		{TraceID: 1, SpanID: 2, ParentSpanID: 1, Operation: "a", StartTime: at(1)},
+	}
+	rec3 := Recording{
+		// A stale duplicate of the root.
+		{TraceID: 1, SpanID: 1, Operation: "root", StartTime: at(0)},
+	}
+
+	merged := MergeRecordings(rec1, rec2, rec3)
+	var ops []string
+	for _, sp := range merged {
+		ops = append(ops, sp.Operation)
+	}
+	if exp := []string{"root", "a", "b", "c"}; !reflect.DeepEqual(exp, ops) {
+		t.Fatalf("expected %v, got %v", exp, ops)
+	}
+	if merged[0].Duration != time.Second {
+		t.Errorf("expected the finished version of the root, got %+v", merged[0])
+	}
+	if merged[2].Duration != time.Millisecond {
+		t.Errorf("expected the finished version of span b, got %+v", merged[2])
+	}
+}
+
+func TestRecordingSize(t *testing.T) {
+	rec := Recording{
+		{
+			TraceID:   1,
+			SpanID:    2,
+			Operation: "root",
+			StartTime: time.Unix(10, 0),
+			Duration:  time.Second,
+			Tags:      map[string]string{"tag": "value"},
+			Logs: []RecordedSpan_LogRecord{{
+				Time:   time.Unix(10, 1),
+				Fields: []RecordedSpan_LogRecord_Field{{Key: "event", Value: "hello"}},
+			}},
+		},
+		{TraceID: 1, SpanID: 3, ParentSpanID: 2, Operation: "child"},
+	}
+
+	// The wire size is the size of a message with a repeated RecordedSpan field.
+	data, err := (&RecordingChunk{Spans: rec}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := rec.ApproxWireSize(); s != int64(len(data)) {
+		t.Errorf("expected wire size %d, got %d", len(data), s)
+	}
+
+	// The memory size accounts for the contents of the spans.
+	size := rec.MemSize()
+	rec[1].Tags = map[string]string{"big": strings.Repeat("x", 1000)}
+	if s := rec.MemSize(); s < size+1000 {
+		t.Errorf("expected a 1000 byte tag to grow the memory size from %d to at least %d, got %d",
+			size, size+1000, s)
+	}
+	if s := Recording(nil).ApproxWireSize(); s != 0 {
+		t.Errorf("expected empty recording to have no wire size, got %d", s)
+	}
+}
+
+func TestRecordingString(t *testing.T) {
+	rec := Recording{
+		{SpanID: 1, Operation: "root", Duration: 10 * time.Millisecond},
+		{SpanID: 2, ParentSpanID: 1, Operation: "child", Duration: 2 * time.Millisecond,
+			Tags: map[string]string{"k": "v", "a": "b"}},
+		{SpanID: 3, ParentSpanID: 2, Operation: "grandchild", Duration: time.Millisecond},
+		{SpanID: 4, ParentSpanID: 1, Operation: "child2"},
+		// A span whose parent is not part of the recording.
+		{SpanID: 5, ParentSpanID: 100, Operation: "orphan", Duration: time.Second},
+	}
+
+	expected := `root: 10.000ms
+    child: 2.000ms [a=b k=v]
+        grandchild: 1.000ms
+    child2: unfinished
+orphan: 1000.000ms
+`
+	if s := rec.String(); s != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, s)
+	}
+
+	expected = `digraph recording {
+  node [shape=box];
+  s1 [label="root\n10.000ms"];
+  s2 [label="child\n2.000ms\na=b k=v"];
+  s1 -> s2;
+  s3 [label="grandchild\n1.000ms"];
+  s2 -> s3;
+  s4 [label="child2\nunfinished"];
+  s1 -> s4;
+  s5 [label="orphan\n1000.000ms"];
+}
+`
+	if s := rec.ToDot(); s != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, s)
+	}
+}
+
+func TestRecordingSummary(t *testing.T) {
+	rec := Recording{
+		{SpanID: 1, Operation: "root", Duration: 10 * time.Millisecond},
+		{SpanID: 2, ParentSpanID: 1, Operation: "child1", Duration: 3 * time.Millisecond,
+			Tags: map[string]string{"k": "v"}},
+		{SpanID: 3, ParentSpanID: 1, Operation: "fast1", Duration: 200 * time.Microsecond},
+		{SpanID: 4, ParentSpanID: 1, Operation: "child2", Duration: 6 * time.Millisecond},
+		{SpanID: 5, ParentSpanID: 4, Operation: "grandchild", Duration: 5 * time.Millisecond},
+		{SpanID: 6, ParentSpanID: 1, Operation: "fast2", Duration: 300 * time.Microsecond},
+		{SpanID: 7, ParentSpanID: 6, Operation: "hidden", Duration: 100 * time.Microsecond},
+		{SpanID: 8, ParentSpanID: 1, Operation: "child3"},
+	}
+
+	expected := `root: 10.000ms
+    child2: 6.000ms (60.0%)
+        grandchild: 5.000ms (83.3%)
+    child1: 3.000ms (30.0%) [k=v]
+    2 spans under 1ms: 0.500ms (5.0%)
+    child3: unfinished
+`
+	if s := rec.Summary(); s != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, s)
+	}
+}
+
+func TestRoundTimestamps(t *testing.T) {
+	base := time.Unix(100, 0).UTC()
+	rec := Recording{
+		{
+			SpanID:       1,
+			StartTime:    base.Add(1500 * time.Millisecond),
+			Duration:     2 * time.Second,
+			ClockReading: base.Add(5200 * time.Millisecond),
+			Logs:         []RecordedSpan_LogRecord{{Time: base.Add(2700 * time.Millisecond)}},
+		},
+		// A short span which would otherwise end up with a zero duration.
+		{SpanID: 2, StartTime: base.Add(1100 * time.Millisecond), Duration: time.Millisecond},
+		// An unfinished span.
+		{SpanID: 3, StartTime: base.Add(1100 * time.Millisecond)},
+	}
+	size := rec.ApproxWireSize()
+	rec.RoundTimestamps(time.Second)
+
+	expected := Recording{
+		{
+			SpanID:       1,
+			StartTime:    base.Add(1 * time.Second),
+			Duration:     2 * time.Second,
+			ClockReading: base.Add(5 * time.Second),
+			Logs:         []RecordedSpan_LogRecord{{Time: base.Add(2 * time.Second)}},
+		},
+		{SpanID: 2, StartTime: base.Add(1 * time.Second), Duration: time.Nanosecond},
+		{SpanID: 3, StartTime: base.Add(1 * time.Second)},
+	}
+	if !reflect.DeepEqual(rec, expected) {
+		t.Errorf("expected:\n%+v\ngot:\n%+v", expected, rec)
+	}
+	if newSize := rec.ApproxWireSize(); newSize >= size {
+		t.Errorf("expected rounding to shrink the recording, but size went from %d to %d", size, newSize)
+	}
+}
+
+func TestStartOffsets(t *testing.T) {
+	base := time.Unix(1500000000, 123456789).UTC()
+	rec := Recording{
+		// The parent of the root is not part of the recording.
+		{TraceID: 1, SpanID: 2, ParentSpanID: 1, StartTime: base},
+		// A child listed before its parent.
+		{TraceID: 1, SpanID: 4, ParentSpanID: 3, StartTime: base.Add(5 * time.Millisecond)},
+		{TraceID: 1, SpanID: 3, ParentSpanID: 2, StartTime: base.Add(time.Millisecond)},
+	}
+	orig := append(Recording(nil), rec...)
+	size := rec.ApproxWireSize()
+
+	rec.EncodeStartOffsets()
+	if rec[0].StartOffset != nil || rec[1].StartOffset == nil || *rec[1].StartOffset != 4*time.Millisecond {
+		t.Fatalf("unexpected offsets: %+v", rec)
+	}
+	if newSize := rec.ApproxWireSize(); newSize >= size {
+		t.Errorf("expected offsets to shrink the recording, but size went from %d to %d", size, newSize)
+	}
+
+	var decoded Recording
+	for i := range rec {
+		data, err := rec[i].Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sp RecordedSpan
+		if err := sp.Unmarshal(data); err != nil {
+			t.Fatal(err)
+		}
+		decoded = append(decoded, sp)
+	}
+	UpgradeRecording(decoded)
+	for i := range decoded {
+		if !decoded[i].StartTime.Equal(orig[i].StartTime) || decoded[i].StartOffset != nil {
+			t.Errorf("%d: expected start time %s, got %s (offset %v)",
+				i, orig[i].StartTime, decoded[i].StartTime, decoded[i].StartOffset)
+		}
+	}
+}
+
+func TestMarshalRecording(t *testing.T) {
+	var rec Recording
+	for i := 0; i < 100; i++ {
+		rec = append(rec, RecordedSpan{
+			TraceID:      1,
+			SpanID:       uint64(i + 2),
+			ParentSpanID: 1,
+			Operation:    "dist sender send",
+			StartTime:    time.Unix(int64(i), 0).UTC(),
+			Duration:     time.Second,
+			Tags:         map[string]string{"node": "1", "range": "r42"},
+			Logs: []RecordedSpan_LogRecord{{
+				Time: time.Unix(int64(i), 1).UTC(),
+				Fields: []RecordedSpan_LogRecord_Field{
+					{Key: "event", Value: "sending batch 1 Get to r42:/Table/51/1/{1-2}"},
+				},
+			}},
+		})
+	}
+	uncompressed, err := MarshalRecording(rec, RecordingCompressionNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []RecordingCompression{
+		RecordingCompressionNone, RecordingCompressionSnappy, RecordingCompressionGzip,
+	} {
+		data, err := MarshalRecording(rec, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c != RecordingCompressionNone && 4*len(data) > len(uncompressed) {
+			t.Errorf("%d: expected good compression, got %d bytes (from %d)", c, len(data), len(uncompressed))
+		}
+		res, err := UnmarshalRecording(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(res, rec) {
+			t.Errorf("%d: recording doesn't round-trip", c)
+		}
+		if _, err := UnmarshalRecording(data[:len(data)-3]); err == nil {
+			t.Errorf("%d: expected error for truncated recording", c)
+		}
+	}
+	if _, err := UnmarshalRecording([]byte("garbage")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestExclusiveTime(t *testing.T) {
+	t0 := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return t0.Add(time.Duration(ms) * time.Millisecond) }
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+	rec := Recording{
+		{SpanID: 1, StartTime: at(0), Duration: ms(100)},
+		// Sequential child.
+		{SpanID: 2, ParentSpanID: 1, StartTime: at(0), Duration: ms(20)},
+		// Three parallel children, running from 30ms to 70ms.
+		{SpanID: 3, ParentSpanID: 1, StartTime: at(30), Duration: ms(30), ParallelGroup: "rpcs"},
+		{SpanID: 4, ParentSpanID: 1, StartTime: at(35), Duration: ms(35), ParallelGroup: "rpcs"},
+		{SpanID: 5, ParentSpanID: 1, StartTime: at(40), Duration: ms(10), ParallelGroup: "rpcs"},
+		// Grandchild; it doesn't count.
+		{SpanID: 6, ParentSpanID: 4, StartTime: at(40), Duration: ms(30)},
+	}
+	if d := rec.ExclusiveTime(1); d != ms(40) {
+		t.Errorf("expected 40ms, got %s", d)
+	}
+	if d := rec.ExclusiveTime(4); d != ms(5) {
+		t.Errorf("expected 5ms, got %s", d)
+	}
+	if d := rec.ExclusiveTime(7); d != 0 {
+		t.Errorf("expected 0 for unknown span, got %s", d)
+	}
+
+	// The group is recorded.
+	tr := NewTracer()
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SingleNodeRecording)
+	child := tr.StartSpan(
+		"child", opentracing.ChildOf(root.Context()), ParallelGroup("rpcs"),
+	)
+	child.Finish()
+	root.Finish()
+	if g := GetRecording(root)[1].ParallelGroup; g != "rpcs" {
+		t.Errorf("expected group rpcs, got %q", g)
+	}
+}
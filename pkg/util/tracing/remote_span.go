@@ -0,0 +1,103 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sort"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+)
+
+// ChildSpanRemote is like ChildSpan, for work scheduled on a remote node. It
+// also returns the span's metadata, which the remote node passes to
+// ImportSpanMeta. The metadata is nil if there is no span in ctx.
+func ChildSpanRemote(
+	ctx context.Context, opName string,
+) (context.Context, opentracing.Span, []byte, error) {
+	ctx, sp := ChildSpan(ctx, opName)
+	if sp == nil {
+		return ctx, nil, nil, nil
+	}
+	carrier := make(opentracing.TextMapCarrier)
+	if err := sp.Tracer().Inject(sp.Context(), opentracing.TextMap, carrier); err != nil {
+		return ctx, sp, nil, err
+	}
+	return ctx, sp, encodeCarrier(carrier), nil
+}
+
+// ImportSpanMeta is the counterpart of ChildSpanRemote: it opens a span on the
+// given tracer as a child of the span whose metadata was encoded in blob. If
+// the blob is empty, a new root span is opened. The returned context contains
+// the new span, which needs to be closed via FinishSpan.
+func ImportSpanMeta(
+	ctx context.Context, tr opentracing.Tracer, blob []byte, opName string,
+) (context.Context, opentracing.Span, error) {
+	carrier, err := decodeCarrier(blob)
+	if err != nil {
+		return ctx, nil, err
+	}
+	var sp opentracing.Span
+	if len(carrier) == 0 {
+		sp = tr.StartSpan(opName)
+	} else {
+		parentCtx, err := tr.Extract(opentracing.TextMap, carrier)
+		if err != nil {
+			return ctx, nil, err
+		}
+		sp = tr.StartSpan(opName, opentracing.ChildOf(parentCtx))
+	}
+	return opentracing.ContextWithSpan(ctx, sp), sp, nil
+}
+
+// encodeCarrier serializes a text map as a sequence of length-prefixed keys and
+// values. The keys are sorted so that the encoding is deterministic.
+func encodeCarrier(carrier opentracing.TextMapCarrier) []byte {
+	if len(carrier) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(carrier))
+	for k := range carrier {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf []byte
+	for _, k := range keys {
+		buf = appendBytes(buf, []byte(k))
+		buf = appendBytes(buf, []byte(carrier[k]))
+	}
+	return buf
+}
+
+// decodeCarrier is the inverse of encodeCarrier.
+func decodeCarrier(buf []byte) (opentracing.TextMapCarrier, error) {
+	if len(buf) == 0 {
+		return nil, nil
+	}
+	carrier := make(opentracing.TextMapCarrier)
+	for len(buf) > 0 {
+		var k, v []byte
+		var err error
+		if k, buf, err = readBytes(buf); err != nil {
+			return nil, opentracing.ErrSpanContextCorrupted
+		}
+		if v, buf, err = readBytes(buf); err != nil {
+			return nil, opentracing.ErrSpanContextCorrupted
+		}
+		carrier[string(k)] = string(v)
+	}
+	return carrier, nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sort"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// ReplayStats are the statistics of a Replay.
+type ReplayStats struct {
+	// Number of spans and log records that were created.
+	Spans int
+	Logs  int
+	// Wall time spent replaying the recording, i.e. in the tracer (and in the
+	// shadow tracer, if any).
+	Elapsed time.Duration
+}
+
+// PerSpan returns the average time it took to replay a span.
+func (s ReplayStats) PerSpan() time.Duration {
+	if s.Spans == 0 {
+		return 0
+	}
+	return s.Elapsed / time.Duration(s.Spans)
+}
+
+// replaySkippedTags are the tags that the tracer sets by itself; they are not
+// replayed.
+var replaySkippedTags = map[string]struct{}{
+	Snowball:          {},
+	TagSamplingReason: {},
+	TagTagsDropped:    {},
+	TagLinkTraceID:    {},
+	TagLinkSpanID:     {},
+}
+
+// Replay recreates the spans of a recording with the given tracer, shifted so
+// that the trace starts now, e.g. to measure the overhead of the tracer. The
+// root spans are children of the span in ctx, if any.
+func Replay(ctx context.Context, tr opentracing.Tracer, rec Recording) (ReplayStats, error) {
+	r := replayer{tr: tr, children: make(map[uint64][]*RecordedSpan, len(rec))}
+	if len(rec) == 0 {
+		return r.stats, nil
+	}
+	ids := make(map[uint64]struct{}, len(rec))
+	for i := range rec {
+		ids[rec[i].SpanID] = struct{}{}
+	}
+	var roots []*RecordedSpan
+	start := rec[0].StartTime
+	for i := range rec {
+		sp := &rec[i]
+		if sp.StartTime.Before(start) {
+			start = sp.StartTime
+		}
+		if _, ok := ids[sp.ParentSpanID]; ok && sp.ParentSpanID != sp.SpanID {
+			r.children[sp.ParentSpanID] = append(r.children[sp.ParentSpanID], sp)
+		} else {
+			roots = append(roots, sp)
+		}
+	}
+	for _, c := range r.children {
+		sort.Sort(spansByStartTime(c))
+	}
+	sort.Sort(spansByStartTime(roots))
+
+	parent := opentracing.SpanFromContext(ctx)
+	now := time.Now()
+	r.shift = now.Sub(start)
+	for _, sp := range roots {
+		if err := r.replaySpan(parent, sp); err != nil {
+			return r.stats, err
+		}
+	}
+	r.stats.Elapsed = time.Since(now)
+	return r.stats, nil
+}
+
+type spansByStartTime []*RecordedSpan
+
+func (s spansByStartTime) Len() int           { return len(s) }
+func (s spansByStartTime) Less(i, j int) bool { return s[i].StartTime.Before(s[j].StartTime) }
+func (s spansByStartTime) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type replayer struct {
+	tr opentracing.Tracer
+	// children maps span IDs to the recorded children of the span, ordered by
+	// start time.
+	children map[uint64][]*RecordedSpan
+	// shift is added to all the recorded timestamps.
+	shift time.Duration
+	stats ReplayStats
+}
+
+// replaySpan recreates a recorded span and its descendants.
+func (r *replayer) replaySpan(parent opentracing.Span, rs *RecordedSpan) error {
+	opts := []opentracing.StartSpanOption{opentracing.StartTime(rs.StartTime.Add(r.shift))}
+	startOpts := rs.StartOptions
+	if startOpts == nil {
+		startOpts = &RecordedSpan_StartOptions{}
+	}
+	remote := parent != nil && startOpts.RemoteParent
+	if parent != nil {
+		parentCtx := parent.Context()
+		if remote {
+			var err error
+			if parentCtx, err = r.propagate(parentCtx); err != nil {
+				return err
+			}
+		}
+		refType := opentracing.ChildOfRef
+		if startOpts.ParentReference == ParentReferenceFollowsFrom {
+			refType = opentracing.FollowsFromRef
+		}
+		opts = append(opts, opentracing.SpanReference{Type: refType, ReferencedContext: parentCtx})
+	}
+	for k, v := range startOpts.Tags {
+		opts = append(opts, opentracing.Tag{Key: k, Value: replayTagValue(k, v)})
+	}
+	if rs.ParallelGroup != "" {
+		opts = append(opts, ParallelGroup(rs.ParallelGroup))
+	}
+
+	sp := r.tr.StartSpan(rs.Operation, opts...)
+	r.stats.Spans++
+	for k, v := range rs.Tags {
+		if _, ok := replaySkippedTags[k]; ok {
+			continue
+		}
+		if sv, ok := startOpts.Tags[k]; ok && sv == v {
+			continue
+		}
+		sp.SetTag(k, replayTagValue(k, v))
+	}
+	for _, l := range rs.Logs {
+		fields := make([]otlog.Field, len(l.Fields))
+		for i, f := range l.Fields {
+			fields[i] = otlog.String(f.Key, f.Value)
+		}
+		sp.LogFields(fields...)
+		r.stats.Logs++
+	}
+	for _, c := range r.children[rs.SpanID] {
+		if err := r.replaySpan(sp, c); err != nil {
+			sp.Finish()
+			return err
+		}
+	}
+	sp.FinishWithOptions(opentracing.FinishOptions{
+		FinishTime: rs.StartTime.Add(r.shift + rs.Duration),
+	})
+	if remote {
+		return importReplayedSpans(parent, sp)
+	}
+	return nil
+}
+
+// importReplayedSpans imports the recording of a span with a remote parent
+// into the parent's recording, if both are spans of this package's tracer and
+// are recording.
+func importReplayedSpans(parent, sp opentracing.Span) error {
+	if p, ok := parent.(*span); !ok || !p.isRecording() {
+		return nil
+	}
+	if _, ok := sp.(*span); !ok {
+		return nil
+	}
+	if rec := GetRecording(sp); rec != nil {
+		return ImportRemoteSpans(parent, rec)
+	}
+	return nil
+}
+
+// propagate sends a span context through Inject and Extract, as if the child
+// span was started on another node.
+func (r *replayer) propagate(sc opentracing.SpanContext) (opentracing.SpanContext, error) {
+	carrier := make(opentracing.TextMapCarrier)
+	if err := r.tr.Inject(sc, opentracing.TextMap, carrier); err != nil {
+		return nil, errors.Wrap(err, "injecting span context")
+	}
+	res, err := r.tr.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		return nil, errors.Wrap(err, "extracting span context")
+	}
+	return res, nil
+}
+
+// replayTagValue converts a recorded tag value back to the type used by the
+// standard tags where it matters (i.e. error=true, which marks the span as
+// failed).
+func replayTagValue(key, value string) interface{} {
+	if key == string(otext.Error) && value == "true" {
+		return true
+	}
+	return value
+}
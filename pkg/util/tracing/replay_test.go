@@ -0,0 +1,114 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+)
+
+func TestReplay(t *testing.T) {
+	at := func(sec int64) time.Time { return time.Unix(sec, 0).UTC() }
+	rec := Recording{
+		{
+			TraceID:   1,
+			SpanID:    1,
+			Operation: "root",
+			StartTime: at(100),
+			Duration:  10 * time.Second,
+			Tags:      map[string]string{Snowball: "1", "k": "v"},
+			Logs: []RecordedSpan_LogRecord{{
+				Time:   at(101),
+				Fields: []RecordedSpan_LogRecord_Field{{Key: "event", Value: "hello"}},
+			}},
+		},
+		{
+			TraceID:      1,
+			SpanID:       3,
+			ParentSpanID: 1,
+			Operation:    "b",
+			StartTime:    at(105),
+			Duration:     time.Second,
+			Tags:         map[string]string{"error": "true"},
+			StartOptions: &RecordedSpan_StartOptions{ParentReference: ParentReferenceFollowsFrom},
+		},
+		{
+			TraceID:      1,
+			SpanID:       2,
+			ParentSpanID: 1,
+			Operation:    "a",
+			StartTime:    at(102),
+			Duration:     2 * time.Second,
+			Tags:         map[string]string{"x": "1"},
+			StartOptions: &RecordedSpan_StartOptions{
+				ParentReference: ParentReferenceChildOf,
+				RemoteParent:    true,
+				Tags:            map[string]string{"x": "1"},
+			},
+		},
+	}
+
+	tr := NewTracer()
+	parent := tr.StartSpan("replay", Recordable)
+	StartRecording(parent, SnowballRecording)
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+	stats, err := Replay(ctx, tr, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent.Finish()
+	if stats.Spans != 3 || stats.Logs != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	replayed := GetRecording(parent)
+	if err := TestingCheckRecordedSpans(replayed, `
+		span replay:
+			tags: sb=1
+		span root:
+			tags: k=v sb=1
+			event: hello
+		span b:
+			tags: error=true sb=1
+		span a:
+			tags: sb=1 x=1
+	`); err != nil {
+		t.Fatal(err)
+	}
+	byOp := make(map[string]RecordedSpan)
+	for _, sp := range replayed {
+		byOp[sp.Operation] = sp
+	}
+	for _, orig := range rec {
+		sp := byOp[orig.Operation]
+		if sp.Duration != orig.Duration {
+			t.Errorf("%s: expected duration %s, got %s", orig.Operation, orig.Duration, sp.Duration)
+		}
+		if exp := orig.StartTime.Sub(at(100)); sp.StartTime.Sub(byOp["root"].StartTime) != exp {
+			t.Errorf("%s: expected start offset %s", orig.Operation, exp)
+		}
+	}
+	if byOp["root"].ParentSpanID != byOp["replay"].SpanID ||
+		byOp["a"].ParentSpanID != byOp["root"].SpanID {
+		t.Errorf("unexpected hierarchy: %+v", replayed)
+	}
+	if !byOp["a"].StartOptions.RemoteParent ||
+		byOp["b"].StartOptions.ParentReference != ParentReferenceFollowsFrom {
+		t.Errorf("start options not replayed: %+v %+v", byOp["a"].StartOptions, byOp["b"].StartOptions)
+	}
+}
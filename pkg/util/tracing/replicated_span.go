@@ -0,0 +1,124 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"encoding/binary"
+	"strconv"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// The span metadata embedded in replicated commands (e.g. Raft proposals) is
+// encoded as:
+//
+//   version (1 byte) | flags (1 byte) | trace ID (8 bytes) | span ID (8 bytes)
+//
+// optionally followed by the baggage and shadow fields of the span context,
+// encoded by encodeCarrier. EncodeProposalSpan never includes them;
+// SpanMetaForWire includes as many as fit in the caller's budget.
+const (
+	replicatedSpanMetaVersion = 1
+	replicatedSpanMetaLen     = 18
+)
+
+const (
+	// replicatedFlagRecording is set if the proposing span was being recorded;
+	// it causes the application span to be a real span even when tracing is
+	// otherwise disabled.
+	replicatedFlagRecording = 1 << iota
+)
+
+// EncodeProposalSpan returns a compact encoding of the IDs of the given span,
+// to be embedded in a replicated command; see StartApplicationSpan. It returns
+// nil for noop spans.
+func EncodeProposalSpan(os opentracing.Span) []byte {
+	sp, ok := os.(*span)
+	if !ok {
+		return nil
+	}
+	var flags byte
+	if sp.isRecording() {
+		flags |= replicatedFlagRecording
+	}
+	return encodeReplicatedSpanMeta(sp.spanMeta, flags, nil)
+}
+
+// encodeReplicatedSpanMeta encodes the IDs and flags of a span, followed by
+// the given fields.
+func encodeReplicatedSpanMeta(m spanMeta, flags byte, fields opentracing.TextMapCarrier) []byte {
+	buf := make([]byte, replicatedSpanMetaLen)
+	buf[0] = replicatedSpanMetaVersion
+	buf[1] = flags
+	binary.BigEndian.PutUint64(buf[2:], m.TraceID)
+	binary.BigEndian.PutUint64(buf[10:], m.SpanID)
+	return append(buf, encodeCarrier(fields)...)
+}
+
+// decodeReplicatedSpanMeta is the inverse of encodeReplicatedSpanMeta.
+func decodeReplicatedSpanMeta(buf []byte) (spanMeta, byte, opentracing.TextMapCarrier, error) {
+	if len(buf) < replicatedSpanMetaLen || buf[0] != replicatedSpanMetaVersion {
+		return spanMeta{}, 0, nil, errors.Errorf("invalid replicated span metadata (%d bytes)", len(buf))
+	}
+	m := spanMeta{
+		TraceID: binary.BigEndian.Uint64(buf[2:]),
+		SpanID:  binary.BigEndian.Uint64(buf[10:]),
+	}
+	if m.TraceID == 0 || m.SpanID == 0 {
+		return spanMeta{}, 0, nil, errors.New("invalid replicated span metadata (zero IDs)")
+	}
+	fields, err := decodeCarrier(buf[replicatedSpanMetaLen:])
+	if err != nil {
+		return spanMeta{}, 0, nil, err
+	}
+	return m, buf[1], fields, nil
+}
+
+// StartApplicationSpan opens a span for the application of a replicated
+// command, which follows from the span encoded by EncodeProposalSpan. The span
+// should be closed via FinishSpan.
+func StartApplicationSpan(
+	ctx context.Context, tr opentracing.Tracer, encoded []byte, opName string,
+) (context.Context, opentracing.Span, error) {
+	t, ok := tr.(*Tracer)
+	if len(encoded) == 0 || !ok {
+		sp := tr.StartSpan(opName)
+		return opentracing.ContextWithSpan(ctx, sp), sp, nil
+	}
+	m, flags, fields, err := decodeReplicatedSpanMeta(encoded)
+	if err != nil {
+		return ctx, nil, err
+	}
+	var parent opentracing.SpanContext = &spanContext{spanMeta: m}
+	if len(fields) > 0 {
+		// The fields were written by one of our nodes, so the baggage is not
+		// subjected to the BaggageAuthorizer.
+		fields[fieldNameTraceID] = strconv.FormatUint(m.TraceID, 16)
+		fields[fieldNameSpanID] = strconv.FormatUint(m.SpanID, 16)
+		if parent, err = t.extract(opentracing.TextMap, fields, false /* authorize */); err != nil {
+			return ctx, nil, err
+		}
+	}
+	ref := opentracing.FollowsFrom(parent)
+	var sp opentracing.Span
+	if flags&replicatedFlagRecording != 0 {
+		sp = t.StartSpan(opName, ref, Recordable)
+	} else {
+		sp = t.StartSpan(opName, ref)
+	}
+	return opentracing.ContextWithSpan(ctx, sp), sp, nil
+}
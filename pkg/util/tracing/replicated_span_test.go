@@ -0,0 +1,71 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestApplicationSpan(t *testing.T) {
+	tr := NewTracer()
+	tr2 := NewTracer()
+
+	if buf := EncodeProposalSpan(tr.StartSpan("noop")); buf != nil {
+		t.Fatalf("expected no encoding for noop span, got %x", buf)
+	}
+
+	proposal := tr.StartSpan("propose", Recordable)
+	StartRecording(proposal, SnowballRecording)
+	proposal.SetBaggageItem("k", "v")
+	buf := EncodeProposalSpan(proposal)
+	if len(buf) != replicatedSpanMetaLen {
+		t.Fatalf("unexpected encoding length %d", len(buf))
+	}
+
+	_, sp, err := StartApplicationSpan(context.Background(), tr2, buf, "apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, ok := sp.(*span)
+	if !ok {
+		t.Fatalf("expected real span, got %T", sp)
+	}
+	p := proposal.(*span)
+	if app.TraceID != p.TraceID || app.parentSpanID != p.SpanID {
+		t.Errorf("application span not linked to proposal: %+v", app.spanMeta)
+	}
+	if app.isRecording() || app.BaggageItem("k") != "" {
+		t.Error("application span should not inherit recording or baggage")
+	}
+	sp.Finish()
+
+	// The proposal wasn't recording; with tracing disabled, the application
+	// span is a noop.
+	StopRecording(proposal)
+	_, sp, err = StartApplicationSpan(context.Background(), tr2, EncodeProposalSpan(proposal), "apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, noop := sp.(*noopSpan); !noop {
+		t.Errorf("expected noop span, got %T", sp)
+	}
+	proposal.Finish()
+
+	if _, _, err := StartApplicationSpan(context.Background(), tr2, buf[1:], "apply"); err == nil {
+		t.Error("expected error for corrupted encoding")
+	}
+}
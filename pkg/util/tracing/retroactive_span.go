@@ -0,0 +1,57 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+	"golang.org/x/net/context"
+)
+
+// TagSlowThreshold is set on the spans created by RunWithRetroactiveSpan to
+// the threshold that the operation exceeded.
+const TagSlowThreshold = "slow_threshold"
+
+// RunWithRetroactiveSpan runs fn and, only if it takes longer than threshold,
+// creates a child span for it after the fact with the events that fn buffered.
+func RunWithRetroactiveSpan(
+	ctx context.Context, opName string, threshold time.Duration, fn func(context.Context) error,
+) error {
+	parent := opentracing.SpanFromContext(ctx)
+	if parent == nil || IsBlackHoleSpan(parent) {
+		return fn(ctx)
+	}
+	start := time.Now()
+	fnCtx, buf := WithEventBuffer(ctx)
+	err := fn(fnCtx)
+	end := time.Now()
+	if end.Sub(start) < threshold {
+		return err
+	}
+
+	sp := parent.Tracer().StartSpan(
+		opName, opentracing.ChildOf(parent.Context()), opentracing.StartTime(start),
+	)
+	sp.SetTag(TagSlowThreshold, threshold.String())
+	buf.Flush(sp)
+	if err != nil {
+		otext.Error.Set(sp, true)
+		sp.LogKV("event", "error", "error", err.Error())
+	}
+	sp.FinishWithOptions(opentracing.FinishOptions{FinishTime: end})
+	return err
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+func TestRunWithRetroactiveSpan(t *testing.T) {
+	tr := NewTracer()
+	sp := tr.StartSpan("parent", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	ctx := opentracing.ContextWithSpan(context.Background(), sp)
+
+	// A fast operation doesn't get a span.
+	if err := RunWithRetroactiveSpan(ctx, "fast", time.Hour, func(ctx context.Context) error {
+		RecordEvent(ctx, "fast event")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A slow one does.
+	var start time.Time
+	err := RunWithRetroactiveSpan(ctx, "slow", time.Millisecond, func(ctx context.Context) error {
+		start = time.Now()
+		RecordEvent(ctx, "slow event")
+		time.Sleep(2 * time.Millisecond)
+		return errors.New("boom")
+	})
+	if err == nil || err.Error() != "boom" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	sp.Finish()
+
+	rec := GetRecording(sp)
+	if err := TestingCheckRecordedSpans(rec, `
+		span parent:
+		span slow:
+			tags: error=true slow_threshold=1ms
+			event: slow event
+			event: error  error: boom
+	`); err != nil {
+		t.Fatal(err)
+	}
+	if slow := rec[1]; slow.StartTime.After(start) || slow.Duration < 2*time.Millisecond {
+		t.Fatalf("unexpected start time or duration: %s %s", slow.StartTime, slow.Duration)
+	}
+}
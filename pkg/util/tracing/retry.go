@@ -0,0 +1,62 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+)
+
+// Tags maintained by AnnotateRetry.
+const (
+	// TagRetries is the number of retries annotated on the span.
+	TagRetries = "retries"
+	// TagRetryBackoff is the total backoff of the retries annotated on the span.
+	TagRetryBackoff = "retry_backoff"
+)
+
+// AnnotateRetry logs a structured "retry" event in the span and updates the
+// TagRetries and TagRetryBackoff totals.
+func AnnotateRetry(os opentracing.Span, attempt int, backoff time.Duration, err error) {
+	if os == nil {
+		return
+	}
+	if _, noop := os.(*noopSpan); noop {
+		return
+	}
+	fields := []otlog.Field{
+		otlog.String("event", "retry"),
+		otlog.Int("attempt", attempt),
+		otlog.String("backoff", backoff.String()),
+	}
+	if err != nil {
+		fields = append(fields, otlog.String("error", err.Error()))
+	}
+	os.LogFields(fields...)
+
+	s, ok := os.(*span)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	s.mu.retries++
+	s.mu.retryBackoff += backoff
+	retries, retryBackoff := s.mu.retries, s.mu.retryBackoff
+	s.mu.Unlock()
+	s.SetTag(TagRetries, retries)
+	s.SetTag(TagRetryBackoff, retryBackoff.String())
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestAnnotateRetry(t *testing.T) {
+	tr := NewTracer()
+
+	// Noop spans are ignored.
+	AnnotateRetry(tr.StartSpan("noop"), 1, time.Second, nil)
+
+	sp := tr.StartSpan("a", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	AnnotateRetry(sp, 1, 10*time.Millisecond, errors.New("boom"))
+	AnnotateRetry(sp, 2, 20*time.Millisecond, nil)
+	sp.Finish()
+
+	if err := TestingCheckRecordedSpans(GetRecording(sp), `
+		span a:
+			tags: retries=2 retry_backoff=30ms
+			event: retry  attempt: 1  backoff: 10ms  error: boom
+			event: retry  attempt: 2  backoff: 20ms
+	`); err != nil {
+		t.Fatal(err)
+	}
+}
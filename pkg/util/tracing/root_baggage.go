@@ -0,0 +1,113 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+var rootBaggageSetting = settings.RegisterValidatedStringSetting(
+	"trace.root_baggage",
+	"comma-separated baggage items placed in every new root span, in the form "+
+		"<key>=<value> (e.g. 'cluster=prod-east,env=production'), so that every "+
+		"node handling part of a trace sees deployment-wide identifiers",
+	"",
+	func(v string) error {
+		_, err := parseRootBaggage(v)
+		return err
+	},
+)
+
+// rootBaggageItem is a baggage item from the trace.root_baggage setting.
+type rootBaggageItem struct {
+	key, value string
+}
+
+func parseRootBaggage(v string) ([]rootBaggageItem, error) {
+	var res []rootBaggageItem
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		i := strings.Index(item, "=")
+		if i < 0 {
+			return nil, errors.Errorf("invalid baggage item %q: missing '='", item)
+		}
+		key, value := strings.TrimSpace(item[:i]), strings.TrimSpace(item[i+1:])
+		if key == "" {
+			return nil, errors.Errorf("invalid baggage item %q", item)
+		}
+		// The items are placed in every trace, so they must not change how
+		// traces are recorded.
+		if privilegedBaggage[key] {
+			return nil, errors.Errorf("baggage item %q is reserved", key)
+		}
+		if seen[key] {
+			return nil, errors.Errorf("duplicate baggage item %q", key)
+		}
+		seen[key] = true
+		res = append(res, rootBaggageItem{key: key, value: value})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].key < res[j].key })
+	return res, nil
+}
+
+// parsedRootBaggage caches the parsed value of the setting.
+type parsedRootBaggage struct {
+	raw   string
+	items []rootBaggageItem
+}
+
+var rootBaggageCache atomic.Value
+
+// rootBaggage returns the baggage items from the trace.root_baggage setting.
+func rootBaggage() []rootBaggageItem {
+	raw := rootBaggageSetting.Get()
+	if raw == "" {
+		return nil
+	}
+	p, ok := rootBaggageCache.Load().(parsedRootBaggage)
+	if !ok || p.raw != raw {
+		items, _ := parseRootBaggage(raw)
+		p = parsedRootBaggage{raw: raw, items: items}
+		rootBaggageCache.Store(p)
+	}
+	return p.items
+}
+
+// setRootBaggage places the items of the trace.root_baggage setting in the
+// baggage of a new root span. Items already present (i.e. set through
+// start options) are left alone.
+func (s *span) setRootBaggage() {
+	items := rootBaggage()
+	if len(items) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, it := range items {
+		if _, ok := s.mu.Baggage[it.key]; !ok {
+			s.setBaggageItemLocked(it.key, it.value)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestParseRootBaggage(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		expErr bool
+	}{
+		{in: ""},
+		{in: "cluster=east, env=prod,"},
+		{in: "cluster", expErr: true},
+		{in: "=east", expErr: true},
+		{in: Snowball + "=1", expErr: true},
+		{in: "a=1,a=2", expErr: true},
+	} {
+		items, err := parseRootBaggage(tc.in)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%q: unexpected error %v", tc.in, err)
+		}
+		if tc.in == "cluster=east, env=prod," &&
+			(len(items) != 2 || items[0] != (rootBaggageItem{"cluster", "east"})) {
+			t.Errorf("%q: unexpected items %v", tc.in, items)
+		}
+	}
+}
+
+func TestRootBaggage(t *testing.T) {
+	defer settings.TestingSetString(&rootBaggageSetting, "cluster=east,env=prod")()
+	tr := NewTracer()
+	tr2 := NewTracer()
+
+	root := tr.StartSpan("root", Recordable)
+	root.SetBaggageItem("env", "staging")
+	if v := root.BaggageItem("cluster"); v != "east" {
+		t.Errorf("expected the cluster in the baggage, got %q", v)
+	}
+
+	// The items reach the spans of other nodes.
+	carrier := opentracing.TextMapCarrier{}
+	if err := tr.Inject(root.Context(), opentracing.TextMap, carrier); err != nil {
+		t.Fatal(err)
+	}
+	wireCtx, err := tr2.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := tr2.StartSpan("remote", opentracing.ChildOf(wireCtx), Recordable)
+	if v := remote.BaggageItem("cluster"); v != "east" {
+		t.Errorf("expected the cluster in the remote baggage, got %q", v)
+	}
+	// The items set on the trace take precedence.
+	if v := remote.BaggageItem("env"); v != "staging" {
+		t.Errorf("expected env=staging, got %q", v)
+	}
+	remote.Finish()
+	root.Finish()
+}
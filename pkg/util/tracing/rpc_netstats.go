@@ -0,0 +1,206 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sync/atomic"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/stats"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// Tags set on RPC client spans by SetRPCNetworkStats.
+const (
+	TagNetBytesSent     = "net.bytes_sent"
+	TagNetBytesReceived = "net.bytes_received"
+	TagNetMsgsSent      = "net.msgs_sent"
+	TagNetMsgsReceived  = "net.msgs_received"
+	TagNetPeerNodeID    = "net.peer_node_id"
+	TagNetRemoteAddr    = "net.remote_addr"
+	TagNetConnReused    = "net.conn_reused"
+)
+
+// RPCNetworkStats are the network statistics of an RPC.
+type RPCNetworkStats struct {
+	// Bytes sent and received on the wire (after compression and framing),
+	// for the messages of the RPC.
+	BytesSent     int64
+	BytesReceived int64
+	MsgsSent      int64
+	MsgsReceived  int64
+	// ID of the node the RPC was sent to; zero if unknown. See
+	// ContextWithPeerNodeID.
+	PeerNodeID int32
+	RemoteAddr string
+	// ConnReused is set if the RPC was sent on a connection which was
+	// established before the RPC started.
+	ConnReused bool
+}
+
+// SetRPCNetworkStats sets the statistics as (typed) tags on the span.
+func SetRPCNetworkStats(sp opentracing.Span, st RPCNetworkStats) {
+	if IsBlackHoleSpan(sp) {
+		return
+	}
+	sp.SetTag(TagNetBytesSent, st.BytesSent)
+	sp.SetTag(TagNetBytesReceived, st.BytesReceived)
+	sp.SetTag(TagNetMsgsSent, st.MsgsSent)
+	sp.SetTag(TagNetMsgsReceived, st.MsgsReceived)
+	if st.PeerNodeID != 0 {
+		sp.SetTag(TagNetPeerNodeID, st.PeerNodeID)
+	}
+	if st.RemoteAddr != "" {
+		sp.SetTag(TagNetRemoteAddr, st.RemoteAddr)
+	}
+	sp.SetTag(TagNetConnReused, st.ConnReused)
+}
+
+// GetRPCNetworkStats returns the statistics set on the span by
+// SetRPCNetworkStats; ok is false if there are none.
+func GetRPCNetworkStats(sp opentracing.Span) (st RPCNetworkStats, ok bool) {
+	tags := GetSpanTags(sp)
+	if _, ok := tags[TagNetBytesSent]; !ok {
+		return RPCNetworkStats{}, false
+	}
+	st.BytesSent, _ = tags[TagNetBytesSent].(int64)
+	st.BytesReceived, _ = tags[TagNetBytesReceived].(int64)
+	st.MsgsSent, _ = tags[TagNetMsgsSent].(int64)
+	st.MsgsReceived, _ = tags[TagNetMsgsReceived].(int64)
+	st.PeerNodeID, _ = tags[TagNetPeerNodeID].(int32)
+	st.RemoteAddr, _ = tags[TagNetRemoteAddr].(string)
+	st.ConnReused, _ = tags[TagNetConnReused].(bool)
+	return st, true
+}
+
+type peerNodeIDKey struct{}
+
+// ContextWithPeerNodeID returns a context which indicates that the RPCs sent
+// with it go to the given node; the ID is included in the statistics recorded
+// by the RPCNetworkStatsHandler.
+func ContextWithPeerNodeID(ctx context.Context, nodeID int32) context.Context {
+	return context.WithValue(ctx, peerNodeIDKey{}, nodeID)
+}
+
+// RPCNetworkStatsHandler is a gRPC stats.Handler for client connections which
+// sets RPCNetworkStats on the span of each RPC (e.g. the one opened by the
+// OpenTracing client interceptor). It must be installed with
+// grpc.WithStatsHandler.
+type RPCNetworkStatsHandler struct {
+	mu struct {
+		syncutil.Mutex
+		// connStart maps the remote address of each open connection to the time
+		// it was established.
+		connStart map[string]time.Time
+	}
+}
+
+var _ stats.Handler = &RPCNetworkStatsHandler{}
+
+// NewRPCNetworkStatsHandler creates a RPCNetworkStatsHandler.
+func NewRPCNetworkStatsHandler() *RPCNetworkStatsHandler {
+	h := &RPCNetworkStatsHandler{}
+	h.mu.connStart = make(map[string]time.Time)
+	return h
+}
+
+// rpcNetStats accumulates the statistics of an RPC.
+type rpcNetStats struct {
+	begin                time.Time
+	bytesSent, bytesRecv int64
+	msgsSent, msgsRecv   int64
+	remoteAddr           string
+	connReused           bool
+}
+
+type rpcNetStatsKey struct{}
+
+type connAddrKey struct{}
+
+// TagRPC is part of the stats.Handler interface.
+func (h *RPCNetworkStatsHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	if sp := opentracing.SpanFromContext(ctx); sp == nil || IsBlackHoleSpan(sp) {
+		return ctx
+	}
+	return context.WithValue(ctx, rpcNetStatsKey{}, &rpcNetStats{})
+}
+
+// HandleRPC is part of the stats.Handler interface.
+func (h *RPCNetworkStatsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	st, ok := ctx.Value(rpcNetStatsKey{}).(*rpcNetStats)
+	if !ok || !s.IsClient() {
+		return
+	}
+	// The events of an RPC are reported sequentially, but the counters are
+	// updated atomically in case the RPC is a stream used concurrently for
+	// sending and receiving.
+	switch s := s.(type) {
+	case *stats.Begin:
+		st.begin = s.BeginTime
+	case *stats.OutHeader:
+		if s.RemoteAddr != nil {
+			st.remoteAddr = s.RemoteAddr.String()
+			h.mu.Lock()
+			start, ok := h.mu.connStart[st.remoteAddr]
+			h.mu.Unlock()
+			st.connReused = ok && !start.After(st.begin)
+		}
+	case *stats.OutPayload:
+		atomic.AddInt64(&st.bytesSent, int64(s.WireLength))
+		atomic.AddInt64(&st.msgsSent, 1)
+	case *stats.InPayload:
+		atomic.AddInt64(&st.bytesRecv, int64(s.WireLength))
+		atomic.AddInt64(&st.msgsRecv, 1)
+	case *stats.End:
+		sp := opentracing.SpanFromContext(ctx)
+		nodeID, _ := ctx.Value(peerNodeIDKey{}).(int32)
+		SetRPCNetworkStats(sp, RPCNetworkStats{
+			BytesSent:     atomic.LoadInt64(&st.bytesSent),
+			BytesReceived: atomic.LoadInt64(&st.bytesRecv),
+			MsgsSent:      atomic.LoadInt64(&st.msgsSent),
+			MsgsReceived:  atomic.LoadInt64(&st.msgsRecv),
+			PeerNodeID:    nodeID,
+			RemoteAddr:    st.remoteAddr,
+			ConnReused:    st.connReused,
+		})
+	}
+}
+
+// TagConn is part of the stats.Handler interface.
+func (h *RPCNetworkStatsHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	if info.RemoteAddr == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, connAddrKey{}, info.RemoteAddr.String())
+}
+
+// HandleConn is part of the stats.Handler interface.
+func (h *RPCNetworkStatsHandler) HandleConn(ctx context.Context, s stats.ConnStats) {
+	addr, ok := ctx.Value(connAddrKey{}).(string)
+	if !ok || !s.IsClient() {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch s.(type) {
+	case *stats.ConnBegin:
+		h.mu.connStart[addr] = time.Now()
+	case *stats.ConnEnd:
+		delete(h.mu.connStart, addr)
+	}
+}
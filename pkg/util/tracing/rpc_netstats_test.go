@@ -0,0 +1,78 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/stats"
+)
+
+func TestRPCNetworkStatsHandler(t *testing.T) {
+	tr := NewTracer()
+	h := NewRPCNetworkStatsHandler()
+	addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 26257}
+
+	connCtx := h.TagConn(context.Background(), &stats.ConnTagInfo{RemoteAddr: addr})
+	h.HandleConn(connCtx, &stats.ConnBegin{Client: true})
+
+	// Simulates an RPC with the events reported by gRPC.
+	rpc := func(nodeID int32) opentracing.Span {
+		sp := tr.StartSpan("rpc", Recordable)
+		ctx := opentracing.ContextWithSpan(context.Background(), sp)
+		ctx = ContextWithPeerNodeID(ctx, nodeID)
+		ctx = h.TagRPC(ctx, &stats.RPCTagInfo{FullMethodName: "/test/Method"})
+		h.HandleRPC(ctx, &stats.Begin{Client: true, BeginTime: time.Now()})
+		h.HandleRPC(ctx, &stats.OutHeader{Client: true, RemoteAddr: addr})
+		h.HandleRPC(ctx, &stats.OutPayload{Client: true, WireLength: 100})
+		h.HandleRPC(ctx, &stats.InPayload{Client: true, WireLength: 30})
+		h.HandleRPC(ctx, &stats.InPayload{Client: true, WireLength: 20})
+		h.HandleRPC(ctx, &stats.End{Client: true, EndTime: time.Now()})
+		sp.Finish()
+		return sp
+	}
+
+	st, ok := GetRPCNetworkStats(rpc(3))
+	if !ok {
+		t.Fatal("expected network stats")
+	}
+	exp := RPCNetworkStats{
+		BytesSent:     100,
+		BytesReceived: 50,
+		MsgsSent:      1,
+		MsgsReceived:  2,
+		PeerNodeID:    3,
+		RemoteAddr:    addr.String(),
+		ConnReused:    true,
+	}
+	if st != exp {
+		t.Errorf("expected %+v, got %+v", exp, st)
+	}
+
+	// After the connection is closed, a new one is established for the next
+	// RPC.
+	h.HandleConn(connCtx, &stats.ConnEnd{Client: true})
+	if st, _ := GetRPCNetworkStats(rpc(3)); st.ConnReused {
+		t.Error("expected new connection")
+	}
+
+	if _, ok := GetRPCNetworkStats(tr.StartSpan("untouched", Recordable)); ok {
+		t.Error("unexpected network stats")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	opentracing "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+)
+
+var rpcRecordOneIn = settings.RegisterIntSetting(
+	"trace.rpc.record_one_in",
+	"if positive, one in this many RPCs is traced with a full (recorded) span; 0 = disabled",
+	0,
+)
+
+// RPCSpan is a pooled span which only measures the latency of an RPC (see
+// Tracer.RPCLatencies). It carries a real span if the RPC is traced or
+// promoted.
+type RPCSpan struct {
+	tracer    *Tracer
+	operation string
+	start     time.Time
+	span      opentracing.Span
+	promoted  bool
+	// The timing reported by the server, if any; see SetServerTiming.
+	serverTiming    ServerTiming
+	hasServerTiming bool
+}
+
+var rpcSpanPool = sync.Pool{
+	New: func() interface{} { return new(RPCSpan) },
+}
+
+// StartRPCSpan opens an RPCSpan for an RPC. If the RPC gets a real span (see
+// RPCSpan), the span is put in the returned context.
+//
+// The RPCSpan must be finished with Finish, after which it can no longer be
+// used.
+func (t *Tracer) StartRPCSpan(ctx context.Context, operation string) (context.Context, *RPCSpan) {
+	s := rpcSpanPool.Get().(*RPCSpan)
+	*s = RPCSpan{
+		tracer:    t,
+		operation: operation,
+		start:     time.Now(),
+	}
+	parent := opentracing.SpanFromContext(ctx)
+	if t.shouldPromoteRPC() {
+		atomic.AddInt64(&t.rpcPromoted, 1)
+		s.promoted = true
+		s.span = t.Start(operation, WithParent(parent), WithRecording(SingleNodeRecording))
+		ctx = opentracing.ContextWithSpan(ctx, s.span)
+	} else if parent != nil {
+		ctx, s.span = ChildSpan(ctx, operation)
+	}
+	return ctx, s
+}
+
+// shouldPromoteRPC counts the RPC and returns true for one in
+// trace.rpc.record_one_in calls.
+func (t *Tracer) shouldPromoteRPC() bool {
+	count := atomic.AddInt64(&t.rpcCount, 1)
+	n := rpcRecordOneIn.Get()
+	return n > 0 && count%n == 0
+}
+
+// RPCSpanStats are counters of the RPCs traced with StartRPCSpan.
+type RPCSpanStats struct {
+	// Spans is the number of RPCSpans started.
+	Spans int64
+	// Promoted is the number of RPCs traced with a recording because of
+	// trace.rpc.record_one_in.
+	Promoted int64
+}
+
+// RPCSpanStats returns the counters of the RPCs traced with StartRPCSpan.
+func (t *Tracer) RPCSpanStats() RPCSpanStats {
+	return RPCSpanStats{
+		Spans:    atomic.LoadInt64(&t.rpcCount),
+		Promoted: atomic.LoadInt64(&t.rpcPromoted),
+	}
+}
+
+// Span returns the real span of the RPC, if any; see RPCSpan. It can be used,
+// for example, to retrieve the recording of a promoted RPC before the RPCSpan
+// is finished.
+func (s *RPCSpan) Span() opentracing.Span {
+	return s.span
+}
+
+// Promoted returns true if the RPC is traced with a recording because of
+// trace.rpc.record_one_in.
+func (s *RPCSpan) Promoted() bool {
+	return s.promoted
+}
+
+// SetServerTiming records the timing reported by the server of the RPC (see
+// ExtractServerTiming). When the RPCSpan is finished, its real span (if any)
+// is tagged with the breakdown of the RPC's latency; see RecordRPCTiming.
+func (s *RPCSpan) SetServerTiming(st ServerTiming) {
+	s.serverTiming = st
+	s.hasServerTiming = true
+}
+
+// Finish finishes the RPCSpan (and its real span, if any), recording the
+// duration of the RPC and whether it returned an error. The trace IDs of
+// promoted RPCs are kept as exemplars; failed promoted RPCs are also retained
+// in the tracer's ErrorRecordings.
+func (s *RPCSpan) Finish(err error) {
+	var traceID uint64
+	if sp, ok := s.span.(*span); ok && s.promoted {
+		traceID = sp.TraceID
+	}
+	duration := time.Since(s.start)
+	s.tracer.rpcLatencies.record(s.operation, duration, err != nil, traceID)
+	if s.span != nil {
+		if err != nil {
+			otext.Error.Set(s.span, true)
+		}
+		if s.hasServerTiming {
+			RecordRPCTiming(s.span, duration, s.serverTiming)
+		}
+		s.span.Finish()
+	}
+	*s = RPCSpan{}
+	rpcSpanPool.Put(s)
+}
+
+// RPCLatencies returns the latency statistics of the RPCs traced with
+// StartRPCSpan, by operation.
+func (t *Tracer) RPCLatencies() map[string]OperationLatency {
+	return t.rpcLatencies.snapshot()
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+)
+
+func TestRPCSpan(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	defer settings.TestingSetInt(&rpcRecordOneIn, 3)()
+
+	promoted := 0
+	for i := 0; i < 6; i++ {
+		ctx, s := tr.StartRPCSpan(context.Background(), "rpc")
+		if s.Promoted() {
+			promoted++
+			if opentracing.SpanFromContext(ctx) != s.Span() || GetRecording(s.Span()) == nil {
+				t.Fatal("expected a recording span in the context")
+			}
+		} else if s.Span() != nil || opentracing.SpanFromContext(ctx) != nil {
+			t.Fatal("unexpected span")
+		}
+		var err error
+		if i%2 == 0 {
+			err = errors.New("boom")
+		}
+		s.Finish(err)
+	}
+	if promoted != 2 {
+		t.Fatalf("expected 2 promoted RPCs, got %d", promoted)
+	}
+
+	// RPCs that are part of a trace get a child span.
+	parent := tr.StartSpan("parent", Recordable)
+	StartRecording(parent, SingleNodeRecording)
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+	_, s := tr.StartRPCSpan(ctx, "other")
+	if s.Span() == nil || s.Span().(*span).parentSpanID != parent.(*span).SpanID {
+		t.Fatal("expected a child span")
+	}
+	s.Finish(nil)
+	parent.Finish()
+	if rec := GetRecording(parent); len(rec) != 2 || rec[1].Operation != "other" {
+		t.Fatalf("unexpected recording %v", rec)
+	}
+
+	lat := tr.RPCLatencies()
+	if n := lat["rpc"].Latency.TotalCount(); n != 6 {
+		t.Errorf("expected 6 RPCs, got %d", n)
+	}
+	if n := lat["rpc"].Errors; n != 3 {
+		t.Errorf("expected 3 errors, got %d", n)
+	}
+	if n := lat["other"].Latency.TotalCount(); n != 1 {
+		t.Errorf("expected 1 RPC, got %d", n)
+	}
+	if st := tr.RPCSpanStats(); st != (RPCSpanStats{Spans: 7, Promoted: 2}) {
+		t.Errorf("unexpected stats %+v", st)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"strconv"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+)
+
+// Tags set on the client span of an RPC by RecordRPCTiming, which break down
+// the latency observed by the client. The network duration is the part of the
+// client-observed duration that was not spent processing the request on the
+// server: network transfer, and queuing on both sides.
+const (
+	TagRPCClientDuration  = "rpc.client_duration"
+	TagRPCServerDuration  = "rpc.server_duration"
+	TagRPCNetworkDuration = "rpc.network_duration"
+)
+
+// Carrier fields holding the times (in nanoseconds since the Unix epoch, in
+// hex) at which the server received the request and sent the response.
+const (
+	fieldNameServerReceived = prefixTracerState + "server-received"
+	fieldNameServerSent     = prefixTracerState + "server-sent"
+)
+
+// ServerTiming is the timing of the processing of an RPC, as measured by the
+// server.
+type ServerTiming struct {
+	// Received is the time at which the server received the request.
+	Received time.Time
+	// Sent is the time at which the server sent the response.
+	Sent time.Time
+}
+
+// Duration returns the time the server spent processing the request. It only
+// depends on the server's clock, so it can be compared with durations
+// measured by the client regardless of the clock offset.
+func (st ServerTiming) Duration() time.Duration {
+	return st.Sent.Sub(st.Received)
+}
+
+// InjectServerTiming is used by the server of an RPC to send its timing to
+// the client, in the response's carrier (e.g. the gRPC trailer metadata).
+func InjectServerTiming(st ServerTiming, carrier opentracing.TextMapWriter) {
+	carrier.Set(fieldNameServerReceived, strconv.FormatInt(st.Received.UnixNano(), 16))
+	carrier.Set(fieldNameServerSent, strconv.FormatInt(st.Sent.UnixNano(), 16))
+}
+
+// ExtractServerTiming is used by the client of an RPC to retrieve the timing
+// sent by the server through InjectServerTiming. Returns false if the carrier
+// has no timing (e.g. because the server is running an older version).
+func ExtractServerTiming(carrier opentracing.TextMapReader) (ServerTiming, bool, error) {
+	var received, sent int64
+	var found int
+	err := carrier.ForeachKey(func(k, v string) error {
+		var dst *int64
+		switch k {
+		case fieldNameServerReceived:
+			dst = &received
+		case fieldNameServerSent:
+			dst = &sent
+		default:
+			return nil
+		}
+		nanos, err := strconv.ParseInt(v, 16, 64)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", k)
+		}
+		*dst = nanos
+		found++
+		return nil
+	})
+	if err != nil || found != 2 {
+		return ServerTiming{}, false, err
+	}
+	return ServerTiming{Received: time.Unix(0, received), Sent: time.Unix(0, sent)}, true, nil
+}
+
+// RecordRPCTiming tags the client span of an RPC with the duration of the RPC
+// observed by the client and the server's processing duration, along with the
+// difference between the two (see TagRPCNetworkDuration), so that traces show
+// the network and queuing time of each hop explicitly.
+func RecordRPCTiming(sp opentracing.Span, clientDuration time.Duration, st ServerTiming) {
+	if sp == nil || IsBlackHoleSpan(sp) {
+		return
+	}
+	server := st.Duration()
+	network := clientDuration - server
+	if network < 0 {
+		// The server can't take longer than the client observed; this can only
+		// come from imprecise measurements.
+		network = 0
+	}
+	sp.SetTag(TagRPCClientDuration, clientDuration.String())
+	sp.SetTag(TagRPCServerDuration, server.String())
+	sp.SetTag(TagRPCNetworkDuration, network.String())
+}
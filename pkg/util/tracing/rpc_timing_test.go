@@ -0,0 +1,72 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+)
+
+func TestRPCTiming(t *testing.T) {
+	// The server's clock is an hour ahead; only its durations matter.
+	received := time.Now().Add(time.Hour)
+	st := ServerTiming{Received: received, Sent: received.Add(3 * time.Millisecond)}
+
+	carrier := opentracing.TextMapCarrier{}
+	if _, ok, err := ExtractServerTiming(carrier); ok || err != nil {
+		t.Fatalf("expected no timing, got %t, %v", ok, err)
+	}
+	InjectServerTiming(st, carrier)
+	extracted, ok, err := ExtractServerTiming(carrier)
+	if !ok || err != nil {
+		t.Fatalf("expected a timing, got %t, %v", ok, err)
+	}
+	if extracted.Duration() != 3*time.Millisecond {
+		t.Errorf("unexpected server duration %s", extracted.Duration())
+	}
+
+	tr := NewTracer().(*Tracer)
+	sp := tr.StartSpan("client", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	RecordRPCTiming(sp, 5*time.Millisecond, extracted)
+	sp.Finish()
+	if err := TestingCheckRecordedSpans(GetRecording(sp), `
+		span client:
+			tags: rpc.client_duration=5ms rpc.network_duration=2ms rpc.server_duration=3ms
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	// RPCSpans record the timing when they are finished.
+	parent := tr.StartSpan("parent", Recordable)
+	StartRecording(parent, SingleNodeRecording)
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+	_, s := tr.StartRPCSpan(ctx, "rpc")
+	s.SetServerTiming(ServerTiming{Received: received, Sent: received})
+	s.Finish(nil)
+	parent.Finish()
+	rec := GetRecording(parent)
+	if len(rec) != 2 || rec[1].Tags[TagRPCServerDuration] != "0s" || rec[1].Tags[TagRPCClientDuration] == "" {
+		t.Errorf("unexpected recording %v", rec)
+	}
+
+	carrier[fieldNameServerSent] = "xyz"
+	if _, _, err := ExtractServerTiming(carrier); err == nil {
+		t.Error("expected an error")
+	}
+}
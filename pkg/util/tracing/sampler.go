@@ -0,0 +1,102 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/pkg/errors"
+)
+
+var sampleRate = settings.RegisterValidatedFloatSetting(
+	"trace.sample_rate",
+	"fraction of new traces that are sent to the shadow tracer (e.g. Lightstep)",
+	1,
+	func(v float64) error {
+		if v < 0 || v > 1 {
+			return errors.Errorf("sample rate must be between 0 and 1: %f", v)
+		}
+		return nil
+	},
+)
+
+// TraceHash maps a trace ID to a number in [0, 1), so that any component can
+// make the same sampling decision. It divides the top 53 bits of the FNV-1a
+// hash of the big-endian trace ID by 2^53.
+func TraceHash(traceID uint64) float64 {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], traceID)
+	h := fnv.New64a()
+	_, _ = h.Write(buf[:])
+	return float64(h.Sum64()>>11) / (1 << 53)
+}
+
+// TagSamplingReason is set on the root spans of the traces that are sent to
+// the shadow tracer, explaining why the trace was kept (e.g.
+// "rate=0.5 hash=0.1234"). The reason ends with "weighted" if the rate was
+// boosted by weighted sampling (see trace.sample_rate.weighted.min_per_minute).
+const TagSamplingReason = "sampling.reason"
+
+// SamplingStats counts the sampling decisions made by a Tracer for new traces
+// while a shadow tracer was configured.
+type SamplingStats struct {
+	// Kept is the number of traces sent to the shadow tracer.
+	Kept int64
+	// Dropped is the number of traces not sent to the shadow tracer because of
+	// trace.sample_rate.
+	Dropped int64
+}
+
+// SamplingStats returns the sampling decisions made so far, so that operators
+// can tell whether traces are missing from the shadow tracer because of
+// sampling.
+func (t *Tracer) SamplingStats() SamplingStats {
+	return SamplingStats{
+		Kept:    atomic.LoadInt64(&t.samplingStats.Kept),
+		Dropped: atomic.LoadInt64(&t.samplingStats.Dropped),
+	}
+}
+
+// shouldSample returns whether a new trace of the given operation (and
+// component, if any) with the given ID should be sent to the shadow tracer
+// and, if so, the reason (see TagSamplingReason). The decision is counted in
+// the tracer's SamplingStats.
+func (t *Tracer) shouldSample(
+	operationName, component string, traceID uint64,
+) (bool, string) {
+	rate, boosted := t.weightedSampleRate(
+		operationName, componentSampleRate(component, sampleRate.Get()),
+	)
+	var suffix string
+	if boosted {
+		suffix = " weighted"
+	}
+	if rate >= 1 {
+		atomic.AddInt64(&t.samplingStats.Kept, 1)
+		return true, "rate=1" + suffix
+	}
+	hash := TraceHash(traceID)
+	if hash >= rate {
+		atomic.AddInt64(&t.samplingStats.Dropped, 1)
+		return false, ""
+	}
+	atomic.AddInt64(&t.samplingStats.Kept, 1)
+	return true, "rate=" + strconv.FormatFloat(rate, 'g', -1, 64) +
+		" hash=" + strconv.FormatFloat(hash, 'f', 4, 64) + suffix
+}
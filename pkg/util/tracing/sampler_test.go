@@ -0,0 +1,148 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	lightstep "github.com/lightstep/lightstep-tracer-go"
+)
+
+func TestTraceHash(t *testing.T) {
+	// The hash is part of our external contract; make sure it doesn't change.
+	testCases := []struct {
+		traceID uint64
+		hash    float64
+	}{
+		{0, 0.6593012926533469},
+		{1, 0.6593012330487021},
+		{0x7fffffffffffffff, 0.13758313472440742},
+	}
+	for _, tc := range testCases {
+		if h := TraceHash(tc.traceID); h != tc.hash {
+			t.Errorf("%d: expected %v, got %v", tc.traceID, tc.hash, h)
+		}
+	}
+
+	// The hash should be roughly uniform.
+	var below int
+	const n = 10000
+	for i := uint64(0); i < n; i++ {
+		h := TraceHash(i * 0x9e3779b97f4a7c15)
+		if h < 0 || h >= 1 {
+			t.Fatalf("hash out of range: %v", h)
+		}
+		if h < 0.25 {
+			below++
+		}
+	}
+	if below < n/5 || below > n*3/10 {
+		t.Errorf("expected about a quarter of the hashes below 0.25, got %d/%d", below, n)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	defer settings.TestingSetFloat(&sampleRate, 0)()
+
+	tr := NewTracer()
+	tr.(*Tracer).setShadowTracer(lightStepManager{}, lightstep.NewTracer(lightstep.Options{}))
+	defer tr.(*Tracer).Close()
+
+	// With a zero sampling rate, there is no reason to create real spans.
+	if sp := tr.StartSpan("a"); !IsBlackHoleSpan(sp) {
+		t.Error("expected black hole span")
+	}
+
+	defer settings.TestingSetFloat(&sampleRate, 1)()
+	sp := tr.StartSpan("a")
+	if sp.(*span).shadowTr == nil {
+		t.Error("expected shadow span")
+	}
+	if r := GetSpanTags(sp)[TagSamplingReason]; r != "rate=1" {
+		t.Errorf("unexpected sampling reason %v", r)
+	}
+	// Child spans don't make sampling decisions.
+	if r := GetSpanTags(StartChildSpan("b", sp, false /* separateRecording */))[TagSamplingReason]; r != nil {
+		t.Errorf("unexpected sampling reason %v", r)
+	}
+
+	defer settings.TestingSetFloat(&sampleRate, 0.5)()
+	var kept int64
+	for i := 0; i < 100; i++ {
+		if sp := tr.StartSpan("a"); !IsBlackHoleSpan(sp) {
+			kept++
+			if r := GetSpanTags(sp)[TagSamplingReason].(string); !strings.HasPrefix(r, "rate=0.5 hash=0.") {
+				t.Errorf("unexpected sampling reason %v", r)
+			}
+		}
+	}
+	if stats := tr.(*Tracer).SamplingStats(); stats.Kept != kept+1 || stats.Dropped != 101-kept {
+		t.Errorf("unexpected stats %+v (kept %d)", stats, kept)
+	}
+}
+
+func TestWeightedSampling(t *testing.T) {
+	defer settings.TestingSetFloat(&sampleRate, 0)()
+	defer settings.TestingSetInt(&weightedSamplingMinPerMinute, 5)()
+
+	tr := NewTracer()
+	tr.(*Tracer).setShadowTracer(lightStepManager{}, lightstep.NewTracer(lightstep.Options{}))
+	defer tr.(*Tracer).Close()
+
+	// Rare operations are always traced.
+	for i := 0; i < 5; i++ {
+		sp := tr.StartSpan("rare")
+		if IsBlackHoleSpan(sp) {
+			t.Fatalf("%d: expected rare operation to be traced", i)
+		}
+		if r := GetSpanTags(sp)[TagSamplingReason]; r != "rate=1 weighted" {
+			t.Errorf("unexpected sampling reason %v", r)
+		}
+	}
+
+	// Frequent operations are sampled with a decreasing rate.
+	var kept int
+	for i := 0; i < 1000; i++ {
+		if sp := tr.StartSpan("frequent"); !IsBlackHoleSpan(sp) {
+			kept++
+		}
+	}
+	if kept < 5 || kept > 100 {
+		t.Errorf("expected about 30 frequent traces to be kept, got %d", kept)
+	}
+}
+
+func TestOpThroughput(t *testing.T) {
+	var o opThroughput
+	now := time.Now()
+	for i := int64(1); i <= 3; i++ {
+		if n := o.record("a", now); n != i {
+			t.Fatalf("expected %d, got %d", i, n)
+		}
+	}
+	// The previous window's count is used until the current one catches up.
+	now = now.Add(opThroughputWindow)
+	if n := o.record("a", now); n != 3 {
+		t.Errorf("expected 3, got %d", n)
+	}
+	// Stale counts are discarded.
+	now = now.Add(3 * opThroughputWindow)
+	if n := o.record("a", now); n != 1 {
+		t.Errorf("expected 1, got %d", n)
+	}
+}
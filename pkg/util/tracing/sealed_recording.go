@@ -0,0 +1,241 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// Sealed recordings are used to hand recordings over (e.g. to support) as part
+// of a debug bundle: the recording can be encrypted so that only the holder of
+// the recipient's private key can read it, and signed so that the recipient
+// can verify that it was not tampered with.
+//
+// The format of a sealed recording is:
+//
+//   version (1 byte) | flags (1 byte) |
+//   [len(wrapped key) (uvarint) | wrapped key | nonce]  (if encrypted)
+//   len(payload) (uvarint) | payload |
+//   [ECDSA signature (ASN.1)]                          (if signed)
+//
+// The payload is the encoded recording (see EncodeRecording); if the recording
+// is encrypted, the payload is sealed with AES-256-GCM using a random key which
+// is itself encrypted with the recipient's RSA public key (RSA-OAEP with
+// SHA-256). The signature covers everything that precedes it.
+const sealedRecordingVersion = 1
+
+const (
+	sealedFlagEncrypted = 1 << iota
+	sealedFlagSigned
+)
+
+// SealRecording encodes the recording and optionally encrypts it for the given
+// recipient and signs it with the given key. Either recipient or signer can be
+// nil, in which case the recording is not encrypted or not signed,
+// respectively.
+func SealRecording(
+	rec Recording, recipient *rsa.PublicKey, signer *ecdsa.PrivateKey,
+) ([]byte, error) {
+	payload, err := EncodeRecording(rec)
+	if err != nil {
+		return nil, err
+	}
+
+	var flags byte
+	if recipient != nil {
+		flags |= sealedFlagEncrypted
+	}
+	if signer != nil {
+		flags |= sealedFlagSigned
+	}
+	buf := []byte{sealedRecordingVersion, flags}
+
+	if recipient != nil {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		wrappedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, recipient, key, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "encrypting recording key")
+		}
+		aead, err := newRecordingAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		buf = appendBytes(buf, wrappedKey)
+		buf = append(buf, nonce...)
+		payload = aead.Seal(nil, nonce, payload, nil)
+	}
+	buf = appendBytes(buf, payload)
+
+	if signer != nil {
+		digest := sha256.Sum256(buf)
+		r, s, err := ecdsa.Sign(rand.Reader, signer, digest[:])
+		if err != nil {
+			return nil, errors.Wrap(err, "signing recording")
+		}
+		sig, err := asn1.Marshal(ecdsaSignature{R: r, S: s})
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, sig...)
+	}
+	return buf, nil
+}
+
+// OpenRecording is the inverse of SealRecording. The key is needed if the
+// recording is encrypted. If signer is not nil, the recording must be signed
+// by the corresponding private key.
+func OpenRecording(
+	sealed []byte, key *rsa.PrivateKey, signer *ecdsa.PublicKey,
+) (Recording, error) {
+	if len(sealed) < 2 {
+		return nil, errors.New("sealed recording too short")
+	}
+	if v := sealed[0]; v != sealedRecordingVersion {
+		return nil, errors.Errorf("unsupported sealed recording version %d", v)
+	}
+	flags := sealed[1]
+	buf := sealed[2:]
+
+	var wrappedKey, nonce []byte
+	var err error
+	if flags&sealedFlagEncrypted != 0 {
+		if wrappedKey, buf, err = readBytes(buf); err != nil {
+			return nil, err
+		}
+		// The nonce size is fixed for AES-GCM.
+		const nonceSize = 12
+		if len(buf) < nonceSize {
+			return nil, errors.New("sealed recording too short")
+		}
+		nonce, buf = buf[:nonceSize], buf[nonceSize:]
+	}
+	payload, sig, err := readBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if signer != nil {
+		if flags&sealedFlagSigned == 0 {
+			return nil, errors.New("recording is not signed")
+		}
+		var s ecdsaSignature
+		if rest, err := asn1.Unmarshal(sig, &s); err != nil || len(rest) != 0 {
+			return nil, errors.New("invalid recording signature")
+		}
+		digest := sha256.Sum256(sealed[:len(sealed)-len(sig)])
+		if !ecdsa.Verify(signer, digest[:], s.R, s.S) {
+			return nil, errors.New("recording signature verification failed")
+		}
+	} else if flags&sealedFlagSigned == 0 && len(sig) != 0 {
+		return nil, errors.New("trailing data after sealed recording")
+	}
+
+	if flags&sealedFlagEncrypted != 0 {
+		if key == nil {
+			return nil, errors.New("recording is encrypted")
+		}
+		aesKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, wrappedKey, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "decrypting recording key")
+		}
+		aead, err := newRecordingAEAD(aesKey)
+		if err != nil {
+			return nil, err
+		}
+		if payload, err = aead.Open(nil, nonce, payload, nil); err != nil {
+			return nil, errors.Wrap(err, "decrypting recording")
+		}
+	}
+	return DecodeRecording(payload)
+}
+
+// EncodeRecording serializes a recording as a sequence of length-prefixed
+// RecordedSpan protos.
+func EncodeRecording(rec Recording) ([]byte, error) {
+	var buf []byte
+	for i := range rec {
+		data, err := rec[i].Marshal()
+		if err != nil {
+			return nil, err
+		}
+		buf = appendBytes(buf, data)
+	}
+	return buf, nil
+}
+
+// DecodeRecording is the inverse of EncodeRecording.
+func DecodeRecording(buf []byte) (Recording, error) {
+	var rec Recording
+	for len(buf) > 0 {
+		var data []byte
+		var err error
+		if data, buf, err = readBytes(buf); err != nil {
+			return nil, err
+		}
+		var sp RecordedSpan
+		if err := sp.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		rec = append(rec, sp)
+	}
+	return rec, nil
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+func newRecordingAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// appendBytes appends a uvarint length prefix followed by data to buf.
+func appendBytes(buf []byte, data []byte) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], uint64(len(data)))
+	buf = append(buf, tmp[:n]...)
+	return append(buf, data...)
+}
+
+// readBytes reads a length-prefixed byte slice written by appendBytes and
+// returns it along with the rest of the buffer.
+func readBytes(buf []byte) (data []byte, rest []byte, err error) {
+	l, n := binary.Uvarint(buf)
+	if n <= 0 || uint64(len(buf)-n) < l {
+		return nil, nil, errors.New("corrupted recording data")
+	}
+	return buf[n : n+int(l)], buf[n+int(l):], nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSealRecording(t *testing.T) {
+	rec := Recording{
+		{
+			TraceID:   1,
+			SpanID:    2,
+			Operation: "root",
+			StartTime: time.Unix(10, 0).UTC(),
+			Duration:  time.Second,
+			Tags:      map[string]string{"secret": "s3cr3t"},
+		},
+		{
+			TraceID:      1,
+			SpanID:       3,
+			ParentSpanID: 2,
+			Operation:    "child",
+			StartTime:    time.Unix(11, 0).UTC(),
+			Duration:     time.Millisecond,
+		},
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherECKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name    string
+		encrypt bool
+		sign    bool
+	}{
+		{"plain", false, false},
+		{"encrypted", true, false},
+		{"signed", false, true},
+		{"encrypted and signed", true, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var recipient *rsa.PublicKey
+			var signer *ecdsa.PrivateKey
+			var verifier *ecdsa.PublicKey
+			if tc.encrypt {
+				recipient = &rsaKey.PublicKey
+			}
+			if tc.sign {
+				signer = ecKey
+				verifier = &ecKey.PublicKey
+			}
+			sealed, err := SealRecording(rec, recipient, signer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contains := bytes.Contains(sealed, []byte("s3cr3t")); contains == tc.encrypt {
+				t.Errorf("encrypted: %t, but sealed recording contains plaintext: %t", tc.encrypt, contains)
+			}
+
+			res, err := OpenRecording(sealed, rsaKey, verifier)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res, rec) {
+				t.Errorf("expected:\n%+v\ngot:\n%+v", rec, res)
+			}
+
+			if tc.encrypt {
+				if _, err := OpenRecording(sealed, nil, verifier); err == nil {
+					t.Error("expected error when opening without key")
+				}
+			}
+			if _, err := OpenRecording(sealed, rsaKey, &otherECKey.PublicKey); err == nil {
+				t.Error("expected error when verifying with the wrong key")
+			}
+
+			// Tamper with the payload.
+			tampered := append([]byte(nil), sealed...)
+			tampered[len(tampered)/2] ^= 1
+			if _, err := OpenRecording(tampered, rsaKey, verifier); err == nil && (tc.encrypt || tc.sign) {
+				t.Error("expected error when opening tampered recording")
+			}
+		})
+	}
+}
@@ -30,6 +30,8 @@ import (
 
 type shadowTracerManager interface {
 	Name() string
+	// Flush sends out any spans buffered by the tracer. It can block.
+	Flush(tr opentracing.Tracer)
 	Close(tr opentracing.Tracer)
 }
 
@@ -39,6 +41,10 @@ func (lightStepManager) Name() string {
 	return "lightstep"
 }
 
+func (lightStepManager) Flush(tr opentracing.Tracer) {
+	_ = lightstep.FlushLightStepTracer(tr)
+}
+
 func (lightStepManager) Close(tr opentracing.Tracer) {
 	// TODO(radu): these calls are not reliable. FlushLightstepTracer exits
 	// immediately if a flush is in progress (see
@@ -58,8 +64,12 @@ func (st *shadowTracer) Typ() string {
 	return st.manager.Name()
 }
 
+func (st *shadowTracer) Flush() {
+	st.manager.Flush(st.Tracer)
+}
+
 func (st *shadowTracer) Close() {
-	st.manager.Close(st)
+	st.manager.Close(st.Tracer)
 }
 
 // linkShadowSpan creates and links a Shadow span to the passed-in span (i.e.
@@ -80,8 +90,12 @@ func linkShadowSpan(
 	var opts []opentracing.StartSpanOption
 	// Replicate the options, using the lightstep context in the reference.
 	opts = append(opts, opentracing.StartTime(s.startTime))
-	if s.mu.tags != nil {
-		opts = append(opts, s.mu.tags)
+	if s.tracer.globalTags != nil {
+		// This goes before the span's own tags, which take precedence.
+		opts = append(opts, s.tracer.exportedShadowTags(shadowTr, s.tracer.globalTags))
+	}
+	if tags := s.allTagsLocked(); tags != nil {
+		opts = append(opts, s.tracer.exportedShadowTags(shadowTr, tags))
 	}
 	if parentShadowCtx != nil {
 		opts = append(opts, opentracing.SpanReference{
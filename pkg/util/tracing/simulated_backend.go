@@ -0,0 +1,175 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// SimulationOptions configure how a SimulatedBackend misbehaves.
+type SimulationOptions struct {
+	// ExportLatency is the time spent exporting each span, when it is
+	// finished.
+	ExportLatency time.Duration
+	// FlushLatency is the time spent in each flush.
+	FlushLatency time.Duration
+	// ErrorRate is the probability that the export of a span fails.
+	ErrorRate float64
+	// DropRate is the probability that a span is silently lost.
+	DropRate float64
+}
+
+// SimulatedBackendStats counts what happened to the spans sent to a
+// SimulatedBackend.
+type SimulatedBackendStats struct {
+	// Exported is the number of spans that made it to the backend.
+	Exported int64
+	// Errors is the number of spans whose export failed.
+	Errors int64
+	// Dropped is the number of spans that were silently lost.
+	Dropped int64
+	// Flushes is the number of flushes.
+	Flushes int64
+}
+
+// SimulatedBackend is a shadow tracer for tests which simulates a misbehaving
+// backend. Install it with Tracer.SetSimulatedBackend.
+type SimulatedBackend struct {
+	*TestCollector
+
+	mu struct {
+		syncutil.Mutex
+		opts SimulationOptions
+	}
+	// Accessed atomically.
+	stats SimulatedBackendStats
+}
+
+// NewSimulatedBackend creates a SimulatedBackend with the given options.
+func NewSimulatedBackend(opts SimulationOptions) *SimulatedBackend {
+	b := &SimulatedBackend{TestCollector: NewTestCollector()}
+	b.mu.opts = opts
+	return b
+}
+
+// SetOptions changes the behavior of the backend, e.g. to simulate the
+// recovery of the backend.
+func (b *SimulatedBackend) SetOptions(opts SimulationOptions) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.mu.opts = opts
+}
+
+func (b *SimulatedBackend) options() SimulationOptions {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.mu.opts
+}
+
+// Stats returns the counts of what happened to the spans sent to the
+// backend.
+func (b *SimulatedBackend) Stats() SimulatedBackendStats {
+	return SimulatedBackendStats{
+		Exported: atomic.LoadInt64(&b.stats.Exported),
+		Errors:   atomic.LoadInt64(&b.stats.Errors),
+		Dropped:  atomic.LoadInt64(&b.stats.Dropped),
+		Flushes:  atomic.LoadInt64(&b.stats.Flushes),
+	}
+}
+
+// SetSimulatedBackend installs the backend as the shadow tracer of t,
+// replacing any existing shadow tracer. Spans created afterwards are sent to
+// the backend (subject to trace.sample_rate). A nil backend removes the
+// shadow tracer.
+func (t *Tracer) SetSimulatedBackend(b *SimulatedBackend) {
+	if b == nil {
+		t.setShadowTracer(nil, nil)
+		return
+	}
+	addKnownShadowType(simulatedBackendManager{}.Name())
+	t.setShadowTracer(simulatedBackendManager{b}, simulatedTracer{b})
+}
+
+type simulatedBackendManager struct {
+	b *SimulatedBackend
+}
+
+func (simulatedBackendManager) Name() string {
+	return "simulated"
+}
+
+func (m simulatedBackendManager) Flush(tr opentracing.Tracer) {
+	time.Sleep(m.b.options().FlushLatency)
+	atomic.AddInt64(&m.b.stats.Flushes, 1)
+}
+
+func (simulatedBackendManager) Close(tr opentracing.Tracer) {}
+
+// simulatedTracer is the opentracing.Tracer of a SimulatedBackend; it wraps
+// the spans of the underlying mock tracer so that their export can be
+// disrupted.
+type simulatedTracer struct {
+	b *SimulatedBackend
+}
+
+func (st simulatedTracer) StartSpan(
+	operationName string, opts ...opentracing.StartSpanOption,
+) opentracing.Span {
+	return &simulatedSpan{Span: st.b.MockTracer.StartSpan(operationName, opts...), b: st.b}
+}
+
+func (st simulatedTracer) Inject(
+	sm opentracing.SpanContext, format interface{}, carrier interface{},
+) error {
+	return st.b.MockTracer.Inject(sm, format, carrier)
+}
+
+func (st simulatedTracer) Extract(
+	format interface{}, carrier interface{},
+) (opentracing.SpanContext, error) {
+	return st.b.MockTracer.Extract(format, carrier)
+}
+
+// simulatedSpan is a span of the mock tracer whose export (which happens when
+// it is finished) is subject to the SimulationOptions.
+type simulatedSpan struct {
+	opentracing.Span
+	b *SimulatedBackend
+}
+
+func (s *simulatedSpan) Finish() {
+	s.FinishWithOptions(opentracing.FinishOptions{})
+}
+
+func (s *simulatedSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	o := s.b.options()
+	time.Sleep(o.ExportLatency)
+	switch {
+	case o.DropRate > 0 && rand.Float64() < o.DropRate:
+		atomic.AddInt64(&s.b.stats.Dropped, 1)
+	case o.ErrorRate > 0 && rand.Float64() < o.ErrorRate:
+		atomic.AddInt64(&s.b.stats.Errors, 1)
+	default:
+		s.Span.FinishWithOptions(opts)
+		atomic.AddInt64(&s.b.stats.Exported, 1)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestSimulatedBackend(t *testing.T) {
+	defer settings.TestingSetFloat(&sampleRate, 1)()
+	tr := NewTracer().(*Tracer)
+	defer tr.Close()
+	b := NewSimulatedBackend(SimulationOptions{DropRate: 1})
+	tr.SetSimulatedBackend(b)
+
+	trace := func() {
+		sp := tr.StartSpan("root", Recordable)
+		StartRecording(sp, SingleNodeRecording)
+		StartChildSpan("child", sp, false /* separateRecording */).Finish()
+		sp.Finish()
+		// The recording is not affected by the backend.
+		if rec := GetRecording(sp); len(rec) != 2 {
+			t.Errorf("unexpected recording %v", rec)
+		}
+	}
+
+	trace()
+	if s := b.Stats(); s != (SimulatedBackendStats{Dropped: 2}) {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	b.SetOptions(SimulationOptions{ErrorRate: 1})
+	trace()
+	if s := b.Stats(); s.Errors != 2 || len(b.FinishedSpans()) != 0 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	// The backend recovers.
+	b.SetOptions(SimulationOptions{FlushLatency: time.Second})
+	trace()
+	if s := b.Stats(); s.Exported != 2 || len(b.SpansByOperation("child")) != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	// Slow flushes don't block the caller past its deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := tr.Flush(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// Tags conventionally set through TagIfSlow, so that slow spans can be
+// searched for the same way in all the backends.
+const (
+	TagSlow     = "slow"
+	TagVerySlow = "very_slow"
+)
+
+// slowTag is a tag registered through TagIfSlow.
+type slowTag struct {
+	threshold time.Duration
+	key       string
+	value     interface{}
+}
+
+// TagIfSlow sets the tags in keyValues (alternating keys and values) on the
+// span when it is finished, if its duration is at least threshold.
+func TagIfSlow(os opentracing.Span, threshold time.Duration, keyValues ...interface{}) {
+	if os == nil {
+		return
+	}
+	s, ok := os.(*span)
+	if !ok {
+		return
+	}
+	if len(keyValues)%2 != 0 {
+		panic(fmt.Sprintf("TagIfSlow: odd number of arguments: %v", keyValues))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < len(keyValues); i += 2 {
+		key, ok := keyValues[i].(string)
+		if !ok {
+			panic(fmt.Sprintf("TagIfSlow: non-string key %v", keyValues[i]))
+		}
+		s.mu.slowTags = append(s.mu.slowTags, slowTag{
+			threshold: threshold, key: key, value: keyValues[i+1],
+		})
+	}
+}
+
+// finishSlowTags sets the tags registered through TagIfSlow whose threshold is
+// exceeded by the span's duration. Called when the span finishes.
+func (s *span) finishSlowTags() {
+	s.mu.Lock()
+	duration, slowTags := s.mu.duration, s.mu.slowTags
+	s.mu.Unlock()
+	for _, t := range slowTags {
+		if duration >= t.threshold {
+			s.setTagInner(t.key, t.value, false /* locked */)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestTagIfSlow(t *testing.T) {
+	tr := NewTracer()
+	start := time.Now()
+	for _, tc := range []struct {
+		duration time.Duration
+		expSlow  bool
+		expVery  bool
+	}{
+		{duration: time.Millisecond},
+		{duration: time.Second, expSlow: true},
+		{duration: time.Minute, expSlow: true, expVery: true},
+	} {
+		sp := tr.StartSpan("op", Recordable, opentracing.StartTime(start))
+		StartRecording(sp, SingleNodeRecording)
+		TagIfSlow(sp, time.Second, TagSlow, true)
+		TagIfSlow(sp, 10*time.Second, TagVerySlow, true, "threshold", "10s")
+		sp.FinishWithOptions(opentracing.FinishOptions{FinishTime: start.Add(tc.duration)})
+
+		tags := GetRecording(sp)[0].Tags
+		if _, ok := tags[TagSlow]; ok != tc.expSlow {
+			t.Errorf("%s: expected %s=%t, got tags %v", tc.duration, TagSlow, tc.expSlow, tags)
+		}
+		if _, ok := tags[TagVerySlow]; ok != tc.expVery {
+			t.Errorf("%s: expected %s=%t, got tags %v", tc.duration, TagVerySlow, tc.expVery, tags)
+		}
+		if _, ok := tags["threshold"]; ok != tc.expVery {
+			t.Errorf("%s: unexpected tags %v", tc.duration, tags)
+		}
+	}
+
+	// Noop spans are ignored.
+	TagIfSlow(tr.StartSpan("noop"), time.Second, TagSlow, true)
+}
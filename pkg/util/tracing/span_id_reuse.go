@@ -0,0 +1,98 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// spanIDChecks enables the detection of span IDs that are reused within a
+// recording; see SetSpanIDReuseHandler. It is enabled in race builds.
+var spanIDChecks = raceEnabled
+
+// SpanIDReuse describes a span imported into a recording (see
+// ImportRemoteSpans) with the same trace and span IDs as a finished span
+// already in the recording. This points to an ID collision, or to a bug
+// causing spans (or their recordings) to be reused or imported twice.
+type SpanIDReuse struct {
+	Existing, Duplicate RecordedSpan
+}
+
+func (r SpanIDReuse) String() string {
+	return fmt.Sprintf("span ID reused in recording (trace=%x span=%x):\n  existing: %s\n  duplicate: %s",
+		r.Duplicate.TraceID, r.Duplicate.SpanID,
+		strings.TrimSuffix(Recording{r.Existing}.String(), "\n"),
+		strings.TrimSuffix(Recording{r.Duplicate}.String(), "\n"))
+}
+
+// spanIDReuseHandlerHolder allows storing a nil handler in an atomic.Value.
+type spanIDReuseHandlerHolder struct {
+	fn func(SpanIDReuse)
+}
+
+// SetSpanIDReuseHandler sets the function called for each reused span ID
+// detected in recordings (generally, to log both spans). The detection is
+// only performed in race builds. A nil handler removes the previous one; the
+// reuses are still counted (see SpanIDReuses).
+func (t *Tracer) SetSpanIDReuseHandler(fn func(SpanIDReuse)) {
+	t.spanIDReuseHandler.Store(spanIDReuseHandlerHolder{fn: fn})
+}
+
+// SpanIDReuses returns the number of reused span IDs detected so far.
+func (t *Tracer) SpanIDReuses() int64 {
+	return atomic.LoadInt64(&t.spanIDReuses)
+}
+
+type spanKey struct {
+	traceID, spanID uint64
+}
+
+// checkSpanIDReuse reports the spans about to be imported into the group
+// whose IDs are the same as those of finished spans in the group, or of
+// other spans being imported.
+func (ss *spanGroup) checkSpanIDReuse(t *Tracer, imported []RecordedSpan) {
+	ss.Lock()
+	spans := ss.spans
+	remoteSpans := ss.remoteSpans
+	ss.Unlock()
+
+	existing := make(map[spanKey]RecordedSpan, len(spans)+len(remoteSpans))
+	now := t.now()
+	for _, s := range spans {
+		s.mu.Lock()
+		finished := s.mu.duration >= 0
+		s.mu.Unlock()
+		if finished {
+			rs := s.getRecordedSpan(now, ss.structural)
+			existing[spanKey{rs.TraceID, rs.SpanID}] = rs
+		}
+	}
+	for _, rs := range remoteSpans {
+		existing[spanKey{rs.TraceID, rs.SpanID}] = rs
+	}
+	for _, rs := range imported {
+		k := spanKey{rs.TraceID, rs.SpanID}
+		if prev, ok := existing[k]; ok {
+			atomic.AddInt64(&t.spanIDReuses, 1)
+			if h, _ := t.spanIDReuseHandler.Load().(spanIDReuseHandlerHolder); h.fn != nil {
+				h.fn(SpanIDReuse{Existing: prev, Duplicate: rs})
+			}
+			continue
+		}
+		existing[k] = rs
+	}
+}
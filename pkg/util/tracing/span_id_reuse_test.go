@@ -0,0 +1,69 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSpanIDReuse(t *testing.T) {
+	defer func(prev bool) { spanIDChecks = prev }(spanIDChecks)
+	spanIDChecks = true
+
+	tr := NewTracer().(*Tracer)
+	var reports []SpanIDReuse
+	tr.SetSpanIDReuseHandler(func(r SpanIDReuse) { reports = append(reports, r) })
+
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SnowballRecording)
+	child := StartChildSpan("child", root, false /* separateRecording */)
+	child.Finish()
+	childID := child.(*span).SpanID
+	traceID := root.(*span).TraceID
+
+	remote := []RecordedSpan{
+		{TraceID: traceID, SpanID: 100, Operation: "remote"},
+		{TraceID: traceID, SpanID: 101, Operation: "remote2"},
+	}
+	if err := ImportRemoteSpans(root, remote); err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 0 {
+		t.Fatalf("unexpected reports %v", reports)
+	}
+
+	// A span colliding with a finished local span, one imported twice and one
+	// duplicated within the batch.
+	dups := []RecordedSpan{
+		{TraceID: traceID, SpanID: childID, Operation: "collision"},
+		{TraceID: traceID, SpanID: 100, Operation: "remote"},
+		{TraceID: traceID, SpanID: 200, Operation: "dup"},
+		{TraceID: traceID, SpanID: 200, Operation: "dup"},
+	}
+	if err := ImportRemoteSpans(root, dups); err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 3 || tr.SpanIDReuses() != 3 {
+		t.Fatalf("expected 3 reports, got %v", reports)
+	}
+	if r := reports[0]; r.Existing.Operation != "child" || r.Duplicate.Operation != "collision" {
+		t.Errorf("unexpected report %+v", r)
+	}
+	if s := reports[0].String(); !strings.Contains(s, "existing: child") {
+		t.Errorf("unexpected report %s", s)
+	}
+	root.Finish()
+}
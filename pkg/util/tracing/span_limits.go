@@ -0,0 +1,144 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// The span limits protect nodes from runaway traces (e.g. recursive
+// instrumentation bugs): once a limit is reached, StartSpan and StartChildSpan
+// return noop spans for the offending trace.
+var (
+	maxSpansPerTrace = settings.RegisterIntSetting(
+		"trace.span_limit.per_trace",
+		"maximum number of spans recorded for a trace on each node (0 = unlimited)",
+		10000,
+	)
+	maxSpanDepth = settings.RegisterIntSetting(
+		"trace.span_limit.depth",
+		"maximum nesting depth of the spans of a trace on each node (0 = unlimited)",
+		100,
+	)
+)
+
+// The tag limits protect recordings and exporters from call sites that dump
+// large values (e.g. entire key ranges or protobufs) into tags: tags beyond the
+// count limit are dropped and long values are truncated.
+var (
+	maxTagsPerSpan = settings.RegisterIntSetting(
+		"trace.span_limit.tags",
+		"maximum number of tags set on a span (0 = unlimited)",
+		100,
+	)
+	maxTagValueBytes = settings.RegisterIntSetting(
+		"trace.span_limit.tag_value_bytes",
+		"maximum length of the (formatted) value of a span tag; longer values are truncated (0 = unlimited)",
+		1024,
+	)
+)
+
+// TagSpansDropped is set on the first span of a recording if spans were not
+// created because of the span limits.
+const TagSpansDropped = "spans_dropped"
+
+// TagTagsDropped is set on recorded spans which had tags dropped because of the
+// tag count limit.
+const TagTagsDropped = "tags_dropped"
+
+// exceedsSpanLimits returns true if a span with the given depth (the number of
+// local ancestors) cannot be created as part of the given recording group
+// (which can be nil). If so, the dropped span is accounted for in the group.
+func exceedsSpanLimits(depth int32, group *spanGroup) bool {
+	exceeded := false
+	if max := maxSpanDepth.Get(); max > 0 && int64(depth) >= max {
+		exceeded = true
+	}
+	if group != nil && !exceeded {
+		if max := maxSpansPerTrace.Get(); max > 0 {
+			group.Lock()
+			// Only the spans created on this node count; the remote spans
+			// imported into the group are limited on their own nodes.
+			exceeded = int64(len(group.spans)) >= max
+			group.Unlock()
+		}
+	}
+	if exceeded && group != nil {
+		atomic.AddInt64(&group.dropped, 1)
+	}
+	return exceeded
+}
+
+// annotateDropped sets TagSpansDropped on the first span of a recording.
+func (ss *spanGroup) annotateDropped(rec Recording) {
+	dropped := atomic.LoadInt64(&ss.dropped)
+	if dropped == 0 || len(rec) == 0 {
+		return
+	}
+	if rec[0].Tags == nil {
+		rec[0].Tags = make(map[string]string)
+	}
+	rec[0].Tags[TagSpansDropped] = strconv.FormatInt(dropped, 10)
+}
+
+// limitTagValue truncates string and byte slice tag values longer than the tag
+// value limit, e.g. to "abc…[1500 bytes]". Other values are not formatted
+// here, so that lazily formatted values (e.g. creation stacks) stay lazy.
+func limitTagValue(value interface{}) interface{} {
+	max := int(maxTagValueBytes.Get())
+	if max <= 0 {
+		return value
+	}
+	var str string
+	switch v := value.(type) {
+	case string:
+		if len(v) <= max {
+			return value
+		}
+		str = v
+	case []byte:
+		if len(v) <= max {
+			return value
+		}
+		str = string(v)
+	default:
+		return value
+	}
+	n := max
+	for n > 0 && !utf8.RuneStart(str[n]) {
+		n--
+	}
+	return fmt.Sprintf("%s…[%d bytes]", str[:n], len(str))
+}
+
+// admitTagLocked returns true if the tag with the given key can be set on the
+// span under the tag count limit; tags that are already set can always be
+// updated. Dropped tags are counted in mu.tagsDropped.
+func (s *span) admitTagLocked(key string) bool {
+	max := maxTagsPerSpan.Get()
+	if max <= 0 || int64(len(s.mu.tags)) < max {
+		return true
+	}
+	if _, ok := s.mu.tags[key]; ok {
+		return true
+	}
+	s.mu.tagsDropped++
+	return false
+}
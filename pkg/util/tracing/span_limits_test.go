@@ -0,0 +1,133 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestSpanLimits(t *testing.T) {
+	tr := NewTracer()
+
+	t.Run("per-trace", func(t *testing.T) {
+		defer settings.TestingSetInt(&maxSpansPerTrace, 3)()
+		root := tr.StartSpan("root", Recordable)
+		StartRecording(root, SingleNodeRecording)
+		for i := 0; i < 4; i++ {
+			sp := StartChildSpan("child", root, false /* separateRecording */)
+			if exp := i >= 2; IsBlackHoleSpan(sp) != exp {
+				t.Fatalf("%d: expected noop span: %t", i, exp)
+			}
+			sp.Finish()
+		}
+		// Spans in a separate recording are not affected.
+		if sp := StartChildSpan("child", root, true /* separateRecording */); IsBlackHoleSpan(sp) {
+			t.Fatal("unexpected noop span")
+		}
+		// Spans started from the root's context are part of the recording.
+		if sp := tr.StartSpan("remote", opentracing.ChildOf(root.Context())); !IsBlackHoleSpan(sp) {
+			t.Fatal("expected noop span")
+		}
+		root.Finish()
+		rec := GetRecording(root)
+		if len(rec) != 3 {
+			t.Fatalf("expected 3 spans, got %d", len(rec))
+		}
+		if v := rec[0].Tags[TagSpansDropped]; v != "3" {
+			t.Fatalf("expected 3 dropped spans, got %q", v)
+		}
+	})
+
+	t.Run("remote spans", func(t *testing.T) {
+		defer settings.TestingSetInt(&maxSpansPerTrace, 2)()
+		root := tr.StartSpan("root", Recordable)
+		StartRecording(root, SnowballRecording)
+		rs := root.(*span)
+		remote := []RecordedSpan{
+			{TraceID: rs.TraceID, SpanID: 100, ParentSpanID: rs.SpanID, Operation: "remote1"},
+			{TraceID: rs.TraceID, SpanID: 101, ParentSpanID: rs.SpanID, Operation: "remote2"},
+		}
+		if err := ImportRemoteSpans(root, remote); err != nil {
+			t.Fatal(err)
+		}
+		// The imported spans don't count towards the limit of this node.
+		sp := StartChildSpan("child", root, false /* separateRecording */)
+		if IsBlackHoleSpan(sp) {
+			t.Fatal("unexpected noop span")
+		}
+		sp.Finish()
+		root.Finish()
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		defer settings.TestingSetInt(&maxSpanDepth, 2)()
+		root := tr.StartSpan("root", Recordable)
+		StartRecording(root, SingleNodeRecording)
+		child := StartChildSpan("child", root, false /* separateRecording */)
+		if IsBlackHoleSpan(child) {
+			t.Fatal("unexpected noop span")
+		}
+		if sp := StartChildSpan("grandchild", child, false /* separateRecording */); !IsBlackHoleSpan(sp) {
+			t.Fatal("expected noop span")
+		}
+		if sp := tr.StartSpan("grandchild", opentracing.ChildOf(child.Context())); !IsBlackHoleSpan(sp) {
+			t.Fatal("expected noop span")
+		}
+		child.Finish()
+		root.Finish()
+		if v := GetRecording(root)[0].Tags[TagSpansDropped]; v != "2" {
+			t.Fatalf("expected 2 dropped spans, got %q", v)
+		}
+	})
+}
+
+func TestTagLimits(t *testing.T) {
+	tr := NewTracer()
+	defer settings.TestingSetInt(&maxTagsPerSpan, 2)()
+	defer settings.TestingSetInt(&maxTagValueBytes, 5)()
+
+	sp := tr.StartSpan("a", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	sp.SetTag("long", "abcdefgh")
+	sp.SetTag("short", []byte("abc"))
+	sp.SetTag("dropped", 1)
+	// Existing tags can still be updated.
+	sp.SetTag("short", "x")
+
+	if err := TestingCheckRecordedSpans(GetRecording(sp), `
+		span a:
+			tags: long=abcde…[8 bytes] short=x tags_dropped=1
+	`); err != nil {
+		t.Fatal(err)
+	}
+	sp.Finish()
+
+	// Other values are not formatted.
+	pcs := make([]uintptr, maxCreationStackDepth)
+	stack := creationStack(pcs[:runtime.Callers(0, pcs)])
+	if v := limitTagValue(stack); !reflect.DeepEqual(v, stack) {
+		t.Errorf("expected the stack not to be truncated, got %v", v)
+	}
+
+	// Values are not split in the middle of a multi-byte character.
+	if v := limitTagValue("abcdé"); v != "abcd…[6 bytes]" {
+		t.Errorf("unexpected truncated value %q", v)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// SpanOption configures a span started with Tracer.Start. It is our native
+// alternative to opentracing.StartSpanOption, which requires the options to be
+// applied to an opentracing.StartSpanOptions and then inspected with type
+// switches. StartSpan translates the opentracing options into SpanOptions.
+type SpanOption interface {
+	apply(*spanOptions)
+}
+
+// spanOptions holds the options that Tracer.startSpan acts on.
+type spanOptions struct {
+	// The local or remote parent; nil for root spans.
+	parent     *spanContext
+	parentType opentracing.SpanReferenceType
+
+	tags      opentracing.Tags
+	startTime time.Time
+
+	// forceReal is set by WithForceReal and by the Recordable opentracing
+	// option.
+	forceReal bool
+	// detached is set by the WithDetachedTrace opentracing option.
+	detached bool
+	// forceTrace is set by the ForceTrace opentracing option.
+	forceTrace bool
+	// verboseOnError is set by the VerboseOnError opentracing option.
+	verboseOnError bool
+	// correlationID is set by the WithCorrelationID opentracing option.
+	correlationID string
+
+	// recording is set by WithRecording.
+	recording     bool
+	recordingType RecordingType
+
+	// parallelGroup is set by WithParallelGroup.
+	parallelGroup string
+
+	// component is set by WithComponent.
+	component string
+}
+
+// setParent sets the parent of the span, unless the context is nil or a noop
+// context (in which case the span is a root span). Returns true if the parent
+// was set.
+func (so *spanOptions) setParent(
+	ctx opentracing.SpanContext, typ opentracing.SpanReferenceType,
+) bool {
+	if ctx == nil {
+		return false
+	}
+	if _, noopCtx := ctx.(noopSpanContext); noopCtx {
+		return false
+	}
+	so.parent = ctx.(*spanContext)
+	so.parentType = typ
+	return true
+}
+
+type parentOption struct {
+	sp  opentracing.Span
+	ctx opentracing.SpanContext
+	typ opentracing.SpanReferenceType
+}
+
+func (o parentOption) apply(so *spanOptions) {
+	ctx := o.ctx
+	if o.sp != nil {
+		if _, noop := o.sp.(*noopSpan); noop {
+			return
+		}
+		ctx = o.sp.Context()
+	}
+	so.setParent(ctx, o.typ)
+}
+
+// WithParent makes the span a child of the given span (which can be a noop
+// span, in which case the new span is a root span). It is the equivalent of
+// opentracing.ChildOf(parent.Context()).
+func WithParent(parent opentracing.Span) SpanOption {
+	return parentOption{sp: parent, typ: opentracing.ChildOfRef}
+}
+
+// WithFollowsFrom makes the span follow from the given span, for operations
+// that are caused by the span but don't block it. It is the equivalent of
+// opentracing.FollowsFrom(parent.Context()).
+func WithFollowsFrom(parent opentracing.Span) SpanOption {
+	return parentOption{sp: parent, typ: opentracing.FollowsFromRef}
+}
+
+// WithRemoteParent makes the span a child of the given span context, which is
+// generally obtained through Extract.
+func WithRemoteParent(ctx opentracing.SpanContext) SpanOption {
+	return parentOption{ctx: ctx, typ: opentracing.ChildOfRef}
+}
+
+type tagsOption opentracing.Tags
+
+func (o tagsOption) apply(so *spanOptions) {
+	if so.tags == nil {
+		so.tags = make(opentracing.Tags, len(o))
+	}
+	for k, v := range o {
+		so.tags[k] = v
+	}
+}
+
+// WithTags sets tags on the new span. It can be passed multiple times.
+func WithTags(tags opentracing.Tags) SpanOption {
+	return tagsOption(tags)
+}
+
+type forceRealOption struct{}
+
+func (forceRealOption) apply(so *spanOptions) {
+	so.forceReal = true
+}
+
+// WithForceReal forces the creation of a real span, even when tracing is
+// disabled; it is the equivalent of the Recordable opentracing option.
+func WithForceReal() SpanOption {
+	return forceRealOption{}
+}
+
+type recordingOption RecordingType
+
+func (o recordingOption) apply(so *spanOptions) {
+	so.recording = true
+	so.recordingType = RecordingType(o)
+}
+
+// WithRecording starts a recording of the given type on the new span, unless
+// the span is already part of its parent's recording. It implies
+// WithForceReal.
+func WithRecording(recType RecordingType) SpanOption {
+	return recordingOption(recType)
+}
+
+type parallelGroupOption string
+
+func (o parallelGroupOption) apply(so *spanOptions) {
+	so.parallelGroup = string(o)
+}
+
+func (o parallelGroupOption) Apply(*opentracing.StartSpanOptions) {}
+
+// WithParallelGroup declares that the span runs concurrently with the siblings
+// started with the same group name.
+func WithParallelGroup(group string) SpanOption {
+	return parallelGroupOption(group)
+}
+
+// ParallelGroup is the equivalent of WithParallelGroup for StartSpan.
+func ParallelGroup(group string) opentracing.StartSpanOption {
+	return parallelGroupOption(group)
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestSpanOptions(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	defer tr.Close()
+
+	if sp := tr.Start("a"); !IsBlackHoleSpan(sp) {
+		t.Error("expected noop span")
+	}
+	if sp := tr.Start("a", WithParent(tr.Start("noop"))); !IsBlackHoleSpan(sp) {
+		t.Error("expected noop span")
+	}
+
+	root := tr.Start("root", WithForceReal(), WithTags(opentracing.Tags{"a": 1}), WithTags(opentracing.Tags{"b": 2}))
+	if _, noop := root.(*noopSpan); noop {
+		t.Fatal("expected real span")
+	}
+	if tags := GetSpanTags(root); tags["a"] != 1 || tags["b"] != 2 {
+		t.Errorf("unexpected tags %v", tags)
+	}
+
+	// The child joins the recording of its parent.
+	StartRecording(root, SingleNodeRecording)
+	child := tr.Start("child", WithParent(root))
+	follower := tr.Start("follower", WithFollowsFrom(root), WithRecording(SnowballRecording))
+	child.Finish()
+	follower.Finish()
+	root.Finish()
+	if err := TestingCheckRecordedSpans(GetRecording(root), `
+		span root:
+		span child:
+		span follower:
+	`); err != nil {
+		t.Fatal(err)
+	}
+	rootSp, followerSp := root.(*span), follower.(*span)
+	if followerSp.TraceID != rootSp.TraceID || followerSp.parentSpanID != rootSp.SpanID {
+		t.Error("follower is not part of the root's trace")
+	}
+
+	// WithRecording starts a new recording for spans that aren't recording.
+	sp := tr.Start("rec", WithRecording(SnowballRecording))
+	if !sp.(*span).isRecording() || sp.BaggageItem(Snowball) == "" {
+		t.Error("expected snowball recording")
+	}
+
+	// Remote parents.
+	carrier := opentracing.HTTPHeadersCarrier{}
+	if err := tr.Inject(sp.Context(), opentracing.HTTPHeaders, carrier); err != nil {
+		t.Fatal(err)
+	}
+	wireCtx, err := tr.Extract(opentracing.HTTPHeaders, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := tr.Start("remote", WithRemoteParent(wireCtx))
+	if !remote.(*span).isRecording() || remote.(*span).TraceID != sp.(*span).TraceID {
+		t.Error("remote span is not part of the snowball trace")
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"golang.org/x/net/context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/petermattis/goid"
+)
+
+// ownershipChecks enables the verification of span ownership; see DetachSpan.
+// It is enabled in race builds.
+var ownershipChecks = raceEnabled
+
+// Values of span.owner besides goroutine IDs.
+const (
+	// ownerUntracked is the owner of spans that were never detached; their use
+	// is not verified.
+	ownerUntracked = 0
+	// ownerDetached is the owner of detached spans.
+	ownerDetached = -1
+)
+
+// DetachSpan gives up the current goroutine's ownership of the span; the
+// goroutine that picks it up must call AttachSpan. Misuse panics in race
+// builds.
+func DetachSpan(os opentracing.Span) opentracing.Span {
+	if sp, ok := os.(*span); ok {
+		sp.Detach()
+	}
+	return os
+}
+
+// AttachSpan makes the current goroutine the owner of a span detached with
+// DetachSpan, and returns a context containing the span.
+func AttachSpan(ctx context.Context, os opentracing.Span) context.Context {
+	if sp, ok := os.(*span); ok {
+		return sp.Attach(ctx)
+	}
+	return opentracing.ContextWithSpan(ctx, os)
+}
+
+// Detach gives up the current goroutine's ownership of the span; see
+// DetachSpan.
+func (s *span) Detach() {
+	if ownershipChecks {
+		s.checkOwner()
+		atomic.StoreInt64(&s.owner, ownerDetached)
+	}
+}
+
+// Attach makes the current goroutine the owner of the span; see AttachSpan.
+func (s *span) Attach(ctx context.Context) context.Context {
+	if ownershipChecks {
+		if owner := atomic.LoadInt64(&s.owner); owner != ownerDetached && owner != ownerUntracked {
+			panic(fmt.Sprintf("span %q attached by goroutine %d while owned by goroutine %d",
+				s.operation, goid.Get(), owner))
+		}
+		atomic.StoreInt64(&s.owner, goid.Get())
+	}
+	return opentracing.ContextWithSpan(ctx, s)
+}
+
+// checkOwner panics if ownership checks are enabled and the span was detached
+// but is not owned by the current goroutine.
+func (s *span) checkOwner() {
+	if !ownershipChecks {
+		return
+	}
+	switch owner := atomic.LoadInt64(&s.owner); owner {
+	case ownerUntracked:
+	case ownerDetached:
+		panic(fmt.Sprintf("span %q used by goroutine %d while detached", s.operation, goid.Get()))
+	default:
+		if g := goid.Get(); g != owner {
+			panic(fmt.Sprintf("span %q used by goroutine %d while owned by goroutine %d",
+				s.operation, g, owner))
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestSpanOwnership(t *testing.T) {
+	defer func(prev bool) { ownershipChecks = prev }(ownershipChecks)
+	ownershipChecks = true
+
+	tr := NewTracer()
+	expectPanic := func(fn func()) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected panic")
+			}
+		}()
+		fn()
+	}
+
+	sp := tr.StartSpan("a", Recordable)
+	// Spans that were never detached can be used from any goroutine.
+	done := make(chan struct{})
+	go func() {
+		sp.LogKV("event", "untracked")
+		close(done)
+	}()
+	<-done
+
+	DetachSpan(sp)
+	expectPanic(func() { sp.LogKV("event", "detached") })
+
+	ctx := make(chan context.Context)
+	go func() {
+		c := AttachSpan(context.Background(), sp)
+		opentracing.SpanFromContext(c).LogKV("event", "attached")
+		ctx <- c
+	}()
+	c := <-ctx
+	if opentracing.SpanFromContext(c) != sp {
+		t.Fatal("expected the span in the context")
+	}
+	// The span is owned by the other goroutine.
+	expectPanic(func() { sp.SetTag("k", "v") })
+	expectPanic(func() { AttachSpan(context.Background(), sp) })
+
+	// Noop spans are ignored.
+	noop := DetachSpan(tr.StartSpan("noop"))
+	noop.Finish()
+	if opentracing.SpanFromContext(AttachSpan(context.Background(), noop)) != noop {
+		t.Fatal("expected the noop span in the context")
+	}
+}
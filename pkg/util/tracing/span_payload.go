@@ -0,0 +1,46 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+)
+
+// SpanPayload carries the trace information of a message sent through a Go
+// channel. It is meant to be embedded in the message type. The zero value
+// means that the message is not traced.
+type SpanPayload struct {
+	meta QueuedSpanMeta
+}
+
+// MakeSpanPayload captures the trace information of a message that is about
+// to be sent, if ctx has a span.
+func MakeSpanPayload(ctx context.Context) SpanPayload {
+	return SpanPayload{meta: EnqueueSpan(ctx)}
+}
+
+// Traced returns true if the message carries trace information.
+func (p SpanPayload) Traced() bool {
+	return p.meta.ctx != nil
+}
+
+// StartConsumerSpan opens a span that "follows from" the producer's span,
+// tagged with TagQueueWait. The span should be closed via FinishSpan.
+func (p SpanPayload) StartConsumerSpan(
+	ctx context.Context, opName string,
+) (context.Context, opentracing.Span) {
+	return DequeueSpan(ctx, p.meta, opName)
+}
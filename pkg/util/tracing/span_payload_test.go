@@ -0,0 +1,62 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+)
+
+func TestSpanPayload(t *testing.T) {
+	type message struct {
+		SpanPayload
+		val int
+	}
+	tr := NewTracer()
+	ch := make(chan message, 2)
+
+	// An untraced message.
+	ch <- message{SpanPayload: MakeSpanPayload(context.Background()), val: 1}
+
+	root := tr.StartSpan("producer", Recordable)
+	StartRecording(root, SnowballRecording)
+	ctx := opentracing.ContextWithSpan(context.Background(), root)
+	ch <- message{SpanPayload: MakeSpanPayload(ctx), val: 2}
+	root.Finish()
+
+	if msg := <-ch; msg.Traced() {
+		t.Fatal("expected an untraced message")
+	} else if _, sp := msg.StartConsumerSpan(context.Background(), "consume"); sp != nil {
+		t.Fatal("unexpected span")
+	}
+
+	msg := <-ch
+	if !msg.Traced() {
+		t.Fatal("expected a traced message")
+	}
+	_, sp := msg.StartConsumerSpan(context.Background(), "consume")
+	FinishSpan(sp)
+
+	rec := GetRecording(sp)
+	if len(rec) != 2 || rec[1].Operation != "consume" ||
+		rec[1].StartOptions.ParentReference != ParentReferenceFollowsFrom {
+		t.Fatalf("unexpected recording: %+v", rec)
+	}
+	if _, ok := rec[1].Tags[TagQueueWait]; !ok {
+		t.Errorf("expected the queue wait to be tagged, got %v", rec[1].Tags)
+	}
+}
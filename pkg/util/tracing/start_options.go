@@ -0,0 +1,65 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// Values of RecordedSpan_StartOptions.ParentReference.
+const (
+	ParentReferenceChildOf     = "child_of"
+	ParentReferenceFollowsFrom = "follows_from"
+)
+
+// spanStart remembers the options a span was started with, so that they can
+// be included in recordings (see RecordedSpan.StartOptions). The protobuf is
+// only built when the span is recorded.
+type spanStart struct {
+	hasParent    bool
+	parentType   opentracing.SpanReferenceType
+	remoteParent bool
+	// The tags passed to StartSpan; the map is owned by the span and not
+	// modified after the span is started.
+	tags           opentracing.Tags
+	samplingReason string
+}
+
+// startOptions returns the protobuf representation of the span's start
+// options.
+func (s *span) startOptions() *RecordedSpan_StartOptions {
+	opts := &RecordedSpan_StartOptions{
+		RemoteParent:   s.start.remoteParent,
+		SamplingReason: s.start.samplingReason,
+	}
+	if s.start.hasParent {
+		opts.ParentReference = ParentReferenceChildOf
+		if s.start.parentType == opentracing.FollowsFromRef {
+			opts.ParentReference = ParentReferenceFollowsFrom
+		}
+	}
+	if len(s.start.tags) > 0 {
+		opts.Tags = make(map[string]string, len(s.start.tags))
+		for k, v := range s.start.tags {
+			opts.Tags[k] = fmt.Sprint(v)
+		}
+	}
+	if s.shadowTr != nil {
+		opts.ShadowType = s.shadowTr.Typ()
+	}
+	return opts
+}
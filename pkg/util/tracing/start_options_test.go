@@ -0,0 +1,76 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestStartOptions(t *testing.T) {
+	defer settings.TestingSetFloat(&sampleRate, 1)()
+	tr := NewTracer().(*Tracer)
+	tr.setShadowTracer(&flushTestManager{}, opentracing.NoopTracer{})
+	defer tr.Close()
+
+	root := tr.StartSpan("root", Recordable, opentracing.Tag{Key: "k", Value: 1})
+	StartRecording(root, SnowballRecording)
+	child := tr.StartSpan("child", opentracing.FollowsFrom(root.Context()))
+	child.SetTag("k", 2)
+	local := StartChildSpan("local", child, false /* separateRecording */)
+
+	carrier := make(opentracing.TextMapCarrier)
+	if err := tr.Inject(local.Context(), opentracing.TextMap, carrier); err != nil {
+		t.Fatal(err)
+	}
+	// The remote node doesn't have a shadow tracer.
+	tr2 := NewTracer()
+	wireContext, err := tr2.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := tr2.StartSpan("remote", opentracing.ChildOf(wireContext))
+
+	for _, sp := range []opentracing.Span{remote, local, child} {
+		sp.Finish()
+	}
+	if err := ImportRemoteSpans(root, GetRecording(remote)); err != nil {
+		t.Fatal(err)
+	}
+	root.Finish()
+
+	expected := map[string]RecordedSpan_StartOptions{
+		"root": {
+			Tags:           map[string]string{"k": "1"},
+			SamplingReason: "rate=1",
+			ShadowType:     "test",
+		},
+		"child":  {ParentReference: ParentReferenceFollowsFrom, ShadowType: "test"},
+		"local":  {ParentReference: ParentReferenceChildOf, ShadowType: "test"},
+		"remote": {ParentReference: ParentReferenceChildOf, RemoteParent: true},
+	}
+	rec := GetRecording(root)
+	if len(rec) != len(expected) {
+		t.Fatalf("expected %d spans, got %d", len(expected), len(rec))
+	}
+	for _, sp := range rec {
+		if exp := expected[sp.Operation]; !reflect.DeepEqual(sp.StartOptions, &exp) {
+			t.Errorf("%s: expected start options %+v, got %+v", sp.Operation, exp, sp.StartOptions)
+		}
+	}
+}
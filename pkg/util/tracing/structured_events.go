@@ -0,0 +1,185 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	opentracing "github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+)
+
+// FieldStructured is the key of the log record field holding a structured
+// event (see LogStructured): a base64-encoded, marshaled types.Any whose type
+// URL identifies the registered event type.
+const FieldStructured = "structured"
+
+// EventType identifies a type of structured events.
+type EventType struct {
+	// Name is the name under which the subsystem registered the type, e.g.
+	// "kv.lease_acquired".
+	Name string
+	// Version is bumped when the type changes incompatibly.
+	Version int
+}
+
+// typeURL is the type URL of the events' types.Any payloads.
+func (t EventType) typeURL() string {
+	return fmt.Sprintf("%s/v%d", t.Name, t.Version)
+}
+
+func (t EventType) String() string {
+	return t.typeURL()
+}
+
+// eventTypes is the registry of structured event types.
+//
+// It should never be mutated after init (except in tests), as it is read
+// concurrently by different callers.
+var eventTypes = struct {
+	byURL  map[string]reflect.Type
+	byType map[reflect.Type]EventType
+}{
+	byURL:  map[string]reflect.Type{},
+	byType: map[reflect.Type]EventType{},
+}
+
+// RegisterEventType declares the name and version of the structured events with
+// the type of msg, which must be a pointer. It should be called from init().
+func RegisterEventType(name string, version int, msg proto.Message) {
+	if strings.Contains(name, "/") {
+		panic(fmt.Sprintf("invalid event type name: %s", name))
+	}
+	t := EventType{Name: name, Version: version}
+	typ := reflect.TypeOf(msg)
+	if typ.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("event type %s registered with a non-pointer %T", t, msg))
+	}
+	if _, ok := eventTypes.byURL[t.typeURL()]; ok {
+		panic(fmt.Sprintf("event type already registered: %s", t))
+	}
+	if prev, ok := eventTypes.byType[typ]; ok {
+		panic(fmt.Sprintf("%T already registered as event type %s", msg, prev))
+	}
+	eventTypes.byURL[t.typeURL()] = typ
+	eventTypes.byType[typ] = t
+}
+
+// EventTypes returns the registered structured event types, sorted by name
+// and version.
+func EventTypes() []EventType {
+	res := make([]EventType, 0, len(eventTypes.byType))
+	for _, t := range eventTypes.byType {
+		res = append(res, t)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].Version < res[j].Version
+	})
+	return res
+}
+
+// LogStructured logs a structured event in the span, along with a textual
+// rendering of it. The type of ev should be registered with RegisterEventType.
+func LogStructured(os opentracing.Span, ev proto.Message) {
+	if s, ok := os.(*span); ok {
+		if s.shadowTr == nil && s.events == nil && !s.isVerbose() {
+			return
+		}
+	} else if _, noop := os.(*noopSpan); noop {
+		return
+	}
+	var url string
+	if t, ok := eventTypes.byType[reflect.TypeOf(ev)]; ok {
+		url = t.typeURL()
+	} else {
+		// The payload can't be decoded, but it's better than losing the event.
+		url = proto.MessageName(ev)
+	}
+	value, err := proto.Marshal(ev)
+	if err != nil {
+		os.LogFields(otlog.String("event", fmt.Sprintf("%s: %s", url, err)))
+		return
+	}
+	data, err := proto.Marshal(&types.Any{TypeUrl: url, Value: value})
+	if err != nil {
+		os.LogFields(otlog.String("event", fmt.Sprintf("%s: %s", url, err)))
+		return
+	}
+	os.LogFields(
+		otlog.String("event", fmt.Sprintf("%s: %s", url, proto.CompactTextString(ev))),
+		otlog.String(FieldStructured, base64.StdEncoding.EncodeToString(data)),
+	)
+}
+
+// StructuredEvent decodes the structured event of the record, if any (see
+// LogStructured). Returns a nil message if the record is not a structured
+// event, and an error if the event is malformed or its type is not registered
+// on this node (e.g. it was logged by a newer version).
+func (l *RecordedSpan_LogRecord) StructuredEvent() (EventType, proto.Message, error) {
+	for _, f := range l.Fields {
+		if f.Key == FieldStructured {
+			return decodeStructuredEvent(f.Value)
+		}
+	}
+	return EventType{}, nil, nil
+}
+
+func decodeStructuredEvent(encoded string) (EventType, proto.Message, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return EventType{}, nil, errors.Wrap(err, "malformed structured event")
+	}
+	var any types.Any
+	if err := proto.Unmarshal(data, &any); err != nil {
+		return EventType{}, nil, errors.Wrap(err, "malformed structured event")
+	}
+	typ, ok := eventTypes.byURL[any.TypeUrl]
+	if !ok {
+		return EventType{}, nil, errors.Errorf("unknown structured event type %q", any.TypeUrl)
+	}
+	msg := reflect.New(typ.Elem()).Interface().(proto.Message)
+	if err := proto.Unmarshal(any.Value, msg); err != nil {
+		return EventType{}, nil, errors.Wrapf(err, "malformed %s event", any.TypeUrl)
+	}
+	return eventTypes.byType[typ], msg, nil
+}
+
+// TestingValidateStructuredEvents checks that all the structured events in a
+// recording are of registered types and can be decoded. Returns an error
+// listing the invalid events.
+func TestingValidateStructuredEvents(rec Recording) error {
+	var errs []string
+	for _, sp := range rec {
+		for i := range sp.Logs {
+			if _, _, err := sp.Logs[i].StructuredEvent(); err != nil {
+				errs = append(errs, fmt.Sprintf("span %s, event %d: %s", sp.Operation, i, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("invalid structured events:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+var testEventType = EventType{Name: "tracing.test_start_options", Version: 2}
+
+func init() {
+	RegisterEventType(testEventType.Name, testEventType.Version, &RecordedSpan_StartOptions{})
+}
+
+func TestStructuredEvents(t *testing.T) {
+	found := false
+	for _, et := range EventTypes() {
+		if et == testEventType {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("%s not in %v", testEventType, EventTypes())
+	}
+
+	tr := NewTracer()
+	sp := tr.StartSpan("s", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	ev := &RecordedSpan_StartOptions{ParentReference: ParentReferenceChildOf, RemoteParent: true}
+	LogStructured(sp, ev)
+	sp.LogKV("event", "plain")
+	sp.Finish()
+
+	rec := GetRecording(sp)
+	if err := TestingValidateStructuredEvents(rec); err != nil {
+		t.Fatal(err)
+	}
+	et, msg, err := rec[0].Logs[0].StructuredEvent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if et != testEventType || !proto.Equal(msg, ev) {
+		t.Errorf("expected %s %v, got %s %v", testEventType, ev, et, msg)
+	}
+	if _, msg, err := rec[0].Logs[1].StructuredEvent(); msg != nil || err != nil {
+		t.Errorf("unexpected structured event %v (%v)", msg, err)
+	}
+
+	// Events of unregistered types can't be decoded.
+	sp = tr.StartSpan("s", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	LogStructured(sp, &RecordedSpan_LogRecord_Field{Key: "k", Value: "v"})
+	sp.Finish()
+	if err := TestingValidateStructuredEvents(GetRecording(sp)); err == nil ||
+		!strings.Contains(err.Error(), "unknown structured event type") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"strings"
+	"sync/atomic"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+var shadowTagMappingSetting = settings.RegisterValidatedStringSetting(
+	"trace.shadow.tag_mapping",
+	"comma-separated rules renaming the span tags exported to the shadow tracer "+
+		"(e.g. to follow the conventions of the backend), in the form "+
+		"<tag>=<exported tag>, optionally prefixed by <shadow tracer type>: to "+
+		"apply only to that tracer (e.g. 'error=otel.status_code,lightstep:node=host.name')",
+	"",
+	func(v string) error {
+		_, err := parseShadowTagMapping(v)
+		return err
+	},
+)
+
+// shadowTagMapping is the parsed trace.shadow.tag_mapping setting.
+type shadowTagMapping struct {
+	// all holds the rules that apply to any shadow tracer.
+	all map[string]string
+	// byType holds the rules specific to a shadow tracer type; they take
+	// precedence over the others.
+	byType map[string]map[string]string
+}
+
+func parseShadowTagMapping(v string) (shadowTagMapping, error) {
+	var m shadowTagMapping
+	for _, rule := range strings.Split(v, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		i := strings.Index(rule, "=")
+		if i < 0 {
+			return shadowTagMapping{}, errors.Errorf("invalid tag mapping %q: missing '='", rule)
+		}
+		from, to := strings.TrimSpace(rule[:i]), strings.TrimSpace(rule[i+1:])
+		var typ string
+		if j := strings.Index(from, ":"); j >= 0 {
+			typ, from = strings.TrimSpace(from[:j]), strings.TrimSpace(from[j+1:])
+			if typ == "" {
+				return shadowTagMapping{}, errors.Errorf("invalid tag mapping %q", rule)
+			}
+		}
+		if from == "" || to == "" {
+			return shadowTagMapping{}, errors.Errorf("invalid tag mapping %q", rule)
+		}
+		if typ == "" {
+			if m.all == nil {
+				m.all = make(map[string]string)
+			}
+			m.all[from] = to
+			continue
+		}
+		if m.byType == nil {
+			m.byType = make(map[string]map[string]string)
+		}
+		if m.byType[typ] == nil {
+			m.byType[typ] = make(map[string]string)
+		}
+		m.byType[typ][from] = to
+	}
+	return m, nil
+}
+
+// parsedShadowTagMapping caches the parsed value of the setting.
+type parsedShadowTagMapping struct {
+	raw     string
+	mapping shadowTagMapping
+}
+
+var shadowTagMappingCache atomic.Value
+
+// exportedTagKey returns the key under which a tag is exported to a shadow
+// tracer of the given type.
+func exportedTagKey(typ, key string) string {
+	raw := shadowTagMappingSetting.Get()
+	if raw == "" {
+		return key
+	}
+	p, ok := shadowTagMappingCache.Load().(parsedShadowTagMapping)
+	if !ok || p.raw != raw {
+		m, _ := parseShadowTagMapping(raw)
+		p = parsedShadowTagMapping{raw: raw, mapping: m}
+		shadowTagMappingCache.Store(p)
+	}
+	if to, ok := p.mapping.byType[typ][key]; ok {
+		return to
+	}
+	if to, ok := p.mapping.all[key]; ok {
+		return to
+	}
+	return key
+}
+
+// exportedShadowTags returns the tags to export to the shadow tracer, with
+// the values transformed by the tracer's TagTransforms (which match our tag
+// keys) and the keys renamed according to the trace.shadow.tag_mapping
+// setting.
+func (t *Tracer) exportedShadowTags(st *shadowTracer, tags opentracing.Tags) opentracing.Tags {
+	tags = t.shadowTagTransforms.applyAll(tags)
+	if shadowTagMappingSetting.Get() == "" || len(tags) == 0 {
+		return tags
+	}
+	res := make(opentracing.Tags, len(tags))
+	for k, v := range tags {
+		res[exportedTagKey(st.Typ(), k)] = v
+	}
+	return res
+}
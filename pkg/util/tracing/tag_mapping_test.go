@@ -0,0 +1,77 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestParseShadowTagMapping(t *testing.T) {
+	for _, v := range []string{"", "error=otel.status_code", " a=b , test:node = host.name"} {
+		if _, err := parseShadowTagMapping(v); err != nil {
+			t.Errorf("%q: unexpected error %v", v, err)
+		}
+	}
+	for _, v := range []string{"a", "=b", "a=", ":a=b", "test:=b"} {
+		if _, err := parseShadowTagMapping(v); err == nil {
+			t.Errorf("%q: expected error", v)
+		}
+	}
+}
+
+func TestShadowTagMapping(t *testing.T) {
+	defer settings.TestingSetString(
+		&shadowTagMappingSetting, "error=otel.status_code,node=x,test:node=host.name,other:user=u",
+	)()
+	tr := NewTracerWithOptions(TracerOptions{
+		GlobalTags: opentracing.Tags{"node": 1},
+	}).(*Tracer)
+	defer tr.Close()
+	mockTr := mocktracer.New()
+	// The type of the shadow tracer is "test".
+	tr.setShadowTracer(&flushTestManager{}, mockTr)
+
+	sp := tr.StartSpan("a", Recordable, opentracing.Tags{"user": "root"})
+	StartRecording(sp, SingleNodeRecording)
+	otext.Error.Set(sp, true)
+	sp.Finish()
+
+	mockSp := mockTr.FinishedSpans()[0]
+	for k, v := range map[string]interface{}{
+		"otel.status_code": true,
+		"host.name":        1,
+		"user":             "root",
+	} {
+		if actual := mockSp.Tag(k); actual != v {
+			t.Errorf("%s: expected %v, got %v", k, v, actual)
+		}
+	}
+	for _, k := range []string{"error", "node", "x", "u"} {
+		if v := mockSp.Tag(k); v != nil {
+			t.Errorf("unexpected tag %s=%v", k, v)
+		}
+	}
+
+	// The recording has the original keys.
+	if v := GetRecording(sp)[0].Tags["error"]; v != "true" {
+		t.Errorf("unexpected recorded tags %v", GetRecording(sp)[0].Tags)
+	}
+}
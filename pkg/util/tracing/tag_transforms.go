@@ -0,0 +1,162 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sync/atomic"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// TagTransform rewrites the value of a tag before it is exported to the shadow
+// tracer (e.g. Lightstep). Recordings and the event sink are not affected,
+// since their data doesn't leave the cluster.
+type TagTransform func(value interface{}) interface{}
+
+// HashTagValue returns a TagTransform which replaces values with a keyed hash
+// (HMAC-SHA256, truncated to 64 bits). The same value always hashes to the same
+// string, so spans can still be correlated by it in the external tracer.
+func HashTagValue(key []byte) TagTransform {
+	return func(value interface{}) interface{} {
+		mac := hmac.New(sha256.New, key)
+		fmt.Fprint(mac, value)
+		return hex.EncodeToString(mac.Sum(nil)[:8])
+	}
+}
+
+// TruncateTagValue returns a TagTransform which truncates values to at most n
+// bytes.
+func TruncateTagValue(n int) TagTransform {
+	return func(value interface{}) interface{} {
+		s := fmt.Sprint(value)
+		if len(s) > n {
+			s = s[:n]
+		}
+		return s
+	}
+}
+
+// EncryptTagValue returns a TagTransform which encrypts values with AES-GCM
+// using the given key (16, 24 or 32 bytes long). The values can be recovered
+// with DecryptTagValue by the holders of the key.
+func EncryptTagValue(key []byte) (TagTransform, error) {
+	aead, err := newRecordingAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return func(value interface{}) interface{} {
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "<encryption failed>"
+		}
+		sealed := aead.Seal(nonce, nonce, []byte(fmt.Sprint(value)), nil)
+		return base64.RawURLEncoding.EncodeToString(sealed)
+	}, nil
+}
+
+// DecryptTagValue decrypts a value produced by EncryptTagValue.
+func DecryptTagValue(key []byte, value string) (string, error) {
+	aead, err := newRecordingAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("encrypted tag value too short")
+	}
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "decrypting tag value")
+	}
+	return string(plain), nil
+}
+
+type tagTransform struct {
+	pattern   string
+	transform TagTransform
+}
+
+type tagTransforms struct {
+	// num is the number of transforms, accessed atomically to avoid locking in
+	// the common case where there are none.
+	num int32
+	mu  struct {
+		syncutil.Mutex
+		transforms []tagTransform
+	}
+}
+
+// AddShadowTagTransform registers a transformation for the values of the tags
+// whose keys match the given pattern (see path.Match for the syntax; e.g.
+// "sql.*") when they are exported to the shadow tracer. If several patterns
+// match a key, the first one registered is used.
+func (t *Tracer) AddShadowTagTransform(pattern string, fn TagTransform) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return errors.Wrapf(err, "invalid tag pattern %q", pattern)
+	}
+	tt := &t.shadowTagTransforms
+	tt.mu.Lock()
+	tt.mu.transforms = append(tt.mu.transforms, tagTransform{pattern: pattern, transform: fn})
+	atomic.StoreInt32(&tt.num, int32(len(tt.mu.transforms)))
+	tt.mu.Unlock()
+	return nil
+}
+
+// empty returns true if there are no transforms.
+func (tt *tagTransforms) empty() bool {
+	return atomic.LoadInt32(&tt.num) == 0
+}
+
+// apply returns the value to export for the given tag.
+func (tt *tagTransforms) apply(key string, value interface{}) interface{} {
+	if tt.empty() {
+		return value
+	}
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	for _, t := range tt.mu.transforms {
+		if ok, _ := path.Match(t.pattern, key); ok {
+			return t.transform(value)
+		}
+	}
+	return value
+}
+
+// applyAll returns the tags to export; the argument is returned as is if there
+// are no transforms.
+func (tt *tagTransforms) applyAll(tags opentracing.Tags) opentracing.Tags {
+	if tt.empty() || len(tags) == 0 {
+		return tags
+	}
+	res := make(opentracing.Tags, len(tags))
+	for k, v := range tags {
+		res[k] = tt.apply(k, v)
+	}
+	return res
+}
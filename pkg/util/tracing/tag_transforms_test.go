@@ -0,0 +1,78 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestShadowTagTransforms(t *testing.T) {
+	tr := NewTracerWithOptions(TracerOptions{
+		GlobalTags: opentracing.Tags{"node.address": "10.0.0.1:26257"},
+	}).(*Tracer)
+	defer tr.Close()
+	mockTr := mocktracer.New()
+	tr.setShadowTracer(&flushTestManager{}, mockTr)
+
+	if err := tr.AddShadowTagTransform("[", TruncateTagValue(1)); err == nil {
+		t.Fatal("expected error for invalid pattern")
+	}
+	key := []byte("0123456789abcdef")
+	encrypt, err := EncryptTagValue(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		pattern string
+		fn      TagTransform
+	}{
+		{"node.*", HashTagValue(key)},
+		{"sql.stmt", TruncateTagValue(6)},
+		{"user", encrypt},
+	} {
+		if err := tr.AddShadowTagTransform(tt.pattern, tt.fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sp := tr.StartSpan("a", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	sp.SetTag("user", "root")
+	sp.SetTag("sql.stmt", "SELECT 1")
+	sp.SetTag("other", "x")
+	sp.Finish()
+
+	mockSp := mockTr.FinishedSpans()[0]
+	if v := mockSp.Tag("node.address"); v != HashTagValue(key)("10.0.0.1:26257") {
+		t.Errorf("unexpected hashed value %v", v)
+	}
+	if v := mockSp.Tag("sql.stmt"); v != "SELECT" {
+		t.Errorf("unexpected truncated value %v", v)
+	}
+	if v := mockSp.Tag("other"); v != "x" {
+		t.Errorf("unexpected value %v", v)
+	}
+	if v, err := DecryptTagValue(key, mockSp.Tag("user").(string)); err != nil || v != "root" {
+		t.Errorf("unexpected decrypted value %q (err: %v)", v, err)
+	}
+
+	// The recording has the original values.
+	if v := GetRecording(sp)[0].Tags["sql.stmt"]; v != "SELECT 1" {
+		t.Errorf("unexpected recorded value %v", v)
+	}
+}
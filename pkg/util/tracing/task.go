@@ -0,0 +1,53 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	opentracing "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+	"golang.org/x/net/context"
+)
+
+// RunAsyncTask runs fn in a new goroutine, in a span that "follows from" the
+// span in ctx (or in a new root span of tracer). The span is finished when fn
+// returns or panics.
+func RunAsyncTask(
+	ctx context.Context, tracer opentracing.Tracer, opName string, fn func(context.Context),
+) {
+	ctx, sp := ForkCtxSpan(ctx, opName)
+	if sp == nil && tracer != nil {
+		sp = tracer.StartSpan(opName)
+		ctx = opentracing.ContextWithSpan(ctx, sp)
+	}
+	go func() {
+		defer FinishTaskSpan(sp)
+		fn(ctx)
+	}()
+}
+
+// FinishTaskSpan finishes the span of a task (if any), recording a panic if
+// the task is panicking. It must be deferred directly by the task.
+func FinishTaskSpan(sp opentracing.Span) {
+	if sp == nil {
+		return
+	}
+	if r := recover(); r != nil {
+		otext.Error.Set(sp, true)
+		sp.LogKV("event", "panic", "value", r)
+		sp.Finish()
+		panic(r)
+	}
+	sp.Finish()
+}
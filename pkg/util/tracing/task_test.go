@@ -0,0 +1,84 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+)
+
+func TestRunAsyncTask(t *testing.T) {
+	tr := NewTracer()
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SingleNodeRecording)
+	ctx := opentracing.ContextWithSpan(context.Background(), root)
+
+	done := make(chan opentracing.Span)
+	RunAsyncTask(ctx, nil /* tracer */, "task", func(ctx context.Context) {
+		sp := opentracing.SpanFromContext(ctx)
+		sp.LogKV("x", 1)
+		done <- sp
+	})
+	sp := <-done
+
+	if err := TestingCheckRecordedSpans(GetRecording(root), `
+		span root:
+		span task:
+			x: 1
+	`); err != nil {
+		t.Fatal(err)
+	}
+	if sp.(*span).parentSpanID != root.(*span).SpanID {
+		t.Errorf("expected task span to follow from root span")
+	}
+
+	// Without a span in the context, the tracer is used for a root span.
+	tr.(*Tracer).SetForceRealSpans(true)
+	RunAsyncTask(context.Background(), tr, "task2", func(ctx context.Context) {
+		done <- opentracing.SpanFromContext(ctx)
+	})
+	if sp := <-done; sp == nil || sp.(*span).parentSpanID != 0 {
+		t.Errorf("expected root span, got %+v", sp)
+	}
+}
+
+func TestFinishTaskSpanPanic(t *testing.T) {
+	tr := NewTracer()
+	sp := tr.StartSpan("task", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("expected panic to be propagated, got %v", r)
+			}
+		}()
+		defer FinishTaskSpan(sp)
+		panic("boom")
+	}()
+
+	if err := TestingCheckRecordedSpans(GetRecording(sp), `
+		span task:
+			tags: error=true
+			event: panic  value: boom
+	`); err != nil {
+		t.Fatal(err)
+	}
+	if sp.(*span).mu.duration < 0 {
+		t.Error("span was not finished")
+	}
+}
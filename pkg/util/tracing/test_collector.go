@@ -0,0 +1,108 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+// TestCollector is a shadow tracer for tests which keeps the finished spans in
+// memory. Install it with Tracer.SetTestCollector.
+type TestCollector struct {
+	*mocktracer.MockTracer
+}
+
+// NewTestCollector creates an empty TestCollector.
+func NewTestCollector() *TestCollector {
+	return &TestCollector{MockTracer: mocktracer.New()}
+}
+
+type testCollectorManager struct{}
+
+func (testCollectorManager) Name() string {
+	return "test-collector"
+}
+
+func (testCollectorManager) Flush(tr opentracing.Tracer) {}
+
+func (testCollectorManager) Close(tr opentracing.Tracer) {}
+
+// SetTestCollector installs the collector as the shadow tracer of t, replacing
+// any existing shadow tracer. Spans created afterwards are exported to the
+// collector (subject to trace.sample_rate). A nil collector removes the shadow
+// tracer.
+func (t *Tracer) SetTestCollector(c *TestCollector) {
+	if c == nil {
+		t.setShadowTracer(nil, nil)
+		return
+	}
+	addKnownShadowType(testCollectorManager{}.Name())
+	t.setShadowTracer(testCollectorManager{}, c.MockTracer)
+}
+
+func (c *TestCollector) filter(fn func(*mocktracer.MockSpan) bool) []*mocktracer.MockSpan {
+	var res []*mocktracer.MockSpan
+	for _, sp := range c.FinishedSpans() {
+		if fn(sp) {
+			res = append(res, sp)
+		}
+	}
+	return res
+}
+
+// SpansByOperation returns the finished spans with the given operation name,
+// in the order in which they were finished.
+func (c *TestCollector) SpansByOperation(operation string) []*mocktracer.MockSpan {
+	return c.filter(func(sp *mocktracer.MockSpan) bool {
+		return sp.OperationName == operation
+	})
+}
+
+// SpansWithTag returns the finished spans that have the given tag. If value is
+// nil, any value matches.
+func (c *TestCollector) SpansWithTag(key string, value interface{}) []*mocktracer.MockSpan {
+	return c.filter(func(sp *mocktracer.MockSpan) bool {
+		v := sp.Tag(key)
+		if value == nil {
+			return v != nil
+		}
+		return v == value
+	})
+}
+
+// SpansByTraceID returns the finished spans that belong to the given shadow
+// trace. See TraceID.
+func (c *TestCollector) SpansByTraceID(traceID int) []*mocktracer.MockSpan {
+	return c.filter(func(sp *mocktracer.MockSpan) bool {
+		return sp.SpanContext.TraceID == traceID
+	})
+}
+
+// TraceID returns the ID under which the trace of the given span is exported
+// to the collector. The collector allocates its own IDs, which are unrelated
+// to our trace IDs. Returns false if the span is not exported to the
+// collector.
+func (c *TestCollector) TraceID(os opentracing.Span) (int, bool) {
+	sp, ok := os.(*span)
+	if !ok || sp.shadowTr == nil || sp.shadowTr.Tracer != c.MockTracer {
+		return 0, false
+	}
+	ctx, ok := sp.shadowSpan.Context().(mocktracer.MockSpanContext)
+	if !ok {
+		return 0, false
+	}
+	return ctx.TraceID, true
+}
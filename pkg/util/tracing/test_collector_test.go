@@ -0,0 +1,83 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestTestCollector(t *testing.T) {
+	c := NewTestCollector()
+	tr1 := NewTracer().(*Tracer)
+	defer tr1.Close()
+	tr2 := NewTracer().(*Tracer)
+	defer tr2.Close()
+	tr1.SetTestCollector(c)
+	tr2.SetTestCollector(c)
+
+	root := tr1.StartSpan("root")
+	root.SetTag("node", 1)
+	traceID, ok := c.TraceID(root)
+	if !ok {
+		t.Fatal("expected root span to be exported")
+	}
+	child := tr1.StartSpan("child", opentracing.ChildOf(root.Context()))
+	child.Finish()
+
+	// Propagate the trace to the other tracer.
+	carrier := opentracing.HTTPHeadersCarrier{}
+	if err := tr1.Inject(root.Context(), opentracing.HTTPHeaders, carrier); err != nil {
+		t.Fatal(err)
+	}
+	wireCtx, err := tr2.Extract(opentracing.HTTPHeaders, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := tr2.StartSpan("remote", opentracing.FollowsFrom(wireCtx))
+	remote.SetTag("node", 2)
+	remote.Finish()
+	root.Finish()
+
+	other := tr1.StartSpan("other")
+	other.Finish()
+
+	if n := len(c.FinishedSpans()); n != 4 {
+		t.Fatalf("expected 4 spans, got %d", n)
+	}
+	if sps := c.SpansByOperation("remote"); len(sps) != 1 || sps[0].Tag("node") != 2 {
+		t.Errorf("unexpected remote spans %v", sps)
+	}
+	if sps := c.SpansWithTag("node", nil); len(sps) != 2 {
+		t.Errorf("expected 2 spans with node tag, got %v", sps)
+	}
+	if sps := c.SpansWithTag("node", 1); len(sps) != 1 || sps[0].OperationName != "root" {
+		t.Errorf("unexpected spans with node=1: %v", sps)
+	}
+	if sps := c.SpansByTraceID(traceID); len(sps) != 3 {
+		t.Errorf("expected 3 spans in trace, got %v", sps)
+	}
+
+	// Spans that aren't exported to the collector have no trace ID.
+	tr1.SetTestCollector(nil)
+	if _, ok := c.TraceID(tr1.StartSpan("a", Recordable)); ok {
+		t.Error("expected no trace ID")
+	}
+	c.Reset()
+	if n := len(c.FinishedSpans()); n != 0 {
+		t.Errorf("expected no spans after reset, got %d", n)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sync/atomic"
+	"unsafe"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+)
+
+// TraceCost is the tracing overhead accumulated by a trace on the local node.
+// See SetTraceCostLimit.
+type TraceCost struct {
+	// Spans is the number of spans created.
+	Spans int64
+	// RecordedBytes estimates the size of the messages captured in recordings.
+	RecordedBytes int64
+	// ExportedBytes estimates the size of the messages and tags sent to the
+	// shadow tracer.
+	ExportedBytes int64
+}
+
+// Exceeds returns true if any of the costs reaches the corresponding nonzero
+// field of limit.
+func (c TraceCost) Exceeds(limit TraceCost) bool {
+	return (limit.Spans > 0 && c.Spans >= limit.Spans) ||
+		(limit.RecordedBytes > 0 && c.RecordedBytes >= limit.RecordedBytes) ||
+		(limit.ExportedBytes > 0 && c.ExportedBytes >= limit.ExportedBytes)
+}
+
+// traceCost is shared by all the local spans of a trace (and by their span
+// contexts). The methods can be called on a nil receiver. Being the per-node
+// state of the trace, it also holds the trace's TraceLocals.
+type traceCost struct {
+	// All the fields are accessed atomically.
+	cost  TraceCost
+	limit TraceCost
+	// exceeded is set once the cost reaches the limit; see cutOff.
+	exceeded int32
+
+	locals TraceLocals
+
+	// verbose points to the recording group of the spans of a trace upgraded
+	// to verbose recording; see VerboseOnError. Accessed atomically.
+	verbose unsafe.Pointer
+}
+
+func (c *traceCost) get() TraceCost {
+	if c == nil {
+		return TraceCost{}
+	}
+	return TraceCost{
+		Spans:         atomic.LoadInt64(&c.cost.Spans),
+		RecordedBytes: atomic.LoadInt64(&c.cost.RecordedBytes),
+		ExportedBytes: atomic.LoadInt64(&c.cost.ExportedBytes),
+	}
+}
+
+func (c *traceCost) getLimit() TraceCost {
+	return TraceCost{
+		Spans:         atomic.LoadInt64(&c.limit.Spans),
+		RecordedBytes: atomic.LoadInt64(&c.limit.RecordedBytes),
+		ExportedBytes: atomic.LoadInt64(&c.limit.ExportedBytes),
+	}
+}
+
+// cutOff returns true if the trace exceeded its limit, in which case no more
+// spans are created and no more messages are recorded or exported for it.
+func (c *traceCost) cutOff() bool {
+	return c != nil && atomic.LoadInt32(&c.exceeded) != 0
+}
+
+func (c *traceCost) add(field *int64, n int64) {
+	atomic.AddInt64(field, n)
+	if c.get().Exceeds(c.getLimit()) {
+		atomic.StoreInt32(&c.exceeded, 1)
+	}
+}
+
+func (c *traceCost) addSpan() {
+	if c != nil {
+		c.add(&c.cost.Spans, 1)
+	}
+}
+
+func (c *traceCost) addRecorded(n int64) {
+	if c != nil {
+		c.add(&c.cost.RecordedBytes, n)
+	}
+}
+
+func (c *traceCost) addExported(n int64) {
+	if c != nil {
+		c.add(&c.cost.ExportedBytes, n)
+	}
+}
+
+// valueSize estimates the size of a tag or log field value without formatting
+// it.
+func valueSize(v interface{}) int64 {
+	switch v := v.(type) {
+	case string:
+		return int64(len(v))
+	case lazyMessage:
+		return int64(len(v.format) + 8*len(v.args))
+	default:
+		return 8
+	}
+}
+
+func fieldsSize(fields []otlog.Field) int64 {
+	var size int64
+	for _, f := range fields {
+		size += int64(len(f.Key())) + valueSize(f.Value())
+	}
+	return size
+}
+
+// GetTraceCost returns the cost accumulated so far by the span's trace on the
+// local node.
+func GetTraceCost(os opentracing.Span) TraceCost {
+	sp, ok := os.(*span)
+	if !ok {
+		return TraceCost{}
+	}
+	return sp.cost.get()
+}
+
+// SetTraceCostLimit sets a limit on the cost of the span's trace on the local
+// node (zero fields are unlimited). Once the limit is reached, tracing is cut
+// off for the trace: new spans are noop spans and messages are no longer
+// recorded or exported. Spans that already exist are still finished normally.
+func SetTraceCostLimit(os opentracing.Span, limit TraceCost) {
+	sp, ok := os.(*span)
+	if !ok || sp.cost == nil {
+		return
+	}
+	c := sp.cost
+	atomic.StoreInt64(&c.limit.Spans, limit.Spans)
+	atomic.StoreInt64(&c.limit.RecordedBytes, limit.RecordedBytes)
+	atomic.StoreInt64(&c.limit.ExportedBytes, limit.ExportedBytes)
+	if c.get().Exceeds(limit) {
+		atomic.StoreInt32(&c.exceeded, 1)
+	}
+}
+
+// TraceCostExceeded returns true if tracing was cut off for the span's trace
+// because it reached its cost limit.
+func TraceCostExceeded(os opentracing.Span) bool {
+	sp, ok := os.(*span)
+	return ok && sp.cost.cutOff()
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestTraceCost(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	defer tr.Close()
+	mockTr := mocktracer.New()
+	tr.setShadowTracer(&flushTestManager{}, mockTr)
+
+	root := tr.StartSpan("root")
+	StartRecording(root, SingleNodeRecording)
+	child := tr.StartSpan("child", opentracing.ChildOf(root.Context()))
+	Recordf(child, "hello")
+	child.SetTag("k", "v")
+	grandchild := StartChildSpan("grandchild", child, false /* separateRecording */)
+
+	c := GetTraceCost(grandchild)
+	if c.Spans != 3 {
+		t.Errorf("expected 3 spans, got %d", c.Spans)
+	}
+	// The message ("event" + "hello") is both recorded and exported; the tags
+	// ("k" + "v" and the sampling reason) are only exported.
+	exported := int64(10 + 2 + len(TagSamplingReason) + len("rate=1"))
+	if c.RecordedBytes != 10 || c.ExportedBytes != exported {
+		t.Errorf("unexpected cost %+v", c)
+	}
+	if c != GetTraceCost(root) {
+		t.Errorf("expected the same cost for all spans, got %+v and %+v", c, GetTraceCost(root))
+	}
+
+	// A separate trace has its own cost.
+	other := tr.StartSpan("other")
+	if c := GetTraceCost(other); c.Spans != 1 || c.RecordedBytes != 0 {
+		t.Errorf("unexpected cost %+v", c)
+	}
+
+	SetTraceCostLimit(root, TraceCost{Spans: 4})
+	if TraceCostExceeded(root) {
+		t.Fatal("limit exceeded too early")
+	}
+	last := StartChildSpan("last", root, false /* separateRecording */)
+	if IsBlackHoleSpan(last) || !TraceCostExceeded(root) {
+		t.Fatal("expected the fourth span to be created and to reach the limit")
+	}
+	// Tracing is now cut off for the trace.
+	if sp := StartChildSpan("a", root, false /* separateRecording */); !IsBlackHoleSpan(sp) {
+		t.Error("expected noop span")
+	}
+	if sp := tr.StartSpan("b", opentracing.ChildOf(child.Context())); !IsBlackHoleSpan(sp) {
+		t.Error("expected noop span")
+	}
+	Recordf(child, "dropped")
+	child.LogKV("event", "dropped")
+	if c := GetTraceCost(root); c.Spans != 4 || c.RecordedBytes != 10 || c.ExportedBytes != exported {
+		t.Errorf("unexpected cost after cutoff %+v", c)
+	}
+	if TraceCostExceeded(other) {
+		t.Error("other trace affected by the limit")
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"strconv"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+)
+
+// TraceID128 is a 128-bit trace ID, as used by external tracing systems. Our
+// 64-bit IDs have a zero High part.
+type TraceID128 struct {
+	High, Low uint64
+}
+
+// MakeTraceID128 returns the 128-bit version of one of our trace IDs.
+func MakeTraceID128(traceID uint64) TraceID128 {
+	return TraceID128{Low: traceID}
+}
+
+// Is64Bit returns true if the ID can be represented as one of our (64-bit)
+// trace IDs.
+func (id TraceID128) Is64Bit() bool {
+	return id.High == 0
+}
+
+// String formats the ID as 32 lowercase hex digits, as in W3C Trace Context
+// (traceparent) headers.
+func (id TraceID128) String() string {
+	return fmt.Sprintf("%016x%016x", id.High, id.Low)
+}
+
+// JaegerString formats the ID as in Jaeger (uber-trace-id headers and the
+// Jaeger UI): lowercase hex digits without leading zeros.
+func (id TraceID128) JaegerString() string {
+	if id.High == 0 {
+		return strconv.FormatUint(id.Low, 16)
+	}
+	return fmt.Sprintf("%x%016x", id.High, id.Low)
+}
+
+// ParseTraceID128 parses a trace ID formatted as 1 to 32 hex digits; it
+// accepts both the W3C and the Jaeger formats.
+func ParseTraceID128(s string) (TraceID128, error) {
+	if len(s) == 0 || len(s) > 32 {
+		return TraceID128{}, errors.Errorf("invalid trace ID %q", s)
+	}
+	var id TraceID128
+	var err error
+	if len(s) > 16 {
+		if id.High, err = strconv.ParseUint(s[:len(s)-16], 16, 64); err != nil {
+			return TraceID128{}, errors.Errorf("invalid trace ID %q", s)
+		}
+		s = s[len(s)-16:]
+	}
+	if id.Low, err = strconv.ParseUint(s, 16, 64); err != nil {
+		return TraceID128{}, errors.Errorf("invalid trace ID %q", s)
+	}
+	return id, nil
+}
+
+// TraceID128String formats one of our trace IDs in the W3C format (32 hex
+// digits), which is accepted by most external trace UIs.
+func TraceID128String(traceID uint64) string {
+	return MakeTraceID128(traceID).String()
+}
+
+// FormatSpanID formats a span ID as 16 lowercase hex digits, as in W3C Trace
+// Context headers.
+func FormatSpanID(spanID uint64) string {
+	return fmt.Sprintf("%016x", spanID)
+}
+
+// ParseTraceID parses a trace ID in any of the formats accepted by
+// ParseTraceID128. 128-bit IDs are truncated to their low 64 bits, as 64-bit
+// Zipkin and Jaeger clients do; Extract applies the same policy to the legacy
+// and the traceparent carrier fields.
+func ParseTraceID(s string) (uint64, error) {
+	id, err := ParseTraceID128(s)
+	if err != nil {
+		return 0, err
+	}
+	return id.Low, nil
+}
+
+// String formats the IDs as "trace=<id> span=<id>", with the IDs in hex as in
+// the carriers produced by Inject (and in the Jaeger format). The result is
+// meant for log messages and errors, so that a trace found in the logs can be
+// looked up directly in a trace store or an external UI.
+func (m spanMeta) String() string {
+	return "trace=" + strconv.FormatUint(m.TraceID, 16) + " span=" + strconv.FormatUint(m.SpanID, 16)
+}
+
+// formatSpanMeta formats the IDs like spanMeta.String, adding "sb=1" for
+// snowball traces.
+func formatSpanMeta(m spanMeta, baggage map[string]string) string {
+	if baggage[Snowball] != "" {
+		return m.String() + " sb=1"
+	}
+	return m.String()
+}
+
+// String formats the span context as "trace=<id> span=<id> [sb=1]"; see
+// spanMeta.String.
+func (sc *spanContext) String() string {
+	return formatSpanMeta(sc.spanMeta, sc.Baggage)
+}
+
+// String formats the span's IDs as "trace=<id> span=<id> [sb=1]"; see
+// spanMeta.String.
+func (s *span) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return formatSpanMeta(s.spanMeta, s.mu.Baggage)
+}
+
+// SpanContextString formats the given span context as
+// "trace=<id> span=<id> [sb=1]". It returns an empty string for noop contexts
+// and contexts from other tracers.
+func SpanContextString(osc opentracing.SpanContext) string {
+	if sc, ok := osc.(*spanContext); ok {
+		return sc.String()
+	}
+	return ""
+}
+
+// SpanString formats the IDs of the given span like SpanContextString, without
+// the cost of creating the span's context. It returns an empty string for noop
+// spans and spans from other tracers.
+func SpanString(os opentracing.Span) string {
+	if sp, ok := os.(*span); ok {
+		return sp.String()
+	}
+	return ""
+}
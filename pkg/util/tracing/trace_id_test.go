@@ -0,0 +1,114 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestTraceID128(t *testing.T) {
+	testCases := []struct {
+		id     TraceID128
+		w3c    string
+		jaeger string
+	}{
+		{TraceID128{Low: 0xabc}, "00000000000000000000000000000abc", "abc"},
+		{TraceID128{Low: 0x7fffffffffffffff}, "00000000000000007fffffffffffffff", "7fffffffffffffff"},
+		{TraceID128{High: 0x1, Low: 0x2}, "00000000000000010000000000000002", "10000000000000002"},
+		{
+			TraceID128{High: 0x4bf92f3577b34da6, Low: 0xa3ce929d0e0e4736},
+			"4bf92f3577b34da6a3ce929d0e0e4736", "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+	}
+	for _, tc := range testCases {
+		if s := tc.id.String(); s != tc.w3c {
+			t.Errorf("%v: expected W3C format %s, got %s", tc.id, tc.w3c, s)
+		}
+		if s := tc.id.JaegerString(); s != tc.jaeger {
+			t.Errorf("%v: expected Jaeger format %s, got %s", tc.id, tc.jaeger, s)
+		}
+		for _, s := range []string{tc.w3c, tc.jaeger} {
+			id, err := ParseTraceID128(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id != tc.id {
+				t.Errorf("%s: expected %v, got %v", s, tc.id, id)
+			}
+		}
+	}
+
+	for _, s := range []string{"", "xyz", "000000000000000000000000000000000", "-1"} {
+		if _, err := ParseTraceID128(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+
+	if s := TraceID128String(0xabc); s != "00000000000000000000000000000abc" {
+		t.Errorf("unexpected trace ID string %s", s)
+	}
+	if s := FormatSpanID(0x123); s != "0000000000000123" {
+		t.Errorf("unexpected span ID string %s", s)
+	}
+	if id, err := ParseTraceID("00000000000000000000000000000abc"); err != nil || id != 0xabc {
+		t.Errorf("expected abc, got %x (%v)", id, err)
+	}
+	if id, err := ParseTraceID("4bf92f3577b34da6a3ce929d0e0e4736"); err != nil || id != 0xa3ce929d0e0e4736 {
+		t.Errorf("expected the low 64 bits of a 128-bit trace ID, got %x (%v)", id, err)
+	}
+
+	// Extracting a 128-bit trace ID keeps its low 64 bits.
+	carrier := opentracing.TextMapCarrier{
+		fieldNameTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		fieldNameSpanID:  "1",
+	}
+	wireContext, err := NewTracer().Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc := wireContext.(*spanContext); sc.TraceID != 0xa3ce929d0e0e4736 || sc.SpanID != 1 {
+		t.Errorf("unexpected IDs %s", sc)
+	}
+}
+
+func TestSpanMetaString(t *testing.T) {
+	if s := (spanMeta{TraceID: 0xabcd1234, SpanID: 0xef567890}).String(); s != "trace=abcd1234 span=ef567890" {
+		t.Errorf("unexpected string %q", s)
+	}
+
+	tr := NewTracer()
+	sp := tr.StartSpan("a", Recordable)
+	defer sp.Finish()
+	s := sp.(*span)
+	exp := fmt.Sprintf("trace=%x span=%x", s.TraceID, s.SpanID)
+	if str := SpanString(sp); str != exp {
+		t.Errorf("expected %q, got %q", exp, str)
+	}
+	StartRecording(sp, SnowballRecording)
+	exp += " sb=1"
+	if str := SpanString(sp); str != exp {
+		t.Errorf("expected %q, got %q", exp, str)
+	}
+	if str := SpanContextString(sp.Context()); str != exp {
+		t.Errorf("expected %q, got %q", exp, str)
+	}
+
+	if str := SpanString(tr.StartSpan("noop")); str != "" {
+		t.Errorf("expected empty string for noop span, got %q", str)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	opentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// TraceLocals is a scratchpad shared by the spans of a trace on the local node.
+// Keys should be of unexported types. The methods can be called on a nil
+// receiver.
+type TraceLocals struct {
+	mu syncutil.Mutex
+	m  map[interface{}]interface{}
+}
+
+// TraceLocal returns the scratchpad of the trace of the given span; nil for
+// noop spans.
+func TraceLocal(os opentracing.Span) *TraceLocals {
+	sp, ok := os.(*span)
+	if !ok || sp.cost == nil {
+		return nil
+	}
+	return &sp.cost.locals
+}
+
+// Set stores a value; a nil value removes the key.
+func (l *TraceLocals) Set(key, value interface{}) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if value == nil {
+		delete(l.m, key)
+		return
+	}
+	if l.m == nil {
+		l.m = make(map[interface{}]interface{})
+	}
+	l.m[key] = value
+}
+
+// Get returns the value stored for the key, or nil.
+func (l *TraceLocals) Get(key interface{}) interface{} {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.m[key]
+}
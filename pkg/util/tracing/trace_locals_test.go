@@ -0,0 +1,65 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+type testLocalKey struct{}
+
+func TestTraceLocals(t *testing.T) {
+	tr := NewTracer()
+	tr2 := NewTracer()
+
+	root := tr.StartSpan("root", Recordable)
+	defer root.Finish()
+	TraceLocal(root).Set(testLocalKey{}, "hint")
+
+	child := StartChildSpan("child", root, false /* separateRecording */)
+	defer child.Finish()
+	if v := TraceLocal(child).Get(testLocalKey{}); v != "hint" {
+		t.Errorf("expected hint in child span, got %v", v)
+	}
+
+	// The scratchpad doesn't follow the trace to other nodes.
+	carrier := make(opentracing.TextMapCarrier)
+	if err := tr.Inject(root.Context(), opentracing.TextMap, carrier); err != nil {
+		t.Fatal(err)
+	}
+	wireContext, err := tr2.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := tr2.StartSpan("remote", opentracing.ChildOf(wireContext), Recordable)
+	defer remote.Finish()
+	if v := TraceLocal(remote).Get(testLocalKey{}); v != nil {
+		t.Errorf("unexpected value in remote span: %v", v)
+	}
+
+	TraceLocal(child).Set(testLocalKey{}, nil)
+	if v := TraceLocal(root).Get(testLocalKey{}); v != nil {
+		t.Errorf("expected value to be removed, got %v", v)
+	}
+
+	// Noop spans have no scratchpad.
+	noop := tr.StartSpan("noop")
+	TraceLocal(noop).Set(testLocalKey{}, "x")
+	if v := TraceLocal(noop).Get(testLocalKey{}); v != nil {
+		t.Errorf("unexpected value for noop span: %v", v)
+	}
+}
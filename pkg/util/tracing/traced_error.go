@@ -0,0 +1,85 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"golang.org/x/net/context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// TracedError is an error decorated with the IDs of the span in which it was
+// returned; see WithTraceID. Its message includes the IDs, so that clients
+// that only see the message can report the trace to operators.
+type TracedError struct {
+	cause error
+	meta  spanMeta
+}
+
+var _ error = &TracedError{}
+
+func (e *TracedError) Error() string {
+	return e.cause.Error() + " (" + e.meta.String() + ")"
+}
+
+// Cause returns the decorated error; it is used by errors.Cause.
+func (e *TracedError) Cause() error {
+	return e.cause
+}
+
+// TraceID returns the ID of the trace in which the error occurred.
+func (e *TracedError) TraceID() uint64 {
+	return e.meta.TraceID
+}
+
+// SpanID returns the ID of the span in which the error occurred.
+func (e *TracedError) SpanID() uint64 {
+	return e.meta.SpanID
+}
+
+// WithTraceID decorates err with the trace and span IDs of the span in ctx, if
+// it has IDs.
+func WithTraceID(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	sp, ok := opentracing.SpanFromContext(ctx).(*span)
+	if !ok {
+		return err
+	}
+	if _, _, ok := GetErrorTraceID(err); ok {
+		return err
+	}
+	return &TracedError{cause: err, meta: sp.spanMeta}
+}
+
+// GetErrorTraceID returns the trace and span IDs with which the error (or one
+// of its causes) was decorated by WithTraceID.
+func GetErrorTraceID(err error) (traceID, spanID uint64, ok bool) {
+	type causer interface {
+		Cause() error
+	}
+	for err != nil {
+		if te, isTraced := err.(*TracedError); isTraced {
+			return te.meta.TraceID, te.meta.SpanID, true
+		}
+		c, isCauser := err.(causer)
+		if !isCauser {
+			break
+		}
+		err = c.Cause()
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+)
+
+func TestWithTraceID(t *testing.T) {
+	tr := NewTracer()
+	orig := errors.New("boom")
+
+	// No span, or a noop span: the error is not decorated.
+	if err := WithTraceID(context.Background(), orig); err != orig {
+		t.Errorf("expected unchanged error, got %v", err)
+	}
+	noopCtx := opentracing.ContextWithSpan(context.Background(), tr.StartSpan("noop"))
+	if err := WithTraceID(noopCtx, orig); err != orig {
+		t.Errorf("expected unchanged error, got %v", err)
+	}
+	if err := WithTraceID(noopCtx, nil); err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+
+	sp := tr.StartSpan("a", Recordable)
+	defer sp.Finish()
+	s := sp.(*span)
+	ctx := opentracing.ContextWithSpan(context.Background(), sp)
+	err := WithTraceID(ctx, orig)
+	if exp := fmt.Sprintf("boom (trace=%x span=%x)", s.TraceID, s.SpanID); err.Error() != exp {
+		t.Errorf("expected %q, got %q", exp, err)
+	}
+	if errors.Cause(err) != orig {
+		t.Errorf("unexpected cause %v", errors.Cause(err))
+	}
+
+	// The IDs can be retrieved through wrappers, and the error isn't decorated
+	// twice.
+	wrapped := errors.Wrap(err, "wrapped")
+	traceID, spanID, ok := GetErrorTraceID(wrapped)
+	if !ok || traceID != s.TraceID || spanID != s.SpanID {
+		t.Errorf("unexpected IDs %x %x %t", traceID, spanID, ok)
+	}
+	if err2 := WithTraceID(ctx, wrapped); err2 != wrapped {
+		t.Errorf("expected unchanged error, got %v", err2)
+	}
+	if _, _, ok := GetErrorTraceID(orig); ok {
+		t.Error("expected no IDs for undecorated error")
+	}
+}
@@ -30,12 +30,13 @@ import (
 	"unsafe"
 
 	"golang.org/x/net/context"
-	"golang.org/x/net/trace"
 
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/caller"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/petermattis/goid"
 	"github.com/pkg/errors"
 )
 
@@ -66,9 +67,15 @@ var enableNetTrace = settings.RegisterBoolSetting(
 	false,
 )
 
+var propagateTraceIDs = settings.RegisterBoolSetting(
+	"trace.propagate_ids.enabled",
+	"if set, trace and span IDs are propagated for operations that are not otherwise traced, so that they can be correlated with external traces",
+	false,
+)
+
 // Tracer is our own custom implementation of opentracing.Tracer. It supports:
 //
-//  - forwarding events to x/net/trace instances
+//  - forwarding events to an EventSink (x/net/trace by default)
 //
 //  - recording traces. Recording is started automatically for spans that have
 //    the Snowball baggage and can be started explicitly as well. Recorded
@@ -77,8 +84,8 @@ var enableNetTrace = settings.RegisterBoolSetting(
 //  - lightstep traces. This is implemented by maintaining a "shadow" lightstep
 //    span inside each of our spans.
 //
-// Even when tracing is disabled, we still use this Tracer (with x/net/trace and
-// lightstep disabled) because of its recording capability (snowball
+// Even when tracing is disabled, we still use this Tracer (with the event sink
+// and lightstep disabled) because of its recording capability (snowball
 // tracing needs to work in all cases).
 //
 // Tracer is currently stateless so we could have a single instance; however,
@@ -86,7 +93,7 @@ var enableNetTrace = settings.RegisterBoolSetting(
 // state.
 type Tracer struct {
 	// Preallocated noopSpan, used to avoid creating spans when we are not using
-	// x/net/trace or lightstep and we are not recording.
+	// the event sink or lightstep and we are not recording.
 	noopSpan noopSpan
 
 	// If forceRealSpans is set, this Tracer will always create real spans (never
@@ -97,27 +104,191 @@ type Tracer struct {
 
 	// Pointer to shadowTracer, if using one.
 	shadowTracer unsafe.Pointer
+
+	// Destination of the events of real spans; see TracerOptions. Immutable
+	// after construction.
+	eventSink EventSink
+
+	// globalTags are applied to every real span; see TracerOptions.
+	globalTags opentracing.Tags
+
+	// Receivers of the recorded spans; see TracerOptions. Immutable after
+	// construction.
+	exporters []SpanExporter
+	// Number of errors returned by the exporters; see SpanExportErrors.
+	// Accessed atomically.
+	exportErrors int64
+
+	// Recordings of recent failed traces; see ErrorRecordings.
+	errorRecordings errorRecordings
+	// Recordings routed to the RecordingSinkRecent sink; see RecentTraces.
+	recentTraces recentTraces
+	// Sinks for the trace.recording.routes setting; see RegisterRecordingSink.
+	recordingSinks recordingSinks
+
+	// If set, spans are tagged with their creation stack; see TracerOptions.
+	creationStacks bool
+
+	// Rules for starting recording automatically; see AddRecordingTrigger.
+	recordingTriggers recordingTriggers
+
+	// Holds a baggageAuthorizerHolder; see SetBaggageAuthorizer.
+	baggageAuthorizer atomic.Value
+
+	// Transformations of tags exported to the shadow tracer; see
+	// AddShadowTagTransform.
+	shadowTagTransforms tagTransforms
+
+	// ID of the node, included in recordings; see SetNodeID. Accessed
+	// atomically.
+	nodeID int32
+
+	// Counts of sampling decisions; accessed atomically.
+	samplingStats SamplingStats
+	// Throughput of the operations, for weighted sampling.
+	opThroughput opThroughput
+
+	// Number of RPCs started with StartRPCSpan and of the ones promoted (see
+	// trace.rpc.record_one_in); accessed atomically.
+	rpcCount    int64
+	rpcPromoted int64
+	// Latency statistics of the RPCs; see RPCLatencies.
+	rpcLatencies latencyStore
+
+	// Operations for which span durations are tracked; see TracerOptions.
+	// Immutable after construction.
+	latencyOps map[string]struct{}
+	// Latency statistics of the spans of latencyOps; see LatencySnapshot.
+	spanLatencies latencyStore
+
+	// Background work; see StartMaintenance.
+	maintenance maintenance
+
+	// Time spent in the tracer's code paths; see Overhead.
+	overhead overheadCounters
+
+	// Registry of open spans; see SetAbandonedSpanHandler.
+	activeSpans activeSpans
+
+	// Observers of the spans' lifecycle; see RegisterSpanObserver.
+	spanObservers spanObservers
+
+	// Holds a spanIDReuseHandlerHolder; see SetSpanIDReuseHandler.
+	spanIDReuseHandler atomic.Value
+	// Number of reused span IDs detected; accessed atomically.
+	spanIDReuses int64
+
+	// If set, span timings come from this clock; see NewVirtualClockTracer.
+	// Immutable after construction.
+	clock *timeutil.ManualTime
 }
 
 var _ opentracing.Tracer = &Tracer{}
 
+// TracerOptions contains optional configuration for a Tracer.
+type TracerOptions struct {
+	// GlobalTags are set on every real span created by the Tracer, unless the
+	// span sets the same tag.
+	GlobalTags opentracing.Tags
+
+	// CreationStacks enables an audit mode in which every real span is tagged
+	// with the (truncated) call stack at the point where it was created; see
+	// TagCreationStack. This is expensive and is meant for tracking down which
+	// code path creates unexpected spans.
+	CreationStacks bool
+
+	// LatencyOperations lists the operations for which the Tracer maintains
+	// histograms of span durations; see LatencySnapshot. Spans for these
+	// operations are always real spans, even when tracing is disabled (except
+	// for the spans started with ForkCtxSpan).
+	LatencyOperations []string
+
+	// EventSink receives the events of real spans; if nil, they are sent to
+	// x/net/trace when trace.debug.enable is set.
+	EventSink EventSink
+
+	// SpanExporters receive the recorded spans as they finish.
+	SpanExporters []SpanExporter
+
+	// Clock, if set, is used for the start and finish times of the spans and
+	// the timestamps of their events instead of the wall clock; see
+	// NewVirtualClockTracer.
+	Clock *timeutil.ManualTime
+}
+
 // NewTracer creates a Tracer. The cluster settings control whether
 // we trace to net/trace and/or lightstep.
 func NewTracer() opentracing.Tracer {
-	t := &Tracer{}
+	return NewTracerWithOptions(TracerOptions{})
+}
+
+// NewTracerWithOptions creates a Tracer with the given options. See NewTracer.
+func NewTracerWithOptions(opts TracerOptions) opentracing.Tracer {
+	t := &Tracer{creationStacks: opts.CreationStacks, eventSink: opts.EventSink, clock: opts.Clock}
+	t.exporters = append([]SpanExporter(nil), opts.SpanExporters...)
+	if t.eventSink == nil {
+		t.eventSink = netTraceSink{}
+	}
+	if len(opts.GlobalTags) > 0 {
+		t.globalTags = make(opentracing.Tags, len(opts.GlobalTags))
+		for k, v := range opts.GlobalTags {
+			t.globalTags[k] = v
+		}
+	}
+	if len(opts.LatencyOperations) > 0 {
+		t.latencyOps = make(map[string]struct{}, len(opts.LatencyOperations))
+		for _, op := range opts.LatencyOperations {
+			t.latencyOps[op] = struct{}{}
+		}
+	}
 	t.noopSpan.tracer = t
+	t.registerFinishHooks()
+	t.AddMaintenanceTask("span gc", spanGCInterval, t.collectAbandonedSpans)
 	updateShadowTracer(t)
 	tracerRegistry.Add(t)
 	return t
 }
 
-// Close cleans up any resources associated with a Tracer.
+// Close cleans up any resources associated with a Tracer. It stops the
+// maintenance worker (see StartMaintenance) and waits for it to exit. Close can
+// be called multiple times.
 func (t *Tracer) Close() {
+	if !t.stopMaintenance() {
+		return
+	}
 	tracerRegistry.Remove(t)
 	// Clean up any shadow tracer.
 	t.setShadowTracer(nil, nil)
 }
 
+// Flush sends out the spans buffered by the shadow tracer (if any), e.g.
+// before the process exits. It returns early with the context's error if the
+// context is canceled or its deadline expires first; the flush continues in
+// the background in that case.
+func (t *Tracer) Flush(ctx context.Context) error {
+	shadowTr := t.getShadowTracer()
+	if shadowTr == nil {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		shadowTr.Flush()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetNodeID sets the ID of the node, which is included in the spans recorded
+// from then on. The node ID is generally not known when the Tracer is created.
+func (t *Tracer) SetNodeID(nodeID int32) {
+	atomic.StoreInt32(&t.nodeID, nodeID)
+}
+
 // SetForceRealSpans sets forceRealSpans option to v and returns the previous
 // value.
 func (t *Tracer) SetForceRealSpans(v bool) bool {
@@ -154,56 +325,139 @@ var Recordable opentracing.StartSpanOption = recordableOption{}
 
 func (recordableOption) Apply(*opentracing.StartSpanOptions) {}
 
+type detachedTraceOption struct{}
+
+// WithDetachedTrace returns a StartSpanOption that makes the new span the root
+// of a new trace, linked to the referenced parent span (see TagLinkTraceID).
+func WithDetachedTrace() opentracing.StartSpanOption {
+	return detachedTraceOption{}
+}
+
+func (detachedTraceOption) Apply(*opentracing.StartSpanOptions) {}
+
+// Tags set on spans started with WithDetachedTrace, identifying the span that
+// caused the new trace. The IDs are formatted in hex, like on the wire.
+const (
+	TagLinkTraceID = "link.trace_id"
+	TagLinkSpanID  = "link.span_id"
+)
+
 // StartSpan is part of the opentracing.Tracer interface.
 func (t *Tracer) StartSpan(
 	operationName string, opts ...opentracing.StartSpanOption,
 ) opentracing.Span {
+	enabled := tracingEnabled.Get()
+	if !enabled && !forcesRealSpan(opts) && !referencesRecording(opts) {
+		return &t.noopSpan
+	}
+	if selfMeasurement.Get() {
+		defer t.overhead.record(overheadStartSpan, time.Now())
+	}
 	// Fast paths to avoid the allocation of StartSpanOptions below when tracing
 	// is disabled: if we have no options or a single SpanReference (the common
 	// case) with a noop context, return a noop span now.
 	if len(opts) == 1 {
 		if o, ok := opts[0].(opentracing.SpanReference); ok {
-			if _, noopCtx := o.ReferencedContext.(noopSpanContext); noopCtx {
+			if _, noopCtx := o.ReferencedContext.(noopSpanContext); noopCtx &&
+				!t.tracksLatency(operationName) {
 				return &t.noopSpan
 			}
 		}
 	}
 
-	netTrace := enableNetTrace.Get()
+	events := enabled && t.eventSink.Enabled()
 	shadowTr := t.getShadowTracer()
+	if !enabled {
+		shadowTr = nil
+	}
 
-	if len(opts) == 0 && !netTrace && shadowTr == nil && !t.forceRealSpans {
+	if len(opts) == 0 && !events && shadowTr == nil && !t.forceRealSpans &&
+		!t.tracksLatency(operationName) {
 		return &t.noopSpan
 	}
 
+	// Translate the opentracing options into our own.
 	var sso opentracing.StartSpanOptions
-	var recordable bool
+	var so spanOptions
 	for _, o := range opts {
 		o.Apply(&sso)
-		if _, ok := o.(recordableOption); ok {
-			recordable = true
+		switch o := o.(type) {
+		case recordableOption:
+			so.forceReal = true
+		case detachedTraceOption:
+			so.detached = true
+		case forceTraceOption:
+			so.forceTrace = true
+		case verboseOnErrorOption:
+			so.verboseOnError = true
+		case correlationIDOption:
+			so.correlationID = string(o)
+		case parallelGroupOption:
+			o.apply(&so)
+		case componentOption:
+			o.apply(&so)
 		}
 	}
-
-	var hasParent bool
-	var parentType opentracing.SpanReferenceType
-	var parentCtx *spanContext
-	var recordingGroup *spanGroup
-	var recordingType RecordingType
-
+	if !enabled {
+		so.forceTrace, so.verboseOnError = false, false
+	}
+	so.startTime = sso.StartTime
+	so.tags = sso.Tags
 	for _, r := range sso.References {
 		if r.Type != opentracing.ChildOfRef && r.Type != opentracing.FollowsFromRef {
 			continue
 		}
-		if r.ReferencedContext == nil {
-			continue
+		if so.setParent(r.ReferencedContext, r.Type) {
+			// TODO(radu): can we do something for multiple references?
+			break
 		}
-		if _, noopCtx := r.ReferencedContext.(noopSpanContext); noopCtx {
-			continue
+	}
+	return t.startSpan(operationName, &so, events, shadowTr)
+}
+
+// Start starts a new span, like StartSpan, but takes our own SpanOptions,
+// which are cheaper to process than opentracing.StartSpanOptions.
+func (t *Tracer) Start(operationName string, opts ...SpanOption) opentracing.Span {
+	if !tracingEnabled.Get() {
+		so := disabledSpanOptions(opts)
+		if so == nil {
+			return &t.noopSpan
 		}
-		hasParent = true
-		parentType = r.Type
-		parentCtx = r.ReferencedContext.(*spanContext)
+		return t.startSpan(operationName, so, false /* events */, nil /* shadowTr */)
+	}
+	if selfMeasurement.Get() {
+		defer t.overhead.record(overheadStartSpan, time.Now())
+	}
+	events := t.eventSink.Enabled()
+	shadowTr := t.getShadowTracer()
+
+	if len(opts) == 0 && !events && shadowTr == nil && !t.forceRealSpans &&
+		!t.tracksLatency(operationName) {
+		return &t.noopSpan
+	}
+
+	var so spanOptions
+	for _, o := range opts {
+		o.apply(&so)
+	}
+	return t.startSpan(operationName, &so, events, shadowTr)
+}
+
+// startSpan implements StartSpan and Start.
+func (t *Tracer) startSpan(
+	operationName string, so *spanOptions, events bool, shadowTr *shadowTracer,
+) opentracing.Span {
+	recordable, detached := so.forceReal, so.detached
+	tracked := t.tracksLatency(operationName)
+	recordable = recordable || tracked
+
+	hasParent := so.parent != nil
+	parentType := so.parentType
+	parentCtx := so.parent
+	var recordingGroup *spanGroup
+	var recordingType RecordingType
+
+	if hasParent {
 		if parentCtx.recordingGroup != nil {
 			recordingGroup = parentCtx.recordingGroup
 			recordingType = parentCtx.recordingType
@@ -211,44 +465,155 @@ func (t *Tracer) StartSpan(
 			// Automatically enable recording if we have the Snowball baggage item.
 			recordingGroup = new(spanGroup)
 			recordingType = SnowballRecording
+			recordingGroup.requesterSchema = parentCtx.recordingSchema
+			if v, ok := parentCtx.Baggage[LogBudget]; ok {
+				recordingGroup.initLogBudget(v)
+			}
+		}
+	}
+	correlationID := correlationIDFor(so)
+	var link spanMeta
+	if hasParent && detached {
+		// Start a new trace; only remember where we came from.
+		link = parentCtx.spanMeta
+		hasParent = false
+		parentCtx = nil
+		recordingGroup = nil
+	}
+	var namedRecordings []namedRecording
+	if hasParent {
+		namedRecordings = parentCtx.namedRecordings
+	}
+	forced := so.forceTrace || (hasParent && parentCtx.Baggage[ForceTraceBaggage] != "")
+	if forced && recordingGroup == nil {
+		recordingGroup = new(spanGroup)
+		recordingType = SnowballRecording
+	}
+	if so.recording && recordingGroup == nil {
+		recordingGroup = new(spanGroup)
+		recordingType = so.recordingType
+	}
+	// The spans of traces with the VerboseOnError policy are real spans, so
+	// that errors can be noticed.
+	armed := hasParent && parentCtx.Baggage[VerboseOnErrorBaggage] != ""
+	recordable = recordable || armed || so.verboseOnError
+	if armed && recordingGroup == nil {
+		if recordingGroup = verboseGroupFor(parentCtx); recordingGroup != nil {
+			recordingType = SnowballRecording
 		}
-		// TODO(radu): can we do something for multiple references?
-		break
 	}
+	var traceID uint64
+	var samplingReason string
 	if hasParent {
 		// We use the parent's shadow tracer, to avoid inconsistency inside a
-		// trace when the shadow tracer changes.
-		shadowTr = parentCtx.shadowTr
+		// trace when the shadow tracer changes. Forced traces which were not
+		// sampled (or which come from a client) start using the current one.
+		if parentCtx.shadowTr != nil || !forced {
+			shadowTr = parentCtx.shadowTr
+		} else if shadowTr != nil {
+			samplingReason = samplingReasonForced
+		}
+		traceID = parentCtx.TraceID
+	} else {
+		// No parent span; allocate a new trace ID.
+		traceID = uint64(rand.Int63())
+		if shadowTr != nil {
+			if forced {
+				atomic.AddInt64(&t.samplingStats.Kept, 1)
+				samplingReason = samplingReasonForced
+			} else {
+				var keep bool
+				if keep, samplingReason = t.shouldSample(operationName, so.component, traceID); !keep {
+					shadowTr = nil
+				}
+			}
+		}
 	}
 
 	// If tracing is disabled, the Recordable option wasn't passed, and we're not
 	// part of a recording or snowball trace, avoid overhead and return a noop
 	// span.
-	if !recordable && recordingGroup == nil && shadowTr == nil && !netTrace && !t.forceRealSpans {
+	//
+	// If we have a parent and trace.propagate_ids.enabled is set, we instead
+	// create a carrier-only span, which is a real span without any recording
+	// capabilities but which keeps the trace identity for downstream operations.
+	var carrier, measureOnly bool
+	if recordingGroup == nil && namedRecordings == nil && shadowTr == nil &&
+		!events && !t.forceRealSpans {
+		noop := !hasParent || !propagateTraceIDs.Get()
+		if !recordable {
+			if noop {
+				return t.noopSpanFor(parentCtx)
+			}
+			carrier = true
+		}
+		measureOnly = noop && tracked && !so.forceReal && !armed && !so.verboseOnError
+	}
+
+	var depth int32
+	if hasParent {
+		depth = parentCtx.depth + 1
+	}
+	if exceedsSpanLimits(depth, recordingGroup) {
 		return &t.noopSpan
 	}
+	var cost *traceCost
+	if hasParent {
+		cost = parentCtx.cost
+	}
+	if cost.cutOff() || (hasParent && parentCtx.partial.dropChild()) {
+		return &t.noopSpan
+	}
+	if cost == nil {
+		cost = new(traceCost)
+	}
+	cost.addSpan()
 
 	s := &span{
-		tracer:    t,
-		operation: operationName,
-		startTime: sso.StartTime,
+		tracer:         t,
+		operation:      operationName,
+		startTime:      so.startTime,
+		link:           link,
+		carrier:        carrier,
+		depth:          depth,
+		cost:           cost,
+		goroutine:      goid.Get(),
+		tracked:        tracked,
+		measureOnly:    measureOnly,
+		parallelGroup:  so.parallelGroup,
+		verboseOnError: armed || so.verboseOnError,
+		partial:        partialSamplingFor(operationName),
+		start: spanStart{
+			hasParent:      hasParent,
+			parentType:     parentType,
+			remoteParent:   hasParent && parentCtx.cost == nil,
+			tags:           so.tags,
+			samplingReason: samplingReason,
+		},
 	}
 	if s.startTime.IsZero() {
-		s.startTime = time.Now()
+		s.startTime = t.now()
 	}
 	s.mu.duration = -1
+	s.maybeStartProcessStats()
 
-	for k, v := range sso.Tags {
+	for k, v := range so.tags {
 		s.SetTag(k, v)
 	}
+	s.setComponent(so.component)
+	if link.TraceID != 0 {
+		s.SetTag(TagLinkTraceID, strconv.FormatUint(link.TraceID, 16))
+		s.SetTag(TagLinkSpanID, strconv.FormatUint(link.SpanID, 16))
+	}
 
-	if !hasParent {
-		// No parent Span; allocate new trace id.
-		s.TraceID = uint64(rand.Int63())
+	s.TraceID = traceID
+	s.SpanID = uint64(rand.Int63())
+
+	if hasParent {
+		s.maybeStartExecTask(&parentCtx.execTask)
 	} else {
-		s.TraceID = parentCtx.TraceID
+		s.maybeStartExecTask(nil)
 	}
-	s.SpanID = uint64(rand.Int63())
 
 	if shadowTr != nil {
 		var parentShadowCtx opentracing.SpanContext
@@ -256,16 +621,22 @@ func (t *Tracer) StartSpan(
 			parentShadowCtx = parentCtx.shadowCtx
 		}
 		linkShadowSpan(s, shadowTr, parentShadowCtx, parentType)
+		if samplingReason != "" {
+			s.SetTag(TagSamplingReason, samplingReason)
+		}
 	}
 
 	// Start recording if necessary.
 	if recordingGroup != nil {
 		s.enableRecording(recordingGroup, recordingType)
 	}
+	if namedRecordings != nil {
+		s.inheritNamedRecordings(namedRecordings)
+	}
 
-	if netTrace {
-		s.netTr = trace.New("tracing", operationName)
-		s.netTr.SetMaxEvents(maxLogsPerSpan)
+	if events {
+		s.events = t.eventSink.NewSpanEvents(operationName)
+		s.eventsGlobalTags()
 	}
 
 	if hasParent {
@@ -279,16 +650,45 @@ func (t *Tracer) StartSpan(
 		}
 	}
 
-	if netTrace || shadowTr != nil {
-		// Copy baggage items to tags so they show up in the shadow tracer UI or x/net/trace.
+	if events || shadowTr != nil {
+		// Copy baggage items to tags so they show up in the shadow tracer UI or
+		// the event sink.
 		for k, v := range s.mu.Baggage {
 			s.SetTag(k, v)
 		}
 	}
+	if so.forceTrace {
+		s.SetBaggageItem(ForceTraceBaggage, "1")
+	}
+	if so.verboseOnError {
+		s.SetBaggageItem(VerboseOnErrorBaggage, "1")
+	}
+	if correlationID != "" && (so.correlationID != "" || !hasParent) {
+		s.SetTag(TagCorrelationID, correlationID)
+		s.SetBaggageItem(CorrelationIDBaggage, correlationID)
+	}
+	if !hasParent {
+		s.setRootBaggage()
+	}
 
+	// Skip startSpan and StartSpan/Start.
+	s.maybeSetCreationStack(2)
+	t.maybeRegisterSpan(s)
+	s.notifyStart()
 	return s
 }
 
+// noopSpanFor returns the noop span for an operation with the given parent
+// context (if any): a pass-through span if the parent is an external context
+// and the pass-through mode is enabled (see ExtractExternal), the shared noop
+// span otherwise.
+func (t *Tracer) noopSpanFor(parentCtx *spanContext) opentracing.Span {
+	if parentCtx != nil && parentCtx.external && externalPassThrough.Get() {
+		return &noopSpan{tracer: t, passThrough: parentCtx}
+	}
+	return &t.noopSpan
+}
+
 // StartChildSpan creates a child span of the given parent span. This is
 // functionally equivalent to:
 // parentSpan.Tracer().(*Tracer).StartSpan(opName, opentracing.ChildOf(parentSpan.Context()))
@@ -306,21 +706,56 @@ func StartChildSpan(
 ) opentracing.Span {
 	tr := parentSpan.Tracer().(*Tracer)
 	// If tracing is disabled, avoid overhead and return a noop span.
-	if IsBlackHoleSpan(parentSpan) {
+	if IsBlackHoleSpan(parentSpan) && !isCarrierSpan(parentSpan) {
+		if !tracingEnabled.Get() {
+			return &tr.noopSpan
+		}
+		if tr.tracksLatency(operationName) {
+			// There is no trace to be part of, but we still want to measure the
+			// operation.
+			return tr.Start(operationName)
+		}
+		if n, ok := parentSpan.(*noopSpan); ok && n.passThrough != nil {
+			// Keep passing the external context through.
+			return n
+		}
 		return &tr.noopSpan
 	}
+	if selfMeasurement.Get() {
+		defer tr.overhead.record(overheadStartSpan, time.Now())
+	}
 
 	pSpan := parentSpan.(*span)
+	pSpan.mu.Lock()
+
+	var recordingGroup *spanGroup
+	if pSpan.isRecording() && !separateRecording {
+		recordingGroup = pSpan.mu.recordingGroup
+	}
+	if exceedsSpanLimits(pSpan.depth+1, recordingGroup) || pSpan.cost.cutOff() ||
+		pSpan.partial.dropChild() {
+		pSpan.mu.Unlock()
+		return &tr.noopSpan
+	}
+	pSpan.cost.addSpan()
 
 	s := &span{
-		tracer:       tr,
-		operation:    operationName,
-		startTime:    time.Now(),
-		parentSpanID: pSpan.SpanID,
+		tracer:         tr,
+		operation:      operationName,
+		startTime:      tr.now(),
+		parentSpanID:   pSpan.SpanID,
+		carrier:        pSpan.carrier,
+		depth:          pSpan.depth + 1,
+		cost:           pSpan.cost,
+		goroutine:      goid.Get(),
+		tracked:        tr.tracksLatency(operationName),
+		start:          spanStart{hasParent: true, parentType: opentracing.ChildOfRef},
+		verboseOnError: pSpan.verboseOnError,
+		partial:        partialSamplingFor(operationName),
 	}
+	s.maybeStartProcessStats()
 
 	// Copy baggage from parent.
-	pSpan.mu.Lock()
 	if l := len(pSpan.mu.Baggage); l > 0 {
 		s.mu.Baggage = make(map[string]string, l)
 		for k, v := range pSpan.mu.Baggage {
@@ -330,13 +765,15 @@ func StartChildSpan(
 
 	s.TraceID = pSpan.TraceID
 	s.SpanID = uint64(rand.Int63())
+	s.maybeStartExecTask(&pSpan.execTask)
 
 	if pSpan.shadowTr != nil {
 		linkShadowSpan(s, pSpan.shadowTr, pSpan.shadowSpan.Context(), opentracing.ChildOfRef)
 	}
 
-	if pSpan.netTr != nil || pSpan.shadowTr != nil {
-		// Copy baggage items to tags so they show up in the shadow tracer UI or x/net/trace.
+	if pSpan.events != nil || pSpan.shadowTr != nil {
+		// Copy baggage items to tags so they show up in the shadow tracer UI or
+		// the event sink.
 		for k, v := range s.mu.Baggage {
 			s.SetTag(k, v)
 		}
@@ -344,14 +781,22 @@ func StartChildSpan(
 
 	// Start recording if necessary.
 	if pSpan.isRecording() {
-		recordingGroup := pSpan.mu.recordingGroup
 		if separateRecording {
 			recordingGroup = new(spanGroup)
 		}
 		s.enableRecording(recordingGroup, pSpan.mu.recordingType)
+	} else if g := pSpan.cost.verboseGroup(); g != nil && s.verboseOnError {
+		s.enableRecording(g, SnowballRecording)
+	}
+	if named := pSpan.mu.namedRecordings; named != nil {
+		s.inheritNamedRecordings(named)
 	}
 
 	pSpan.mu.Unlock()
+	// Skip StartChildSpan.
+	s.maybeSetCreationStack(1)
+	tr.maybeRegisterSpan(s)
+	s.notifyStart()
 	return s
 }
 
@@ -368,6 +813,9 @@ func (fn textMapWriterFn) Set(key, val string) {
 func (t *Tracer) Inject(
 	osc opentracing.SpanContext, format interface{}, carrier interface{},
 ) error {
+	if selfMeasurement.Get() {
+		defer t.overhead.record(overheadInject, time.Now())
+	}
 	if _, noopCtx := osc.(noopSpanContext); noopCtx {
 		// Fast path when tracing is disabled. Extract will accept an empty map as a
 		// noop context.
@@ -388,13 +836,36 @@ func (t *Tracer) Inject(
 	if !ok {
 		return opentracing.ErrInvalidSpanContext
 	}
+	return injectSpanContext(sc, format, mapWriter)
+}
 
-	mapWriter.Set(fieldNameTraceID, strconv.FormatUint(sc.TraceID, 16))
-	mapWriter.Set(fieldNameSpanID, strconv.FormatUint(sc.SpanID, 16))
+// injectSpanContext implements Inject once the arguments have been checked.
+func injectSpanContext(
+	sc *spanContext, format interface{}, mapWriter opentracing.TextMapWriter,
+) error {
+	injectSpanMeta(sc.spanMeta, mapWriter)
+	if contextTTL.Get() > 0 {
+		mapWriter.Set(fieldNameInjectTime, formatInjectTime(time.Now()))
+	}
 
+	budgetShare, hasBudget := int64(0), false
+	if sc.recordingGroup != nil {
+		budgetShare, hasBudget = sc.recordingGroup.takeLogBudgetShare()
+	}
 	for k, v := range sc.Baggage {
+		if k == LogBudget && hasBudget {
+			// Hand over part of the local budget instead.
+			v = strconv.FormatInt(budgetShare, 10)
+		}
+		if k == VerboseOnErrorBaggage && sc.cost.verboseGroup() != nil {
+			// Let the other node know that the trace was upgraded.
+			v = verboseOnErrorUpgraded
+		}
 		mapWriter.Set(prefixBaggage+k, v)
 	}
+	if sc.Baggage[Snowball] != "" {
+		mapWriter.Set(fieldNameRecordingSchema, strconv.Itoa(int(CurrentRecordingSchema)))
+	}
 
 	if sc.shadowTr != nil {
 		mapWriter.Set(fieldNameShadowType, sc.shadowTr.Typ())
@@ -422,6 +893,14 @@ func (fn textMapReaderFn) ForeachKey(handler func(key, val string) error) error
 // It always returns a valid context, even in error cases (this is assumed by the
 // grpc-opentracing interceptor).
 func (t *Tracer) Extract(format interface{}, carrier interface{}) (opentracing.SpanContext, error) {
+	return t.extract(format, carrier, true /* authorize */)
+}
+
+// extract implements Extract. The baggage is only passed to the
+// BaggageAuthorizer if authorize is set.
+func (t *Tracer) extract(
+	format interface{}, carrier interface{}, authorize bool,
+) (opentracing.SpanContext, error) {
 	// We only support the HTTPHeaders/TextMap format.
 	if format != opentracing.HTTPHeaders && format != opentracing.TextMap {
 		return noopSpanContext{}, opentracing.ErrUnsupportedFormat
@@ -433,14 +912,17 @@ func (t *Tracer) Extract(format interface{}, carrier interface{}) (opentracing.S
 	}
 
 	var sc spanContext
+	// IDs from a traceparent field; only used if the legacy fields are absent.
+	var traceparent spanMeta
 	var shadowType string
 	var shadowCarrier opentracing.TextMapCarrier
+	var stale bool
 
 	err := mapReader.ForeachKey(func(k, v string) error {
 		switch k = strings.ToLower(k); k {
 		case fieldNameTraceID:
 			var err error
-			sc.TraceID, err = strconv.ParseUint(v, 16, 64)
+			sc.TraceID, err = ParseTraceID(v)
 			if err != nil {
 				return opentracing.ErrSpanContextCorrupted
 			}
@@ -450,8 +932,26 @@ func (t *Tracer) Extract(format interface{}, carrier interface{}) (opentracing.S
 			if err != nil {
 				return opentracing.ErrSpanContextCorrupted
 			}
+		case fieldNameTraceparent:
+			var err error
+			traceparent, err = parseTraceparent(v)
+			if err != nil {
+				return opentracing.ErrSpanContextCorrupted
+			}
 		case fieldNameShadowType:
 			shadowType = v
+		case fieldNameRecordingSchema:
+			var err error
+			sc.recordingSchema, err = parseRecordingSchema(v)
+			if err != nil {
+				return opentracing.ErrSpanContextCorrupted
+			}
+		case fieldNameInjectTime:
+			var err error
+			stale, err = isStaleInjectTime(v, contextTTL.Get(), time.Now())
+			if err != nil {
+				return opentracing.ErrSpanContextCorrupted
+			}
 		default:
 			if strings.HasPrefix(k, prefixBaggage) {
 				if sc.Baggage == nil {
@@ -472,10 +972,25 @@ func (t *Tracer) Extract(format interface{}, carrier interface{}) (opentracing.S
 		return noopSpanContext{}, err
 	}
 	if sc.TraceID == 0 && sc.SpanID == 0 {
+		sc.spanMeta = traceparent
+	}
+	if sc.TraceID == 0 && sc.SpanID == 0 {
+		return noopSpanContext{}, nil
+	}
+	if stale {
+		// Treat contexts older than the TTL as absent.
 		return noopSpanContext{}, nil
 	}
+	if sc.Baggage != nil && authorize {
+		t.authorizeBaggage(carrier, sc.Baggage)
+	}
+	if sc.recordingSchema == 0 && sc.Baggage[Snowball] != "" {
+		// The context comes from a node that predates schema negotiation.
+		sc.recordingSchema = RecordingSchemaV1
+	}
 
 	if shadowType != "" {
+		sc.shadowType = shadowType
 		// Using a shadow tracer only works if all hosts use the same shadow tracer.
 		// If that's not the case, ignore the shadow context.
 		if shadowTr := t.getShadowTracer(); shadowTr != nil &&
@@ -515,7 +1030,7 @@ func ForkCtxSpan(ctx context.Context, opName string) (context.Context, opentraci
 			return ctx, span
 		}
 		tr := span.Tracer()
-		if IsBlackHoleSpan(span) {
+		if IsBlackHoleSpan(span) && !isCarrierSpan(span) {
 			ns := &tr.(*Tracer).noopSpan
 			return opentracing.ContextWithSpan(ctx, ns), ns
 		}
@@ -535,13 +1050,13 @@ func ChildSpan(ctx context.Context, opName string) (context.Context, opentracing
 	if span == nil {
 		return ctx, nil
 	}
-	if _, noop := span.(*noopSpan); noop {
+	if sp, noop := span.(*noopSpan); noop && !sp.tracer.tracksLatency(opName) {
 		// Optimization: avoid ContextWithSpan call if tracing is disabled.
 		return ctx, span
 	}
-	tr := span.Tracer()
-	if IsBlackHoleSpan(span) {
-		ns := &tr.(*Tracer).noopSpan
+	tr := span.Tracer().(*Tracer)
+	if IsBlackHoleSpan(span) && !isCarrierSpan(span) && !tr.tracksLatency(opName) {
+		ns := &tr.noopSpan
 		return opentracing.ContextWithSpan(ctx, ns), ns
 	}
 	newSpan := StartChildSpan(opName, span, false /* !separateRecording */)
@@ -598,8 +1113,10 @@ func StartSnowballTrace(
 //   	t.Fatal(err)
 //   }
 //
-// The event lines can (and generally should) omit the file:line part that they
-// might contain (depending on the level at which they were logged).
+// The locations of the events (see FieldLocation) and the payloads of
+// structured events (see FieldStructured) are not matched. The event
+// lines can (and generally should) also omit the file:line part that events
+// recorded by older versions contain in their message.
 //
 // Note: this test function is in this file because it needs to be used by
 // both tests in the tracing package and tests outside of it, and the function
@@ -624,6 +1141,9 @@ func TestingCheckRecordedSpans(recSpans []RecordedSpan, expected string) error {
 		for _, l := range rs.Logs {
 			msg := ""
 			for _, f := range l.Fields {
+				if f.Key == FieldLocation || f.Key == FieldStructured {
+					continue
+				}
 				msg = msg + fmt.Sprintf("  %s: %v", f.Key, f.Value)
 			}
 			row("%s", msg)
@@ -19,11 +19,10 @@ package tracing
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"sync/atomic"
 	"time"
 
-	"golang.org/x/net/trace"
-
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	opentracing "github.com/opentracing/opentracing-go"
 	otlog "github.com/opentracing/opentracing-go/log"
@@ -45,10 +44,36 @@ type spanContext struct {
 	// Underlying shadow tracer info and context (optional).
 	shadowTr  *shadowTracer
 	shadowCtx opentracing.SpanContext
+	// The shadow tracer type found in the carrier, for contexts created by
+	// Extract; see ValidateSpanMeta.
+	shadowType string
 
 	// If set, all spans derived from this context are being recorded as a group.
 	recordingGroup *spanGroup
 	recordingType  RecordingType
+	// The named recordings that local spans derived from this context are part
+	// of; see StartNamedRecording.
+	namedRecordings []namedRecording
+
+	// Execution tracer task of the span; children's tasks are nested under it.
+	execTask execTask
+
+	// Nesting depth of the span; zero for remote contexts.
+	depth int32
+
+	// Cost of the trace on this node; nil for remote contexts.
+	cost *traceCost
+
+	// Set if the children of the span are sampled; see partialSampling. Nil
+	// for remote contexts.
+	partial *partialSampling
+
+	// The recording schema advertised by the node that injected the context,
+	// for contexts created by Extract; see fieldNameRecordingSchema.
+	recordingSchema RecordingSchemaVersion
+
+	// Set for contexts created by ExtractExternal.
+	external bool
 
 	// The span's associated baggage.
 	Baggage map[string]string
@@ -80,20 +105,95 @@ type span struct {
 	spanMeta
 
 	parentSpanID uint64
+	// depth is the number of local ancestors of the span.
+	depth int32
+	// cost is shared by all the local spans of the trace; see TraceCost.
+	cost *traceCost
+
+	// The span from which a detached trace was started (see WithDetachedTrace);
+	// zero otherwise.
+	link spanMeta
 
 	tracer *Tracer
 
-	// x/net/trace.Trace instance; nil if not tracing to x/net/trace.
-	netTr trace.Trace
+	// carrier is set for carrier-only spans, which are created when
+	// trace.propagate_ids.enabled is set for operations that would otherwise
+	// get a noopSpan: they don't record anything, but they propagate the trace
+	// and span IDs to child spans and through Inject.
+	carrier bool
+
+	// Stats of the span's component; nil if the span wasn't classified. See
+	// WithComponent.
+	component *componentCounters
+
+	// verboseOnError is set for the spans of traces with the VerboseOnError
+	// policy. Such spans are not black holes, so that errors can be recorded
+	// on them.
+	verboseOnError bool
+
+	// Destination of the span's events in the Tracer's EventSink; nil if the
+	// sink was not enabled when the span was started.
+	events SpanEvents
 	// Shadow tracer and span; nil if not using a shadow tracer.
 	shadowTr   *shadowTracer
 	shadowSpan opentracing.Span
 
 	operation string
 	startTime time.Time
+	// ID of the goroutine that created the span.
+	goroutine int64
+	// The group of siblings the span runs concurrently with; see
+	// WithParallelGroup.
+	parallelGroup string
+	// The options the span was started with.
+	start spanStart
+	// ID of the goroutine that owns the span, for spans that were handed off
+	// with DetachSpan; accessed atomically. See checkOwner.
+	owner int64
+
+	// CPU usage snapshot taken when the span was started; nil unless
+	// processStatsEnabled is set.
+	processStart *processStats
+
+	// Go execution tracer task; only active if execTasksEnabled is set and an
+	// execution trace was being captured when the span started.
+	execTask execTask
+
+	// Set if the children of the span are sampled; see partialSampling.
+	partial *partialSampling
 
 	// Atomic flag used to avoid taking the mutex in the hot path.
 	recording int32
+	// Atomic flag set by SetVerbose(false); when set, log messages are not
+	// captured in the recording even if the span is recording.
+	quiet int32
+	// Atomic flag set when the span is tagged with error=true; see
+	// Tracer.ErrorRecordings.
+	failed int32
+	// Atomic summary of mu.namedRecordings: namedNone, namedStructural, or
+	// namedVerbose if at least one of the recordings is verbose.
+	namedState int32
+	// tracked is set if the span's duration is added to the tracer's latency
+	// histograms; see TracerOptions.LatencyOperations.
+	tracked bool
+	// measureOnly is set if the span is only real because it is tracked. Until
+	// it is sampled (e.g. it starts recording), its context is a noop context,
+	// so that its children are noop spans as they would be without it.
+	measureOnly bool
+	// retained is set atomically when the recording of the span is kept or
+	// exported as it finishes; see exemplarTraceID.
+	retained int32
+	// registered is set if the span is part of the Tracer's activeSpans; in
+	// that case, finished is set atomically when the span is finished (either
+	// by its owner or by the span GC).
+	registered bool
+	finished   int32
+
+	// ctx caches the span's context (a *spanContext) so that repeated calls to
+	// Context() are lock- and allocation-free. The cached context is never
+	// modified; it is reset (under mu) whenever the state it captures changes,
+	// i.e. when the baggage is modified or when recording starts or stops.
+	ctx atomic.Value
 
 	mu struct {
 		syncutil.Mutex
@@ -102,11 +202,26 @@ type span struct {
 
 		recordingGroup *spanGroup
 		recordingType  RecordingType
-		recordedLogs   []opentracing.LogRecord
-		// tags are only set when recording.
+		// namedRecordings are the named recordings the span is part of; see
+		// setNamedRecordingsLocked.
+		namedRecordings []namedRecording
+		recordedLogs    []opentracing.LogRecord
+		// tags are all the tags set on the span (see GetSpanTags). Only the ones
+		// set while recording are part of the recording.
 		// TODO(radu): perhaps we want a recording to capture all the tags (even
 		// those that were set before recording started)?
-		tags opentracing.Tags
+		tags map[string]spanTag
+
+		// Aggregate retry counters maintained by AnnotateRetry.
+		retries      int
+		retryBackoff time.Duration
+		// Total lock wait time maintained by RecordContention.
+		contentionTime time.Duration
+		// Number of tags dropped because of the tag count limit; see
+		// admitTagLocked.
+		tagsDropped int
+		// Tags to set on Finish if the span is slow; see TagIfSlow.
+		slowTags []slowTag
 
 		// The span's associated baggage.
 		Baggage map[string]string
@@ -127,17 +242,27 @@ func (s *span) enableRecording(group *spanGroup, recType RecordingType) {
 	atomic.StoreInt32(&s.recording, 1)
 	s.mu.recordingGroup = group
 	s.mu.recordingType = recType
+	s.resetContextLocked()
+	if v, ok := s.mu.Baggage[LogBudget]; ok && atomic.LoadInt32(&group.budgeted) == 0 {
+		group.initLogBudget(v)
+	}
+	// Clear any previously recorded logs, unless they belong to named
+	// recordings.
+	if s.mu.namedRecordings == nil {
+		s.mu.recordedLogs = nil
+	}
+	// This goes after the logs are cleared, so that the change of the baggage
+	// can be recorded (see trace.debug.baggage_events.enabled).
 	if recType == SnowballRecording {
 		s.setBaggageItemLocked(Snowball, "1")
 	}
-	// Clear any previously recorded logs.
-	s.mu.recordedLogs = nil
 	s.mu.Unlock()
 
 	group.addSpan(s)
 }
 
-// GetSpanTag returns the value of a tag in a span.
+// GetSpanTag returns the value of a tag in a span. Only tags that were set
+// while the span was recording are returned; see GetSpanTags for all the tags.
 func GetSpanTag(os opentracing.Span, key string) interface{} {
 	if _, noop := os.(*noopSpan); noop {
 		return nil
@@ -145,7 +270,55 @@ func GetSpanTag(os opentracing.Span, key string) interface{} {
 	sp := os.(*span)
 	sp.mu.Lock()
 	defer sp.mu.Unlock()
-	return sp.mu.tags[key]
+	if t := sp.mu.tags[key]; t.recorded {
+		return t.value
+	}
+	return nil
+}
+
+// GetSpanTags returns a copy of the tags set on a span (whether recording or
+// not), so that middleware can make decisions based on tags set earlier in a
+// request. Returns nil for noop spans and spans from other tracers.
+func GetSpanTags(os opentracing.Span) map[string]interface{} {
+	sp, ok := os.(*span)
+	if !ok {
+		return nil
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	return sp.allTagsLocked()
+}
+
+// HasSpanTag returns true if the given tag was set on a span; see GetSpanTags.
+func HasSpanTag(os opentracing.Span, key string) bool {
+	sp, ok := os.(*span)
+	if !ok {
+		return false
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	_, ok = sp.mu.tags[key]
+	return ok
+}
+
+// spanTag is the value of a tag set on a span, and whether it was set while the
+// span was recording.
+type spanTag struct {
+	value    interface{}
+	recorded bool
+}
+
+// allTagsLocked returns a copy of all the tags set on the span, or nil if
+// there are none.
+func (s *span) allTagsLocked() opentracing.Tags {
+	if len(s.mu.tags) == 0 {
+		return nil
+	}
+	res := make(opentracing.Tags, len(s.mu.tags))
+	for k, t := range s.mu.tags {
+		res[k] = t.value
+	}
+	return res
 }
 
 // StartRecording enables recording on the span. Events from this point forward
@@ -175,14 +348,67 @@ func StopRecording(os opentracing.Span) {
 
 func (s *span) disableRecording() {
 	s.mu.Lock()
+	s.disableRecordingLocked()
+	s.mu.Unlock()
+}
+
+func (s *span) disableRecordingLocked() {
 	atomic.StoreInt32(&s.recording, 0)
 	s.mu.recordingGroup = nil
+	s.resetContextLocked()
 	if s.mu.recordingType == SnowballRecording {
 		// Clear the Snowball baggage item, assuming that it was set by
 		// enableRecording().
-		s.setBaggageItemLocked(Snowball, "")
+		s.deleteBaggageItemLocked(Snowball)
+	}
+}
+
+// StopRecordingAndGet stops the recording on all the local spans that are part
+// of it and returns it, releasing its memory. Returns nil if the span is not
+// recording.
+func StopRecordingAndGet(os opentracing.Span, opts ...RecordingOption) Recording {
+	s, ok := os.(*span)
+	if !ok || !s.isRecording() {
+		return nil
 	}
+	s.mu.Lock()
+	group := s.mu.recordingGroup
 	s.mu.Unlock()
+	if group == nil {
+		return nil
+	}
+	rec := group.recording(opts)
+	group.release()
+	return rec
+}
+
+// SetVerbose toggles the capture of log messages for an open span, starting a
+// SingleNodeRecording if needed. Existing child spans are not affected.
+func SetVerbose(os opentracing.Span, verbose bool) {
+	if _, noop := os.(*noopSpan); noop {
+		panic("SetVerbose called on NoopSpan; use the Force option for StartSpan")
+	}
+	s := os.(*span)
+	if !verbose {
+		atomic.StoreInt32(&s.quiet, 1)
+		return
+	}
+	atomic.StoreInt32(&s.quiet, 0)
+	if !s.isRecording() {
+		s.enableRecording(new(spanGroup), SingleNodeRecording)
+	}
+}
+
+// IsVerbose returns true if log messages for the given span are being captured
+// in a recording.
+func IsVerbose(os opentracing.Span) bool {
+	s, ok := os.(*span)
+	return ok && s.isVerbose()
+}
+
+func (s *span) isVerbose() bool {
+	return (s.isRecording() && atomic.LoadInt32(&s.quiet) == 0) ||
+		atomic.LoadInt32(&s.namedState) == namedVerbose
 }
 
 // IsRecordable returns true if {Start,Stop}Recording() can be called on this
@@ -195,11 +421,48 @@ func IsRecordable(os opentracing.Span) bool {
 	return isCockroachSpan
 }
 
+// RecordingOption is an option for GetRecording.
+type RecordingOption interface {
+	apply(*recordingOptions)
+}
+
+type recordingOptions struct {
+	subtreeOf    uint64
+	granularity  time.Duration
+	forRequester bool
+}
+
+type subtreeOption uint64
+
+func (o subtreeOption) apply(opts *recordingOptions) {
+	opts.subtreeOf = uint64(o)
+}
+
+// WithSubtreeOf is a GetRecording option which restricts the result to the span
+// with the given ID and its descendants (local or imported from other nodes).
+// The result is empty if no such span is part of the recording.
+func WithSubtreeOf(spanID uint64) RecordingOption {
+	return subtreeOption(spanID)
+}
+
+type granularityOption time.Duration
+
+func (o granularityOption) apply(opts *recordingOptions) {
+	opts.granularity = time.Duration(o)
+}
+
+// WithTimestampGranularity is a GetRecording option which rounds the
+// timestamps in the recording to the given granularity; see
+// Recording.RoundTimestamps.
+func WithTimestampGranularity(granularity time.Duration) RecordingOption {
+	return granularityOption(granularity)
+}
+
 // GetRecording retrieves the current recording, if the span has
 // recording enabled. This can be called while spans that are part of the
 // record are still open; it can run concurrently with operations on those
 // spans.
-func GetRecording(os opentracing.Span) []RecordedSpan {
+func GetRecording(os opentracing.Span, opts ...RecordingOption) Recording {
 	if _, noop := os.(*noopSpan); noop {
 		return nil
 	}
@@ -213,12 +476,13 @@ func GetRecording(os opentracing.Span) []RecordedSpan {
 	if group == nil {
 		return nil
 	}
-	return group.getSpans()
+	return group.recording(opts)
 }
 
 // ImportRemoteSpans adds RecordedSpan data to the recording of the given span;
 // these spans will be part of the result of GetRecording. Used to import
-// recorded traces from other nodes.
+// recorded traces from other nodes. Spans downgraded for an older schema (see
+// DowngradeRecording) are upgraded in place.
 func ImportRemoteSpans(os opentracing.Span, remoteSpans []RecordedSpan) error {
 	s := os.(*span)
 	s.mu.Lock()
@@ -227,6 +491,10 @@ func ImportRemoteSpans(os opentracing.Span, remoteSpans []RecordedSpan) error {
 	if group == nil {
 		return errors.New("adding Raw Spans to a span that isn't recording")
 	}
+	UpgradeRecording(remoteSpans)
+	if spanIDChecks {
+		group.checkSpanIDReuse(s.tracer, remoteSpans)
+	}
 	group.Lock()
 	group.remoteSpans = append(group.remoteSpans, remoteSpans...)
 	group.Unlock()
@@ -244,7 +512,14 @@ func IsBlackHoleSpan(s opentracing.Span) bool {
 		return true
 	}
 	sp := s.(*span)
-	return !sp.isRecording() && sp.netTr == nil && sp.shadowTr == nil
+	return !sp.isRecording() && !sp.hasNamedRecordings() && sp.events == nil && sp.shadowTr == nil &&
+		!sp.verboseOnError
+}
+
+// isCarrierSpan returns true if the span is a carrier-only span.
+func isCarrierSpan(os opentracing.Span) bool {
+	sp, ok := os.(*span)
+	return ok && sp.carrier
 }
 
 // Finish is part of the opentracing.Span interface.
@@ -254,29 +529,62 @@ func (s *span) Finish() {
 
 // FinishWithOptions is part of the opentracing.Span interface.
 func (s *span) FinishWithOptions(opts opentracing.FinishOptions) {
+	if selfMeasurement.Get() {
+		defer s.tracer.overhead.record(overheadFinish, time.Now())
+	}
+	s.checkOwner()
+	if s.registered && !s.finishRegistered() {
+		return
+	}
+	s.finish(opts)
+}
+
+func (s *span) finish(opts opentracing.FinishOptions) {
 	finishTime := opts.FinishTime
 	if finishTime.IsZero() {
-		finishTime = time.Now()
+		finishTime = s.tracer.now()
 	}
+	s.finishProcessStats()
 	s.mu.Lock()
 	s.mu.duration = finishTime.Sub(s.startTime)
 	s.mu.Unlock()
+	s.notifyFinish()
 	if s.shadowTr != nil {
-		s.shadowSpan.Finish()
-	}
-	if s.netTr != nil {
-		s.netTr.Finish()
+		opts.FinishTime = finishTime
+		s.shadowSpan.FinishWithOptions(opts)
 	}
+	s.execTask.end()
+}
+
+// duration returns the duration of the span, or -1 if it is not finished.
+func (s *span) duration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mu.duration
 }
 
 // Context is part of the opentracing.Span interface.
 //
+// Context is safe for concurrent use, including concurrently with
+// modifications of the span's baggage: the returned context is an immutable
+// snapshot, which is shared by all the calls made until the span's baggage or
+// recording state changes.
+//
 // TODO(andrei, radu): Should this return noopSpanContext for a Recordable span
 // that's not currently recording? That might save work and allocations when
 // creating child spans.
 func (s *span) Context() opentracing.SpanContext {
+	if s.measureOnly && IsBlackHoleSpan(s) {
+		return noopSpanContext{}
+	}
+	if sc, _ := s.ctx.Load().(*spanContext); sc != nil {
+		return sc
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if sc, _ := s.ctx.Load().(*spanContext); sc != nil {
+		return sc
+	}
 	baggageCopy := make(map[string]string, len(s.mu.Baggage))
 	for k, v := range s.mu.Baggage {
 		baggageCopy[k] = v
@@ -289,16 +597,31 @@ func (s *span) Context() opentracing.SpanContext {
 		sc.shadowTr = s.shadowTr
 		sc.shadowCtx = s.shadowSpan.Context()
 	}
+	sc.execTask = s.execTask
+	sc.depth = s.depth
+	sc.cost = s.cost
+	sc.partial = s.partial
 
 	if s.isRecording() {
 		sc.recordingGroup = s.mu.recordingGroup
 		sc.recordingType = s.mu.recordingType
 	}
+	sc.namedRecordings = s.mu.namedRecordings
+	s.ctx.Store(sc)
 	return sc
 }
 
+// resetContextLocked discards the context cached by Context(); it must be
+// called whenever the state captured in the context changes.
+func (s *span) resetContextLocked() {
+	if sc, _ := s.ctx.Load().(*spanContext); sc != nil {
+		s.ctx.Store((*spanContext)(nil))
+	}
+}
+
 // SetOperationName is part of the opentracing.Span interface.
 func (s *span) SetOperationName(operationName string) opentracing.Span {
+	s.checkOwner()
 	if s.shadowTr != nil {
 		s.shadowSpan.SetOperationName(operationName)
 	}
@@ -308,42 +631,71 @@ func (s *span) SetOperationName(operationName string) opentracing.Span {
 
 // SetTag is part of the opentracing.Span interface.
 func (s *span) SetTag(key string, value interface{}) opentracing.Span {
+	s.checkOwner()
 	return s.setTagInner(key, value, false /* locked */)
 }
 
 func (s *span) setTagInner(key string, value interface{}, locked bool) opentracing.Span {
-	if s.shadowTr != nil {
-		s.shadowSpan.SetTag(key, value)
+	s.maybeMarkFailed(key, value)
+	if !locked {
+		s.maybeTriggerRecording(key, value)
+		s.maybeUpgradeVerbosity(key, value)
 	}
-	if s.netTr != nil {
-		s.netTr.LazyPrintf("%s:%v", key, value)
+	value = limitTagValue(value)
+	if !locked {
+		s.mu.Lock()
 	}
-	if s.isRecording() {
-		if !locked {
-			s.mu.Lock()
-		}
+	admitted := s.admitTagLocked(key)
+	if admitted {
 		if s.mu.tags == nil {
-			s.mu.tags = make(opentracing.Tags)
-		}
-		s.mu.tags[key] = value
-		if !locked {
-			s.mu.Unlock()
+			s.mu.tags = make(map[string]spanTag)
 		}
+		recorded := s.mu.tags[key].recorded || s.isRecording() || s.hasNamedRecordings()
+		s.mu.tags[key] = spanTag{value: value, recorded: recorded}
+	}
+	if !locked {
+		s.mu.Unlock()
+	}
+	if !admitted {
+		return s
+	}
+	if s.shadowTr != nil && !s.cost.cutOff() {
+		s.shadowSpan.SetTag(
+			exportedTagKey(s.shadowTr.Typ(), key), s.tracer.shadowTagTransforms.apply(key, value),
+		)
+		s.cost.addExported(int64(len(key)) + valueSize(value))
+	}
+	if s.events != nil {
+		s.events.LazyPrintf("%s:%v", key, value)
 	}
 	return s
 }
 
+// eventsGlobalTags sends the tracer's global tags to the span's events.
+func (s *span) eventsGlobalTags() {
+	for k, v := range s.tracer.globalTags {
+		s.events.LazyPrintf("%s:%v", k, v)
+	}
+}
+
 // LogFields is part of the opentracing.Span interface.
 func (s *span) LogFields(fields ...otlog.Field) {
-	if s.shadowTr != nil {
+	if selfMeasurement.Get() {
+		defer s.tracer.overhead.record(overheadLog, time.Now())
+	}
+	s.checkOwner()
+	// The cost of the trace is only checked for spans that export or record
+	// the fields.
+	if s.shadowTr != nil && !s.cost.cutOff() {
 		s.shadowSpan.LogFields(fields...)
+		s.cost.addExported(fieldsSize(fields))
 	}
-	if s.netTr != nil {
+	if s.events != nil {
 		// TODO(radu): when LightStep supports arbitrary fields, we should make
 		// the formatting of the message consistent with that. Until then we treat
 		// legacy events that just have an "event" key specially.
 		if len(fields) == 1 && fields[0].Key() == "event" {
-			s.netTr.LazyPrintf("%s", fields[0].Value())
+			s.events.LazyPrintf("%s", fields[0].Value())
 		} else {
 			var buf bytes.Buffer
 			for i, f := range fields {
@@ -353,16 +705,19 @@ func (s *span) LogFields(fields ...otlog.Field) {
 				fmt.Fprintf(&buf, "%s:%v", f.Key(), f.Value())
 			}
 
-			s.netTr.LazyPrintf("%s", buf.String())
+			s.events.LazyPrintf("%s", buf.String())
 		}
 	}
-	if s.isRecording() {
+	if s.isVerbose() && !s.cost.cutOff() {
 		s.mu.Lock()
-		if len(s.mu.recordedLogs) < maxLogsPerSpan {
+		if len(s.mu.recordedLogs) < maxLogsPerSpan && s.mu.recordingGroup.budget().consumeLogBudget() {
 			s.mu.recordedLogs = append(s.mu.recordedLogs, opentracing.LogRecord{
-				Timestamp: time.Now(),
+				Timestamp: s.tracer.now(),
 				Fields:    fields,
 			})
+			size := fieldsSize(fields)
+			s.cost.addRecorded(size)
+			s.component.addRecorded(size)
 		}
 		s.mu.Unlock()
 	}
@@ -380,6 +735,7 @@ func (s *span) LogKV(alternatingKeyValues ...interface{}) {
 
 // SetBaggageItem is part of the opentracing.Span interface.
 func (s *span) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	s.checkOwner()
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	return s.setBaggageItemLocked(restrictedKey, value)
@@ -389,12 +745,18 @@ func (s *span) setBaggageItemLocked(restrictedKey, value string) opentracing.Spa
 	if s.mu.Baggage == nil {
 		s.mu.Baggage = make(map[string]string)
 	}
+	if old, ok := s.mu.Baggage[restrictedKey]; ok {
+		s.recordBaggageChangeLocked(restrictedKey, &old, &value)
+	} else {
+		s.recordBaggageChangeLocked(restrictedKey, nil, &value)
+	}
 	s.mu.Baggage[restrictedKey] = value
+	s.resetContextLocked()
 
 	if s.shadowTr != nil {
 		s.shadowSpan.SetBaggageItem(restrictedKey, value)
 	}
-	// Also set a tag so it shows up in the Lightstep UI or x/net/trace.
+	// Also set a tag so it shows up in the Lightstep UI or the event sink.
 	s.setTagInner(restrictedKey, value, true /* locked */)
 	return s
 }
@@ -406,6 +768,63 @@ func (s *span) BaggageItem(restrictedKey string) string {
 	return s.mu.Baggage[restrictedKey]
 }
 
+// DeleteBaggageItem removes a baggage item from the span. Spans created from
+// the span's context from now on (locally or on other nodes) don't inherit the
+// item; spans that were already created are not affected.
+func (s *span) DeleteBaggageItem(restrictedKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleteBaggageItemLocked(restrictedKey)
+}
+
+func (s *span) deleteBaggageItemLocked(restrictedKey string) {
+	old, ok := s.mu.Baggage[restrictedKey]
+	if !ok {
+		return
+	}
+	s.recordBaggageChangeLocked(restrictedKey, &old, nil)
+	delete(s.mu.Baggage, restrictedKey)
+	s.resetContextLocked()
+	if s.shadowTr != nil {
+		// opentracing has no way to remove baggage; an empty value is the closest
+		// we can get.
+		s.shadowSpan.SetBaggageItem(restrictedKey, "")
+	}
+}
+
+// ForEachBaggageItem calls fn for each baggage item of the span, until fn
+// returns false. fn is called on a snapshot of the baggage, so it is allowed
+// to modify the span's baggage.
+func (s *span) ForEachBaggageItem(fn func(k, v string) bool) {
+	s.mu.Lock()
+	baggage := make(map[string]string, len(s.mu.Baggage))
+	for k, v := range s.mu.Baggage {
+		baggage[k] = v
+	}
+	s.mu.Unlock()
+	for k, v := range baggage {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
+// DeleteBaggageItem removes a baggage item from the given span, so that it
+// doesn't propagate to spans created from now on.
+func DeleteBaggageItem(os opentracing.Span, key string) {
+	if sp, ok := os.(*span); ok {
+		sp.DeleteBaggageItem(key)
+	}
+}
+
+// ForEachBaggageItem calls fn for each baggage item of the given span, until fn
+// returns false.
+func ForEachBaggageItem(os opentracing.Span, fn func(k, v string) bool) {
+	if sp, ok := os.(*span); ok {
+		sp.ForEachBaggageItem(fn)
+	}
+}
+
 // Tracer is part of the opentracing.Span interface.
 func (s *span) Tracer() opentracing.Tracer {
 	return s.tracer
@@ -438,73 +857,202 @@ type spanGroup struct {
 	// remoteSpans stores spans obtained from another host that we want to associate
 	// with the record for this group.
 	remoteSpans []RecordedSpan
+	// dropped is the number of spans that were not created because of the span
+	// limits; see exceedsSpanLimits. Accessed atomically.
+	dropped int64
+	// The verbose log budget of the recording; see LogBudget.
+	logBudget
+	// requesterSchema is the recording schema of the node that started the
+	// snowball trace, for groups that were started because of a remote snowball
+	// trace; zero otherwise. See ForRequester.
+	requesterSchema RecordingSchemaVersion
+	// structural is set for named recordings with StructuralFidelity; the log
+	// messages of the spans are left out of the recording.
+	structural bool
+	// released is set by release; spans are no longer added to the group.
+	released bool
+}
+
+// budget returns the log budget of the group. The receiver can be nil.
+func (ss *spanGroup) budget() *logBudget {
+	if ss == nil {
+		return nil
+	}
+	return &ss.logBudget
 }
 
 func (ss *spanGroup) addSpan(s *span) {
 	ss.Lock()
-	ss.spans = append(ss.spans, s)
+	if !ss.released {
+		ss.spans = append(ss.spans, s)
+	}
+	ss.Unlock()
+}
+
+// release stops the recording on all the spans of the group and drops the
+// recorded data; see StopRecordingAndGet.
+func (ss *spanGroup) release() {
+	ss.Lock()
+	spans := ss.spans
+	ss.spans = nil
+	ss.remoteSpans = nil
+	ss.released = true
 	ss.Unlock()
+
+	// The spans are locked after the group is unlocked, since child spans are
+	// added to the group while their parent is locked.
+	for _, s := range spans {
+		s.mu.Lock()
+		if s.mu.recordingGroup == ss {
+			s.disableRecordingLocked()
+			// Named recordings share the recorded data.
+			if s.mu.namedRecordings == nil {
+				s.mu.recordedLogs = nil
+				for k, t := range s.mu.tags {
+					s.mu.tags[k] = spanTag{value: t.value}
+				}
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// recording returns the group's recording, processed according to the
+// options; see GetRecording.
+func (ss *spanGroup) recording(opts []RecordingOption) Recording {
+	var o recordingOptions
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+	rec := ss.getSpans()
+	if o.subtreeOf != 0 {
+		rec = rec.subtree(o.subtreeOf)
+	}
+	if o.granularity > 0 {
+		rec.RoundTimestamps(o.granularity)
+	}
+	if o.forRequester && ss.requesterSchema != 0 {
+		if ss.requesterSchema >= RecordingSchemaV6 {
+			rec.EncodeStartOffsets()
+		}
+		rec = DowngradeRecording(rec, ss.requesterSchema)
+	}
+	return rec
 }
 
 // getSpans returns all the local and remote spans accumulated in this group.
 // The first result is the first local span - i.e. the span originally passed to
 // StartRecording().
-func (ss *spanGroup) getSpans() []RecordedSpan {
+func (ss *spanGroup) getSpans() Recording {
 	ss.Lock()
 	spans := ss.spans
 	remoteSpans := ss.remoteSpans
 	ss.Unlock()
 
 	result := make([]RecordedSpan, 0, len(spans)+len(remoteSpans))
-	for _, s := range spans {
-		s.mu.Lock()
-		rs := RecordedSpan{
-			TraceID:      s.TraceID,
-			SpanID:       s.SpanID,
-			ParentSpanID: s.parentSpanID,
-			Operation:    s.operation,
-			StartTime:    s.startTime,
-			Duration:     s.mu.duration,
-		}
-		switch rs.Duration {
-		case -1:
-			// -1 indicates an unfinished span.
-			// TODO(radu): depending how recording of in-progress spans is used, we
-			// may want to set this to (Now - StartTime).
-			rs.Duration = 0
-		case 0:
-			// 0 is a special value for unfinished spans. Change to 1ns.
-			rs.Duration = time.Nanosecond
+	if len(spans) > 0 {
+		// The spans share a clock reading, which AlignRecording uses to tell that
+		// they were collected together.
+		now := spans[0].tracer.now()
+		for _, s := range spans {
+			result = append(result, s.getRecordedSpan(now, ss.structural))
 		}
+	}
+	result = append(result, remoteSpans...)
+	ss.annotateDropped(result)
+	return result
+}
 
-		if len(s.mu.Baggage) > 0 {
-			rs.Baggage = make(map[string]string)
-			for k, v := range s.mu.Baggage {
-				rs.Baggage[k] = v
-			}
+// getRecordedSpan returns the recorded data of the span. If structural is set,
+// the log messages are left out.
+func (s *span) getRecordedSpan(now time.Time, structural bool) RecordedSpan {
+	s.mu.Lock()
+	rs := RecordedSpan{
+		TraceID:       s.TraceID,
+		SpanID:        s.SpanID,
+		ParentSpanID:  s.parentSpanID,
+		Operation:     s.operation,
+		StartTime:     s.startTime,
+		Duration:      s.mu.duration,
+		ClockReading:  now,
+		NodeID:        atomic.LoadInt32(&s.tracer.nodeID),
+		GoroutineID:   s.goroutine,
+		ParallelGroup: s.parallelGroup,
+		StartOptions:  s.startOptions(),
+	}
+	switch rs.Duration {
+	case -1:
+		// -1 indicates an unfinished span.
+		// TODO(radu): depending how recording of in-progress spans is used, we
+		// may want to set this to (Now - StartTime).
+		rs.Duration = 0
+	case 0:
+		// 0 is a special value for unfinished spans. Change to 1ns.
+		rs.Duration = time.Nanosecond
+	}
+
+	if len(s.mu.Baggage) > 0 {
+		rs.Baggage = make(map[string]string)
+		for k, v := range s.mu.Baggage {
+			rs.Baggage[k] = v
+		}
+	}
+	for k, t := range s.mu.tags {
+		if !t.recorded {
+			continue
 		}
-		if len(s.mu.tags) > 0 {
+		if rs.Tags == nil {
 			rs.Tags = make(map[string]string)
-			for k, v := range s.mu.tags {
-				// We encode the tag values as strings.
-				rs.Tags[k] = fmt.Sprint(v)
-			}
 		}
-		rs.Logs = make([]RecordedSpan_LogRecord, len(s.mu.recordedLogs))
-		for i, r := range s.mu.recordedLogs {
-			rs.Logs[i].Time = r.Timestamp
-			rs.Logs[i].Fields = make([]RecordedSpan_LogRecord_Field, len(r.Fields))
-			for j, f := range r.Fields {
-				rs.Logs[i].Fields[j] = RecordedSpan_LogRecord_Field{
-					Key:   f.Key(),
-					Value: fmt.Sprint(f.Value()),
-				}
+		// We encode the tag values as strings.
+		rs.Tags[k] = fmt.Sprint(t.value)
+	}
+	if s.mu.tagsDropped > 0 {
+		if rs.Tags == nil {
+			rs.Tags = make(map[string]string)
+		}
+		rs.Tags[TagTagsDropped] = strconv.Itoa(s.mu.tagsDropped)
+	}
+	if n := s.partial.droppedChildren(); n > 0 {
+		if rs.Tags == nil {
+			rs.Tags = make(map[string]string)
+		}
+		rs.Tags[TagChildrenDropped] = strconv.FormatInt(n, 10)
+	}
+	for k, v := range s.tracer.globalTags {
+		if _, ok := rs.Tags[k]; !ok {
+			if rs.Tags == nil {
+				rs.Tags = make(map[string]string)
 			}
+			rs.Tags[k] = fmt.Sprint(v)
+		}
+	}
+	if s.link.TraceID != 0 {
+		// The link tags are set when the span is created, which is generally
+		// before recording starts.
+		if rs.Tags == nil {
+			rs.Tags = make(map[string]string)
 		}
+		rs.Tags[TagLinkTraceID] = strconv.FormatUint(s.link.TraceID, 16)
+		rs.Tags[TagLinkSpanID] = strconv.FormatUint(s.link.SpanID, 16)
+	}
+	if structural {
 		s.mu.Unlock()
-		result = append(result, rs)
+		return rs
+	}
+	rs.Logs = make([]RecordedSpan_LogRecord, len(s.mu.recordedLogs))
+	for i, r := range s.mu.recordedLogs {
+		rs.Logs[i].Time = r.Timestamp
+		rs.Logs[i].Fields = make([]RecordedSpan_LogRecord_Field, len(r.Fields))
+		for j, f := range r.Fields {
+			rs.Logs[i].Fields[j] = RecordedSpan_LogRecord_Field{
+				Key:   f.Key(),
+				Value: fmt.Sprint(f.Value()),
+			}
+		}
 	}
-	return append(result, remoteSpans...)
+	s.mu.Unlock()
+	return rs
 }
 
 type noopSpanContext struct{}
@@ -515,12 +1063,15 @@ func (n noopSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {}
 
 type noopSpan struct {
 	tracer *Tracer
+	// passThrough is the external context continued by the span, in the
+	// pass-through mode of ExtractExternal; nil otherwise. It is returned by
+	// Context(), so that the external trace is propagated even though nothing
+	// is recorded.
+	passThrough *spanContext
 }
 
 var _ opentracing.Span = &noopSpan{}
 
-func (n *noopSpan) Context() opentracing.SpanContext                       { return noopSpanContext{} }
-func (n *noopSpan) BaggageItem(key string) string                          { return "" }
 func (n *noopSpan) SetTag(key string, value interface{}) opentracing.Span  { return n }
 func (n *noopSpan) Finish()                                                {}
 func (n *noopSpan) FinishWithOptions(opts opentracing.FinishOptions)       {}
@@ -532,6 +1083,20 @@ func (n *noopSpan) LogEvent(event string)                                  {}
 func (n *noopSpan) LogEventWithPayload(event string, payload interface{})  {}
 func (n *noopSpan) Log(data opentracing.LogData)                           {}
 
+func (n *noopSpan) Context() opentracing.SpanContext {
+	if n.passThrough != nil {
+		return n.passThrough
+	}
+	return noopSpanContext{}
+}
+
+func (n *noopSpan) BaggageItem(key string) string {
+	if n.passThrough != nil {
+		return n.passThrough.Baggage[key]
+	}
+	return ""
+}
+
 func (n *noopSpan) SetBaggageItem(key, val string) opentracing.Span {
 	if key == Snowball {
 		panic("attempting to set Snowball on a noop span; use the Force option to StartSpan")
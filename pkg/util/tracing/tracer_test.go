@@ -17,10 +17,17 @@
 package tracing
 
 import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	lightstep "github.com/lightstep/lightstep-tracer-go"
 	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
 )
 
 func TestTracerRecording(t *testing.T) {
@@ -253,6 +260,287 @@ func TestTracerInjectExtract(t *testing.T) {
 	}
 }
 
+func TestChildSpanRemote(t *testing.T) {
+	tr := NewTracer()
+	tr2 := NewTracer()
+
+	// Without a span in the context, there is nothing to propagate.
+	ctx, sp, blob, err := ChildSpanRemote(context.Background(), "flow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sp != nil || blob != nil {
+		t.Fatalf("expected no span and no blob, got %v, %v", sp, blob)
+	}
+
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SnowballRecording)
+	ctx = opentracing.ContextWithSpan(context.Background(), root)
+
+	_, sp, blob, err = ChildSpanRemote(ctx, "setup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, remoteSp, err := ImportSpanMeta(context.Background(), tr2, blob, "flow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remoteSp.LogKV("x", 1)
+
+	sc := sp.Context().(*spanContext)
+	remoteSc := remoteSp.Context().(*spanContext)
+	if sc.TraceID != remoteSc.TraceID {
+		t.Errorf("TraceID doesn't match: %d vs %d", sc.TraceID, remoteSc.TraceID)
+	}
+	if parentID := remoteSp.(*span).parentSpanID; parentID != sc.SpanID {
+		t.Errorf("expected remote span to be a child of %d, got parent %d", sc.SpanID, parentID)
+	}
+	if err := TestingCheckRecordedSpans(GetRecording(remoteSp), `
+		span flow:
+			tags: sb=1
+			x: 1
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := ImportSpanMeta(context.Background(), tr2, blob[:len(blob)-1], "flow"); err == nil {
+		t.Error("expected error for truncated blob")
+	}
+}
+
+func TestGlobalTags(t *testing.T) {
+	tr := NewTracerWithOptions(TracerOptions{
+		GlobalTags: opentracing.Tags{"node": 1, "version": "v1.1"},
+	})
+
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SingleNodeRecording)
+	child := StartChildSpan("child", root, false /* separateRecording */)
+	child.SetTag("node", 2)
+	child.Finish()
+	root.Finish()
+
+	if err := TestingCheckRecordedSpans(GetRecording(root), `
+		span root:
+			tags: node=1 version=v1.1
+		span child:
+			tags: node=2 version=v1.1
+	`); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetVerbose(t *testing.T) {
+	tr := NewTracer()
+	tr.(*Tracer).SetForceRealSpans(true)
+
+	sp := tr.StartSpan("a")
+	if IsVerbose(sp) {
+		t.Fatal("new span should not be verbose")
+	}
+	sp.LogKV("ignored", 1)
+	child1 := StartChildSpan("child1", sp, false /* separateRecording */)
+
+	SetVerbose(sp, true)
+	if !IsVerbose(sp) {
+		t.Fatal("expected verbose span")
+	}
+	sp.LogKV("x", 1)
+	child1.LogKV("ignored", 2)
+
+	SetVerbose(sp, false)
+	sp.LogKV("ignored", 3)
+	child2 := StartChildSpan("child2", sp, false /* separateRecording */)
+	child2.LogKV("y", 2)
+
+	SetVerbose(sp, true)
+	sp.LogKV("z", 3)
+
+	if err := TestingCheckRecordedSpans(GetRecording(sp), `
+		span a:
+			x: 1
+			z: 3
+		span child2:
+			y: 2
+	`); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetRecordingSubtree(t *testing.T) {
+	tr := NewTracer()
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SingleNodeRecording)
+	planning := StartChildSpan("planning", root, false /* separateRecording */)
+	opt := StartChildSpan("optimize", planning, false /* separateRecording */)
+	exec := StartChildSpan("exec", root, false /* separateRecording */)
+	opt.LogKV("x", 1)
+	exec.LogKV("y", 2)
+
+	// A span from another node, child of the optimizer span.
+	remote := RecordedSpan{
+		TraceID:      root.(*span).TraceID,
+		SpanID:       12345,
+		ParentSpanID: opt.(*span).SpanID,
+		Operation:    "remote",
+		Duration:     time.Millisecond,
+	}
+	if err := ImportRemoteSpans(root, []RecordedSpan{remote}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := TestingCheckRecordedSpans(GetRecording(root, WithSubtreeOf(planning.(*span).SpanID)), `
+		span planning:
+		span optimize:
+			x: 1
+		span remote:
+	`); err != nil {
+		t.Fatal(err)
+	}
+	if rec := GetRecording(root, WithSubtreeOf(1)); len(rec) != 0 {
+		t.Errorf("expected empty recording, got %v", rec)
+	}
+	if rec := GetRecording(root); len(rec) != 5 {
+		t.Errorf("expected full recording with 5 spans, got %d", len(rec))
+	}
+}
+
+func TestCreationStacks(t *testing.T) {
+	tr := NewTracerWithOptions(TracerOptions{CreationStacks: true})
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SingleNodeRecording)
+	child := StartChildSpan("child", root, false /* separateRecording */)
+
+	rec := GetRecording(root)
+	if len(rec) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(rec))
+	}
+	// The root span's tag was set before recording started.
+	if stack, ok := rec[0].Tags[TagCreationStack]; ok {
+		t.Errorf("unexpected creation stack on root span: %s", stack)
+	}
+	// The stacks start at the caller.
+	const caller = "github.com/cockroachdb/cockroach/pkg/util/tracing.TestCreationStacks tracer_test.go:"
+	if stack := rec[1].Tags[TagCreationStack]; !strings.HasPrefix(stack, caller) {
+		t.Errorf("unexpected creation stack: %s", stack)
+	}
+	for _, sp := range []opentracing.Span{root, tr.(*Tracer).Start("start", WithForceReal())} {
+		if stack := fmt.Sprint(GetSpanTags(sp)[TagCreationStack]); !strings.HasPrefix(stack, caller) {
+			t.Errorf("unexpected creation stack: %s", stack)
+		}
+	}
+	child.Finish()
+
+	// Without the option, there is no stack.
+	tr = NewTracer()
+	root = tr.StartSpan("root", Recordable)
+	StartRecording(root, SingleNodeRecording)
+	StartChildSpan("child", root, false /* separateRecording */).Finish()
+	for _, sp := range GetRecording(root) {
+		if stack, ok := sp.Tags[TagCreationStack]; ok {
+			t.Errorf("unexpected creation stack: %s", stack)
+		}
+	}
+}
+
+func TestCarrierSpans(t *testing.T) {
+	tr := NewTracer()
+	tr2 := NewTracer()
+
+	// An incoming request with trace information (e.g. from an external tracer).
+	carrier := opentracing.TextMapCarrier{
+		fieldNameTraceID: "abc",
+		fieldNameSpanID:  "123",
+	}
+	wireContext, err := tr.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sp := tr.StartSpan("a", opentracing.ChildOf(wireContext)); !IsBlackHoleSpan(sp) || isCarrierSpan(sp) {
+		t.Fatalf("expected noop span, got %+v", sp)
+	}
+
+	defer settings.TestingSetBool(&propagateTraceIDs, true)()
+
+	// Without a parent, we still get a noop span.
+	if sp := tr.StartSpan("a"); isCarrierSpan(sp) {
+		t.Fatal("unexpected carrier span")
+	}
+
+	sp := tr.StartSpan("a", opentracing.ChildOf(wireContext))
+	if !isCarrierSpan(sp) || !IsBlackHoleSpan(sp) {
+		t.Fatalf("expected carrier span, got %+v", sp)
+	}
+	sp.LogKV("x", 1)
+	if rec := GetRecording(sp); rec != nil {
+		t.Errorf("unexpected recording: %v", rec)
+	}
+
+	// The trace ID is propagated to children and through Inject/Extract.
+	ctx := opentracing.ContextWithSpan(context.Background(), sp)
+	_, child := ChildSpan(ctx, "child")
+	if !isCarrierSpan(child) {
+		t.Fatalf("expected carrier span, got %+v", child)
+	}
+	carrier = make(opentracing.TextMapCarrier)
+	if err := tr.Inject(child.Context(), opentracing.TextMap, carrier); err != nil {
+		t.Fatal(err)
+	}
+	wireContext, err = tr2.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := tr2.StartSpan("remote", opentracing.ChildOf(wireContext))
+	if traceID := remote.(*span).TraceID; traceID != 0xabc {
+		t.Errorf("expected trace ID abc, got %x", traceID)
+	}
+	if parentID := remote.(*span).parentSpanID; parentID != child.(*span).SpanID {
+		t.Errorf("expected parent %d, got %d", child.(*span).SpanID, parentID)
+	}
+}
+
+type flushTestManager struct {
+	flushed chan struct{}
+	block   chan struct{}
+}
+
+func (m *flushTestManager) Name() string { return "test" }
+
+func (m *flushTestManager) Flush(tr opentracing.Tracer) {
+	<-m.block
+	m.flushed <- struct{}{}
+}
+
+func (m *flushTestManager) Close(tr opentracing.Tracer) {}
+
+func TestTracerFlush(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	defer tr.Close()
+
+	// Without a shadow tracer, there is nothing to flush.
+	if err := tr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &flushTestManager{flushed: make(chan struct{}, 2), block: make(chan struct{})}
+	tr.setShadowTracer(m, opentracing.NoopTracer{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := tr.Flush(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	close(m.block)
+	if err := tr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Both flushes eventually complete.
+	<-m.flushed
+	<-m.flushed
+}
+
 func TestLightstepContext(t *testing.T) {
 	tr := NewTracer()
 	lsTr := lightstep.NewTracer(lightstep.Options{
@@ -299,3 +587,224 @@ func TestLightstepContext(t *testing.T) {
 		}
 	}
 }
+
+func TestDeleteBaggageItem(t *testing.T) {
+	tr := NewTracer()
+	tr.(*Tracer).SetForceRealSpans(true)
+	s := tr.StartSpan("a")
+	s.SetBaggageItem("hint", "x")
+	s.SetBaggageItem("keep", "y")
+
+	carrier := make(opentracing.HTTPHeadersCarrier)
+	if err := tr.Inject(s.Context(), opentracing.HTTPHeaders, carrier); err != nil {
+		t.Fatal(err)
+	}
+	wireContext, err := tr.Extract(opentracing.HTTPHeaders, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := tr.StartSpan("remote", opentracing.ChildOf(wireContext))
+	if v := remote.BaggageItem("hint"); v != "x" {
+		t.Fatalf("expected hint baggage on remote span, got %q", v)
+	}
+
+	// The hint is only meant for the first hop.
+	DeleteBaggageItem(remote, "hint")
+	child := tr.StartSpan("child", opentracing.ChildOf(remote.Context()))
+	baggage := make(map[string]string)
+	ForEachBaggageItem(child, func(k, v string) bool {
+		baggage[k] = v
+		return true
+	})
+	if len(baggage) != 1 || baggage["keep"] != "y" {
+		t.Errorf("expected only the keep baggage item, got %v", baggage)
+	}
+	// The span from which the item was removed is not affected.
+	if v := s.BaggageItem("hint"); v != "x" {
+		t.Errorf("expected hint baggage on original span, got %q", v)
+	}
+}
+
+func TestStopRecordingAndGet(t *testing.T) {
+	tr := NewTracer()
+	root := tr.StartSpan("root", Recordable)
+	StartRecording(root, SnowballRecording)
+	root.LogKV("x", 1)
+	child := tr.StartSpan("child", opentracing.ChildOf(root.Context()))
+	child.LogKV("x", 2)
+	if err := ImportRemoteSpans(root, []RecordedSpan{{TraceID: 1, SpanID: 2, Operation: "remote"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := TestingCheckRecordedSpans(StopRecordingAndGet(child), `
+		span root:
+			tags: sb=1
+			x: 1
+		span child:
+			tags: sb=1
+			x: 2
+		span remote:
+	`); err != nil {
+		t.Fatal(err)
+	}
+	// Recording is stopped on all the spans, and the data is gone.
+	for _, sp := range []opentracing.Span{root, child} {
+		if GetRecording(sp) != nil || IsVerbose(sp) || sp.BaggageItem(Snowball) != "" {
+			t.Errorf("expected recording to be stopped on %s", sp.(*span).operation)
+		}
+		if n := len(sp.(*span).mu.recordedLogs); n != 0 {
+			t.Errorf("expected recorded logs to be released, got %d", n)
+		}
+	}
+	if StopRecordingAndGet(root) != nil {
+		t.Error("expected no recording")
+	}
+	child.Finish()
+	root.Finish()
+}
+
+func TestSpanContextCache(t *testing.T) {
+	tr := NewTracer()
+	s := tr.StartSpan("a", Recordable)
+	defer s.Finish()
+	s.SetBaggageItem("k", "v")
+
+	sc := s.Context()
+	if s.Context() != sc {
+		t.Fatal("expected the context to be cached")
+	}
+	if n := testing.AllocsPerRun(100, func() { _ = s.Context() }); n != 0 {
+		t.Errorf("expected no allocations, got %.1f", n)
+	}
+
+	// Baggage changes produce a new context; the old one is unaffected.
+	s.SetBaggageItem("k2", "v2")
+	sc2 := s.Context().(*spanContext)
+	if sc2 == sc || sc2.Baggage["k2"] != "v2" {
+		t.Errorf("expected a new context with the k2 item, got %+v", sc2)
+	}
+	if _, ok := sc.(*spanContext).Baggage["k2"]; ok {
+		t.Error("cached context was modified")
+	}
+
+	// So do recording changes.
+	StartRecording(s, SnowballRecording)
+	if sc3 := s.Context().(*spanContext); sc3.recordingGroup == nil || sc3.Baggage[Snowball] == "" {
+		t.Errorf("expected a recording context, got %+v", sc3)
+	}
+	StopRecording(s)
+	if sc4 := s.Context().(*spanContext); sc4.recordingGroup != nil || sc4.Baggage[Snowball] != "" {
+		t.Errorf("expected a non-recording context, got %+v", sc4)
+	}
+}
+
+func TestDetachedTrace(t *testing.T) {
+	tr := NewTracer()
+	parent := tr.StartSpan("parent", Recordable)
+	StartRecording(parent, SnowballRecording)
+	parent.SetBaggageItem("k", "v")
+
+	child := tr.StartSpan(
+		"detached", opentracing.FollowsFrom(parent.Context()), WithDetachedTrace(), Recordable,
+	)
+	cs, ps := child.(*span), parent.(*span)
+	if cs.TraceID == ps.TraceID {
+		t.Error("expected a new trace ID")
+	}
+	if cs.parentSpanID != 0 {
+		t.Errorf("expected no parent, got %d", cs.parentSpanID)
+	}
+	if cs.BaggageItem("k") != "" || cs.isRecording() {
+		t.Error("detached span should not inherit baggage or recording")
+	}
+	child.Finish()
+	parent.Finish()
+
+	if err := TestingCheckRecordedSpans(GetRecording(parent), `
+		span parent:
+			tags: k=v sb=1
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	StartRecording(child, SingleNodeRecording)
+	rec := GetRecording(child)
+	if len(rec) != 1 {
+		t.Fatalf("expected one span, got %d", len(rec))
+	}
+	if exp := strconv.FormatUint(ps.SpanID, 16); rec[0].Tags[TagLinkSpanID] != exp {
+		t.Errorf("expected link to span %s, got %v", exp, rec[0].Tags)
+	}
+	if exp := strconv.FormatUint(ps.TraceID, 16); rec[0].Tags[TagLinkTraceID] != exp {
+		t.Errorf("expected link to trace %s, got %v", exp, rec[0].Tags)
+	}
+}
+
+func TestRecordedNodeAndGoroutineIDs(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	sp := tr.StartSpan("a", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	if rec := GetRecording(sp); rec[0].NodeID != 0 {
+		t.Fatalf("unexpected node ID %d", rec[0].NodeID)
+	}
+	tr.SetNodeID(5)
+	done := make(chan struct{})
+	go func() {
+		StartChildSpan("b", sp, false /* separateRecording */).Finish()
+		close(done)
+	}()
+	<-done
+	sp.Finish()
+
+	rec := GetRecording(sp)
+	for _, rs := range rec {
+		if rs.NodeID != 5 {
+			t.Errorf("%s: expected node ID 5, got %d", rs.Operation, rs.NodeID)
+		}
+	}
+	if rec[0].GoroutineID == 0 || rec[0].GoroutineID == rec[1].GoroutineID {
+		t.Errorf("unexpected goroutine IDs %d, %d", rec[0].GoroutineID, rec[1].GoroutineID)
+	}
+
+	// The new fields survive serialization.
+	data, err := rec[1].Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rs RecordedSpan
+	if err := rs.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+	if rs.NodeID != 5 || rs.GoroutineID != rec[1].GoroutineID {
+		t.Errorf("unexpected span after round trip: %+v", rs)
+	}
+}
+
+func TestGetSpanTags(t *testing.T) {
+	tr := NewTracer()
+	if tags := GetSpanTags(tr.StartSpan("noop")); tags != nil {
+		t.Fatalf("unexpected tags %v", tags)
+	}
+
+	sp := tr.StartSpan("a", Recordable, opentracing.Tags{"start": 1})
+	sp.SetTag("before", 2)
+	StartRecording(sp, SingleNodeRecording)
+	sp.SetTag("after", 3)
+	tags := GetSpanTags(sp)
+	if exp := map[string]interface{}{"start": 1, "before": 2, "after": 3}; !reflect.DeepEqual(tags, exp) {
+		t.Fatalf("expected %v, got %v", exp, tags)
+	}
+	// The result is a snapshot.
+	tags["after"] = 4
+	if !HasSpanTag(sp, "before") || HasSpanTag(sp, "other") || GetSpanTags(sp)["after"] != 3 {
+		t.Fatalf("unexpected tags %v", GetSpanTags(sp))
+	}
+	// Recordings only contain the tags set while recording.
+	sp.Finish()
+	if err := TestingCheckRecordedSpans(GetRecording(sp), `
+		span a:
+			tags: after=3
+	`); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// RecordingTrigger is a rule that starts a snowball recording on a span when a
+// tag is set on it.
+type RecordingTrigger struct {
+	// Tag is the key of the tag that triggers the rule.
+	Tag string
+	// Matches returns true if the tag value should trigger recording.
+	Matches func(value interface{}) bool
+}
+
+// TagAtLeast returns a RecordingTrigger that fires when the given tag is set to
+// an integer value greater than or equal to threshold.
+func TagAtLeast(tag string, threshold int64) RecordingTrigger {
+	return RecordingTrigger{
+		Tag: tag,
+		Matches: func(value interface{}) bool {
+			switch v := value.(type) {
+			case int:
+				return int64(v) >= threshold
+			case int32:
+				return int64(v) >= threshold
+			case int64:
+				return v >= threshold
+			case uint32:
+				return int64(v) >= threshold
+			case uint64:
+				return threshold <= 0 || v >= uint64(threshold)
+			default:
+				return false
+			}
+		},
+	}
+}
+
+type recordingTriggers struct {
+	// num is the number of triggers, accessed atomically to avoid locking in
+	// the common case where there are none.
+	num int32
+	mu  struct {
+		syncutil.Mutex
+		triggers []RecordingTrigger
+	}
+}
+
+// AddRecordingTrigger registers a rule that starts recording on spans created
+// by this tracer.
+func (t *Tracer) AddRecordingTrigger(trigger RecordingTrigger) {
+	rt := &t.recordingTriggers
+	rt.mu.Lock()
+	rt.mu.triggers = append(rt.mu.triggers, trigger)
+	atomic.StoreInt32(&rt.num, int32(len(rt.mu.triggers)))
+	rt.mu.Unlock()
+}
+
+// match returns the trigger that matches the given tag, if any.
+func (rt *recordingTriggers) match(key string, value interface{}) (RecordingTrigger, bool) {
+	if atomic.LoadInt32(&rt.num) == 0 {
+		return RecordingTrigger{}, false
+	}
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	for _, trigger := range rt.mu.triggers {
+		if trigger.Tag == key && trigger.Matches(value) {
+			return trigger, true
+		}
+	}
+	return RecordingTrigger{}, false
+}
+
+// maybeTriggerRecording starts recording if the span is not already recording
+// and a trigger matches the tag that is being set.
+func (s *span) maybeTriggerRecording(key string, value interface{}) {
+	if s.isRecording() {
+		return
+	}
+	if _, ok := s.tracer.recordingTriggers.match(key, value); !ok {
+		return
+	}
+	s.enableRecording(new(spanGroup), SnowballRecording)
+	s.LogKV("event", fmt.Sprintf("recording triggered by tag %s=%v", key, value))
+}
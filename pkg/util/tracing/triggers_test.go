@@ -0,0 +1,59 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import "testing"
+
+func TestRecordingTriggers(t *testing.T) {
+	tr := NewTracer().(*Tracer)
+	tr.SetForceRealSpans(true)
+	tr.AddRecordingTrigger(TagAtLeast("restart_count", 3))
+
+	sp := tr.StartSpan("txn")
+	sp.LogKV("ignored", 1)
+	sp.SetTag("restart_count", 1)
+	sp.SetTag("restart_count", "lots")
+	if IsVerbose(sp) {
+		t.Fatal("unexpected recording")
+	}
+	sp.SetTag("restart_count", 3)
+	if !IsVerbose(sp) {
+		t.Fatal("expected recording to be triggered")
+	}
+	child := StartChildSpan("child", sp, false /* separateRecording */)
+	child.LogKV("x", 1)
+
+	if err := TestingCheckRecordedSpans(GetRecording(sp), `
+		span txn:
+			tags: restart_count=3 sb=1
+			event: recording triggered by tag restart_count=3
+		span child:
+			tags: sb=1
+			x: 1
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		value interface{}
+		match bool
+	}{
+		{2, false}, {int64(3), true}, {uint64(4), true}, {int32(2), false}, {3.5, false},
+	} {
+		if m := TagAtLeast("k", 3).Matches(tc.value); m != tc.match {
+			t.Errorf("%v: expected %t, got %t", tc.value, tc.match, m)
+		}
+	}
+}
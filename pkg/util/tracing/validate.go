@@ -0,0 +1,111 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"strings"
+
+	opentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// Limits enforced by ValidateSpanMeta.
+const (
+	// maxBaggageItems is the maximum number of baggage items in a valid span
+	// context.
+	maxBaggageItems = 64
+	// maxBaggageBytes is the maximum total size of the keys and values of the
+	// baggage items in a valid span context.
+	maxBaggageBytes = 8 << 10
+)
+
+// knownShadowTypes are the types of shadow tracers we know how to use. The
+// shadow tracers used in tests are added when they are installed; see
+// addKnownShadowType.
+var knownShadowTypes = struct {
+	syncutil.RWMutex
+	m map[string]bool
+}{m: map[string]bool{lightStepManager{}.Name(): true}}
+
+// addKnownShadowType adds a shadow tracer type to knownShadowTypes.
+func addKnownShadowType(name string) {
+	knownShadowTypes.Lock()
+	defer knownShadowTypes.Unlock()
+	knownShadowTypes.m[strings.ToLower(name)] = true
+}
+
+func isKnownShadowType(name string) bool {
+	knownShadowTypes.RLock()
+	defer knownShadowTypes.RUnlock()
+	return knownShadowTypes.m[strings.ToLower(name)]
+}
+
+// InvalidSpanMetaError is returned by ValidateSpanMeta.
+type InvalidSpanMetaError struct {
+	// Field is the part of the span context that is invalid (e.g. "span ID",
+	// "baggage").
+	Field string
+	// Reason describes the problem.
+	Reason string
+}
+
+var _ error = &InvalidSpanMetaError{}
+
+func (e *InvalidSpanMetaError) Error() string {
+	return fmt.Sprintf("invalid span context: %s: %s", e.Field, e.Reason)
+}
+
+// ValidateSpanMeta checks a span context returned by Extract for impossible or
+// abusive values, returning an *InvalidSpanMetaError for the first problem.
+func ValidateSpanMeta(osc opentracing.SpanContext) error {
+	sc, ok := osc.(*spanContext)
+	if !ok {
+		if _, noop := osc.(noopSpanContext); noop || osc == nil {
+			return nil
+		}
+		return &InvalidSpanMetaError{Field: "context", Reason: fmt.Sprintf("unsupported type %T", osc)}
+	}
+	if sc.TraceID == 0 {
+		return &InvalidSpanMetaError{Field: "trace ID", Reason: "zero trace ID with a nonzero span ID"}
+	}
+	if sc.SpanID == 0 {
+		return &InvalidSpanMetaError{Field: "span ID", Reason: "zero span ID with a nonzero trace ID"}
+	}
+	if n := len(sc.Baggage); n > maxBaggageItems {
+		return &InvalidSpanMetaError{
+			Field:  "baggage",
+			Reason: fmt.Sprintf("%d items exceeds the maximum of %d", n, maxBaggageItems),
+		}
+	}
+	var size int
+	for k, v := range sc.Baggage {
+		size += len(k) + len(v)
+	}
+	if size > maxBaggageBytes {
+		return &InvalidSpanMetaError{
+			Field:  "baggage",
+			Reason: fmt.Sprintf("%d bytes exceeds the maximum of %d", size, maxBaggageBytes),
+		}
+	}
+	if sc.shadowType != "" && !isKnownShadowType(sc.shadowType) {
+		return &InvalidSpanMetaError{
+			Field:  "shadow type",
+			Reason: fmt.Sprintf("unknown shadow tracer type %q", sc.shadowType),
+		}
+	}
+	return nil
+}
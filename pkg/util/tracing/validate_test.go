@@ -0,0 +1,93 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestValidateSpanMeta(t *testing.T) {
+	tr := NewTracer()
+
+	manyItems := opentracing.TextMapCarrier{fieldNameTraceID: "1", fieldNameSpanID: "2"}
+	for i := 0; i <= maxBaggageItems; i++ {
+		manyItems[fmt.Sprintf("%sk%d", prefixBaggage, i)] = "v"
+	}
+
+	testCases := []struct {
+		carrier opentracing.TextMapCarrier
+		field   string
+	}{
+		{opentracing.TextMapCarrier{}, ""},
+		{opentracing.TextMapCarrier{fieldNameTraceID: "1", fieldNameSpanID: "2"}, ""},
+		{opentracing.TextMapCarrier{fieldNameTraceID: "1"}, "span ID"},
+		{opentracing.TextMapCarrier{fieldNameSpanID: "2"}, "trace ID"},
+		{manyItems, "baggage"},
+		{
+			opentracing.TextMapCarrier{
+				fieldNameTraceID:      "1",
+				fieldNameSpanID:       "2",
+				prefixBaggage + "big": strings.Repeat("x", maxBaggageBytes),
+			},
+			"baggage",
+		},
+		{
+			opentracing.TextMapCarrier{
+				fieldNameTraceID: "1", fieldNameSpanID: "2", fieldNameShadowType: "LightStep",
+			},
+			"",
+		},
+		{
+			opentracing.TextMapCarrier{
+				fieldNameTraceID: "1", fieldNameSpanID: "2", fieldNameShadowType: "foo",
+			},
+			"shadow type",
+		},
+	}
+	for i, tc := range testCases {
+		sc, err := tr.Extract(opentracing.TextMap, tc.carrier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ValidateSpanMeta(sc)
+		if tc.field == "" {
+			if err != nil {
+				t.Errorf("%d: unexpected error %v", i, err)
+			}
+			continue
+		}
+		if e, ok := err.(*InvalidSpanMetaError); !ok || e.Field != tc.field {
+			t.Errorf("%d: expected error for %s, got %v", i, tc.field, err)
+		}
+	}
+
+	// The shadow tracers used in tests are known once installed.
+	sc, err := tr.Extract(opentracing.TextMap, opentracing.TextMapCarrier{
+		fieldNameTraceID: "1", fieldNameSpanID: "2", fieldNameShadowType: "test-collector",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr2 := NewTracer().(*Tracer)
+	defer tr2.Close()
+	tr2.SetTestCollector(NewTestCollector())
+	if err := ValidateSpanMeta(sc); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sync/atomic"
+	"unsafe"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+)
+
+// VerboseOnErrorBaggage is the baggage item carrying the VerboseOnError policy
+// of a trace. Its value is "1", or verboseOnErrorUpgraded once the trace has
+// been upgraded to verbose recording on the node that sent it.
+const VerboseOnErrorBaggage = "trace-verbose-on-error"
+
+const verboseOnErrorUpgraded = "upgraded"
+
+type verboseOnErrorOption struct{}
+
+// VerboseOnError is a StartSpanOption which upgrades the trace of the new span
+// to snowball recording once any of its spans is marked as failed. The policy
+// travels in baggage (see VerboseOnErrorBaggage).
+var VerboseOnError opentracing.StartSpanOption = verboseOnErrorOption{}
+
+func (verboseOnErrorOption) Apply(*opentracing.StartSpanOptions) {}
+
+// verboseGroup returns the recording group of the spans created after the
+// trace was upgraded to verbose recording, or nil if it wasn't. The receiver
+// can be nil.
+func (c *traceCost) verboseGroup() *spanGroup {
+	if c == nil {
+		return nil
+	}
+	return (*spanGroup)(atomic.LoadPointer(&c.verbose))
+}
+
+// upgradeVerbosity upgrades the trace to verbose recording. Returns the
+// recording group of the verbose spans, and whether this call upgraded the
+// trace.
+func (c *traceCost) upgradeVerbosity() (*spanGroup, bool) {
+	g := new(spanGroup)
+	if atomic.CompareAndSwapPointer(&c.verbose, nil, unsafe.Pointer(g)) {
+		return g, true
+	}
+	return c.verboseGroup(), false
+}
+
+// verboseGroupFor returns the recording group that a span created from a
+// context carrying VerboseOnErrorBaggage joins, if the trace was upgraded.
+func verboseGroupFor(parentCtx *spanContext) *spanGroup {
+	if parentCtx.cost != nil {
+		return parentCtx.cost.verboseGroup()
+	}
+	if parentCtx.Baggage[VerboseOnErrorBaggage] == verboseOnErrorUpgraded {
+		// The trace was upgraded on the node that called us.
+		return new(spanGroup)
+	}
+	return nil
+}
+
+// maybeUpgradeVerbosity upgrades the trace to verbose recording if the span
+// is being marked as failed and the trace has the VerboseOnError policy.
+func (s *span) maybeUpgradeVerbosity(key string, value interface{}) {
+	if !s.verboseOnError || key != string(otext.Error) {
+		return
+	}
+	if failed, ok := value.(bool); !ok || !failed {
+		return
+	}
+	g, upgraded := s.cost.upgradeVerbosity()
+	if !s.isRecording() {
+		s.enableRecording(g, SnowballRecording)
+	}
+	if upgraded {
+		s.LogKV("event", "trace upgraded to verbose recording after an error")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+)
+
+func TestVerboseOnError(t *testing.T) {
+	tr := NewTracer()
+	tr2 := NewTracer().(*Tracer)
+	tr2.SetBaggageAuthorizer(func(interface{}, string, string) bool { return true })
+
+	// Without the policy, errors don't start recording.
+	sp := tr.StartSpan("plain", Recordable)
+	RecordError(sp, errors.New("boom"))
+	if sp.(*span).isRecording() {
+		t.Error("unexpected recording")
+	}
+	sp.Finish()
+
+	root := tr.StartSpan("root", VerboseOnError)
+	if IsBlackHoleSpan(root) {
+		t.Fatal("expected a real span")
+	}
+	rootCtx := root.Context()
+	before := StartChildSpan("before", root, false /* separateRecording */)
+	if before.(*span).isRecording() {
+		t.Fatal("unexpected recording before the error")
+	}
+	failing := tr.StartSpan("failing", opentracing.ChildOf(rootCtx))
+	RecordError(failing, errors.New("boom"))
+	if !failing.(*span).isRecording() {
+		t.Fatal("expected the failing span to be recording")
+	}
+	after1 := StartChildSpan("after1", root, false /* separateRecording */)
+	after2 := tr.StartSpan("after2", opentracing.ChildOf(rootCtx))
+
+	// Other nodes learn about the upgrade even from contexts captured before it.
+	carrier := opentracing.TextMapCarrier{}
+	if err := tr.Inject(rootCtx, opentracing.TextMap, carrier); err != nil {
+		t.Fatal(err)
+	}
+	if v := carrier[prefixBaggage+VerboseOnErrorBaggage]; v != verboseOnErrorUpgraded {
+		t.Errorf("expected the upgraded policy in the carrier, got %q", v)
+	}
+	remoteCtx, err := tr2.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := tr2.StartSpan("remote", opentracing.ChildOf(remoteCtx))
+	if !remote.(*span).isRecording() {
+		t.Error("expected the remote span to be recording")
+	}
+	remote.Finish()
+
+	for _, sp := range []opentracing.Span{after2, after1, failing, before} {
+		sp.Finish()
+	}
+	root.Finish()
+
+	if err := TestingCheckRecordedSpans(root.(*span).finishedRecording(), `
+		span root:
+		span failing:
+		  tags: error=true
+		  event: trace upgraded to verbose recording after an error
+		  error: boom
+		span after1:
+		span after2:
+	`); err != nil {
+		t.Fatal(err)
+	}
+}
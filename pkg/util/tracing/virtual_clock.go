@@ -0,0 +1,37 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// NewVirtualClockTracer creates a Tracer for tests whose spans take their
+// timestamps from the given manual clock.
+func NewVirtualClockTracer(clock *timeutil.ManualTime) opentracing.Tracer {
+	return NewTracerWithOptions(TracerOptions{Clock: clock})
+}
+
+// now returns the current time according to the tracer's clock.
+func (t *Tracer) now() time.Time {
+	if t.clock != nil {
+		return t.clock.Now()
+	}
+	return time.Now()
+}
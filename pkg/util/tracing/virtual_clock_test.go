@@ -0,0 +1,103 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+func TestVirtualClockTracer(t *testing.T) {
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := timeutil.NewManualTime(start)
+	tr := NewVirtualClockTracer(clock)
+
+	sp := tr.StartSpan("root", Recordable)
+	StartRecording(sp, SingleNodeRecording)
+	clock.Advance(time.Second)
+	child := StartChildSpan("child", sp, false /* separateRecording */)
+	clock.Advance(2 * time.Second)
+	child.LogKV("event", "ev")
+	clock.Advance(3 * time.Second)
+	child.Finish()
+	sp.Finish()
+
+	rec := GetRecording(sp)
+	if len(rec) != 2 {
+		t.Fatalf("unexpected recording %v", rec)
+	}
+	root, c := rec[0], rec[1]
+	if !root.StartTime.Equal(start) || root.Duration != 6*time.Second {
+		t.Errorf("unexpected root timings: start %s, duration %s", root.StartTime, root.Duration)
+	}
+	if !c.StartTime.Equal(start.Add(time.Second)) || c.Duration != 5*time.Second {
+		t.Errorf("unexpected child timings: start %s, duration %s", c.StartTime, c.Duration)
+	}
+	if len(c.Logs) != 1 || !c.Logs[0].Time.Equal(start.Add(3*time.Second)) {
+		t.Errorf("unexpected logs %v", c.Logs)
+	}
+	if !root.ClockReading.Equal(start.Add(6 * time.Second)) {
+		t.Errorf("unexpected clock reading %s", root.ClockReading)
+	}
+}
+
+func TestVirtualClockAlignRecording(t *testing.T) {
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	localClock := timeutil.NewManualTime(start)
+	local := NewVirtualClockTracer(localClock)
+	// The remote node's clock is 10s behind.
+	remoteClock := timeutil.NewManualTime(start.Add(-10 * time.Second))
+	remote := NewVirtualClockTracer(remoteClock)
+
+	root := local.StartSpan("root", Recordable)
+	StartRecording(root, SingleNodeRecording)
+	carrier := make(opentracing.TextMapCarrier)
+	if err := local.Inject(root.Context(), opentracing.TextMap, carrier); err != nil {
+		t.Fatal(err)
+	}
+	wireContext, err := remote.Extract(opentracing.TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := remote.StartSpan("parent", opentracing.ChildOf(wireContext), Recordable)
+	StartRecording(parent, SingleNodeRecording)
+	remoteClock.Advance(time.Second)
+	child := StartChildSpan("child", parent, false /* separateRecording */)
+	remoteClock.Advance(time.Second)
+	child.Finish()
+	parent.Finish()
+	remoteClock.Advance(time.Second)
+	localClock.Advance(3 * time.Second)
+	root.Finish()
+
+	rec := append(GetRecording(root), GetRecording(parent)...)
+	AlignRecording(rec)
+	if len(rec) != 3 {
+		t.Fatalf("unexpected recording %v", rec)
+	}
+	p, c := rec[1], rec[2]
+	// The parent appears to start before the root and is moved forward; the
+	// child was collected together with it, so it moves along.
+	if !p.StartTime.Equal(start) {
+		t.Errorf("expected the parent to start at %s, got %s", start, p.StartTime)
+	}
+	if exp := start.Add(time.Second); !c.StartTime.Equal(exp) {
+		t.Errorf("expected the child to start at %s, got %s", exp, c.StartTime)
+	}
+}
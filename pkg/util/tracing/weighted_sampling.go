@@ -0,0 +1,99 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// weightedSamplingMinPerMinute enables weighted sampling: the sampling rate of
+// each operation is boosted above trace.sample_rate so that rare operations
+// (e.g. admin or schema change operations) are almost always traced, while
+// high-volume operations are sampled at the base rate.
+var weightedSamplingMinPerMinute = settings.RegisterIntSetting(
+	"trace.sample_rate.weighted.min_per_minute",
+	"if nonzero, the sampling rate of each operation is boosted above trace.sample_rate so that about this many of its traces are kept per minute",
+	0,
+)
+
+// opThroughputWindow is the window over which the throughput of the operations
+// is measured for weighted sampling.
+const opThroughputWindow = time.Minute
+
+// maxTrackedOps bounds the number of operations tracked in a window; the
+// operations beyond that are sampled at the base rate.
+const maxTrackedOps = 10000
+
+// opThroughput counts the new traces of each (root) operation, for weighted
+// sampling.
+type opThroughput struct {
+	syncutil.Mutex
+	windowStart time.Time
+	// Counts for the current and previous windows.
+	cur, prev map[string]int64
+}
+
+// record counts a new trace for the operation and returns the throughput of
+// the operation: the number of its traces in the previous window, or in the
+// current window if that's higher (including the new trace). Returns 0 if the
+// operation is not tracked.
+func (o *opThroughput) record(op string, now time.Time) int64 {
+	o.Lock()
+	defer o.Unlock()
+	if elapsed := now.Sub(o.windowStart); elapsed >= opThroughputWindow {
+		if elapsed < 2*opThroughputWindow {
+			o.prev = o.cur
+		} else {
+			o.prev = nil
+		}
+		o.cur = make(map[string]int64, len(o.prev))
+		o.windowStart = now
+	}
+	n, ok := o.cur[op]
+	if !ok && len(o.cur) >= maxTrackedOps {
+		return 0
+	}
+	n++
+	o.cur[op] = n
+	if p := o.prev[op]; p > n {
+		return p
+	}
+	return n
+}
+
+// weightedSampleRate returns the sampling rate for a new trace of the given
+// operation, given the base rate; boosted is set if weighted sampling raised
+// the rate.
+func (t *Tracer) weightedSampleRate(op string, rate float64) (_ float64, boosted bool) {
+	min := weightedSamplingMinPerMinute.Get()
+	if min <= 0 || rate >= 1 {
+		return rate, false
+	}
+	n := t.opThroughput.record(op, time.Now())
+	if n == 0 {
+		return rate, false
+	}
+	weighted := float64(min) / float64(n)
+	if weighted <= rate {
+		return rate, false
+	}
+	if weighted > 1 {
+		weighted = 1
+	}
+	return weighted, true
+}
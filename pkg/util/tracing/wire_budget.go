@@ -0,0 +1,108 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// SpanMetaForWire is like EncodeProposalSpan, but adds as much of the context's
+// shadow context and baggage as fits in maxBytes. It returns nil if the IDs
+// don't fit.
+func SpanMetaForWire(osc opentracing.SpanContext, maxBytes int) ([]byte, error) {
+	sc, ok := osc.(*spanContext)
+	if !ok || maxBytes < replicatedSpanMetaLen {
+		return nil, nil
+	}
+	var flags byte
+	if sc.recordingGroup != nil {
+		flags |= replicatedFlagRecording
+	}
+	fields, err := wireFields(sc)
+	if err != nil {
+		return nil, err
+	}
+	if buf := encodeReplicatedSpanMeta(sc.spanMeta, flags, fields); len(buf) <= maxBytes {
+		return buf, nil
+	}
+
+	for k := range fields {
+		if k == fieldNameShadowType || strings.HasPrefix(k, prefixShadow) {
+			delete(fields, k)
+		}
+	}
+	if buf := encodeReplicatedSpanMeta(sc.spanMeta, flags, fields); len(buf) <= maxBytes {
+		return buf, nil
+	}
+
+	// Order the baggage keys by the order in which they are dropped.
+	var baggage []string
+	for k := range fields {
+		if strings.HasPrefix(k, prefixBaggage) {
+			baggage = append(baggage, k)
+		}
+	}
+	size := func(k string) int { return len(k) + len(fields[k]) }
+	sort.Slice(baggage, func(i, j int) bool {
+		pi := privilegedBaggage[strings.TrimPrefix(baggage[i], prefixBaggage)]
+		pj := privilegedBaggage[strings.TrimPrefix(baggage[j], prefixBaggage)]
+		if pi != pj {
+			return pj
+		}
+		if si, sj := size(baggage[i]), size(baggage[j]); si != sj {
+			return si > sj
+		}
+		return baggage[i] < baggage[j]
+	})
+	for _, k := range baggage {
+		delete(fields, k)
+		if k == prefixBaggage+Snowball {
+			delete(fields, fieldNameRecordingSchema)
+		}
+		if buf := encodeReplicatedSpanMeta(sc.spanMeta, flags, fields); len(buf) <= maxBytes {
+			return buf, nil
+		}
+	}
+	// Only the IDs are left.
+	return encodeReplicatedSpanMeta(sc.spanMeta, flags, nil), nil
+}
+
+// wireFields returns the fields of the context that SpanMetaForWire encodes
+// after the IDs; it is the side-effect free subset of injectSpanContext.
+func wireFields(sc *spanContext) (opentracing.TextMapCarrier, error) {
+	fields := make(opentracing.TextMapCarrier)
+	for k, v := range sc.Baggage {
+		if k == LogBudget {
+			continue
+		}
+		fields[prefixBaggage+k] = v
+	}
+	if sc.Baggage[Snowball] != "" {
+		fields[fieldNameRecordingSchema] = strconv.Itoa(int(CurrentRecordingSchema))
+	}
+	if sc.shadowTr != nil {
+		fields[fieldNameShadowType] = sc.shadowTr.Typ()
+		if err := sc.shadowTr.Inject(sc.shadowCtx, opentracing.TextMap, textMapWriterFn(func(key, val string) {
+			fields[prefixShadow+key] = val
+		})); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestSpanMetaForWire(t *testing.T) {
+	tr := NewTracer()
+	sp := tr.StartSpan("a", Recordable)
+	defer sp.Finish()
+	SetLogBudget(sp, 10)
+	StartRecording(sp, SnowballRecording)
+	sp.SetBaggageItem("small", "x")
+	sp.SetBaggageItem("large", strings.Repeat("x", 100))
+
+	full, err := SpanMetaForWire(sp.Context(), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(full) <= replicatedSpanMetaLen {
+		t.Fatalf("expected baggage in the encoding, got %d bytes", len(full))
+	}
+
+	// Returns the baggage items that survived with the given budget.
+	baggageFor := func(maxBytes int) []string {
+		buf, err := SpanMetaForWire(sp.Context(), maxBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(buf) > maxBytes {
+			t.Fatalf("%d: encoding exceeds budget: %d bytes", maxBytes, len(buf))
+		}
+		if buf == nil {
+			return nil
+		}
+		_, imported, err := StartApplicationSpan(context.Background(), tr, buf, "b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer imported.Finish()
+		if imported.(*span).TraceID != sp.(*span).TraceID {
+			t.Fatalf("%d: trace ID not propagated", maxBytes)
+		}
+		var res []string
+		for _, k := range []string{Snowball, "small", "large", LogBudget} {
+			if imported.BaggageItem(k) != "" {
+				res = append(res, k)
+			}
+		}
+		return res
+	}
+
+	if b := baggageFor(len(full)); len(b) != 3 {
+		t.Errorf("expected all baggage but the log budget, got %v", b)
+	}
+	if b := baggageFor(len(full) - 1); len(b) != 2 || b[0] != Snowball || b[1] != "small" {
+		t.Errorf("expected large item to be dropped, got %v", b)
+	}
+	if b := baggageFor(len(full) - 130); len(b) != 1 || b[0] != Snowball {
+		t.Errorf("expected only privileged baggage, got %v", b)
+	}
+	if b := baggageFor(replicatedSpanMetaLen); len(b) != 0 {
+		t.Errorf("expected no baggage, got %v", b)
+	}
+	if b := baggageFor(10); b != nil {
+		t.Errorf("expected no encoding, got %v", b)
+	}
+	if buf, err := SpanMetaForWire(tr.StartSpan("noop").Context(), 1000); err != nil || buf != nil {
+		t.Errorf("expected no encoding for noop context, got %x, %v", buf, err)
+	}
+
+	// The span's log budget was not handed over.
+	for i := 0; i < 20; i++ {
+		sp.LogKV("event", "x")
+	}
+	if n := len(GetRecording(sp)[0].Logs); n != 10 {
+		t.Errorf("expected the whole budget of 10 messages, got %d", n)
+	}
+}